@@ -0,0 +1,119 @@
+// Package client connects Go programs to the taskmatching chaincode through the Fabric
+// gateway of fabric-sdk-go, as described by a profile file.
+//
+// A profile is a JSON file naming the connection profile of the network, the wallet
+// directory and the identity in it to connect as, and the channel and chaincode. If the
+// identity is not in the wallet yet it is imported from the certificate and key the
+// profile points to. Relative paths are taken from the working directory.
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/config"
+	"github.com/hyperledger/fabric-sdk-go/pkg/gateway"
+)
+
+// Profile says how to connect to the network and as whom.
+type Profile struct {
+	Connection string `json:"connection"` //connection profile of the network (YAML or JSON)
+	Wallet     string `json:"wallet"`     //wallet directory
+	Identity   string `json:"identity"`   //label of the identity in the wallet
+	MSPID      string `json:"mspId"`      //MSP of the identity, to import it into the wallet
+	Cert       string `json:"cert"`       //PEM certificate of the identity, to import it
+	Key        string `json:"key"`        //its private key, or the keystore directory holding it
+	Channel    string `json:"channel"`
+	Chaincode  string `json:"chaincode"`
+}
+
+// LoadProfile reads the profile at path. The channel and chaincode default to those of
+// the network in this repository.
+func LoadProfile(path string) (*Profile, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	p := &Profile{Channel: "taskmatch-channel", Chaincode: "taskmatching"}
+	if err := json.Unmarshal(data, p); err != nil {
+		return nil, fmt.Errorf("%s: %s", path, err)
+	}
+	if p.Connection == "" || p.Wallet == "" || p.Identity == "" {
+		return nil, fmt.Errorf("%s: the profile needs a connection, a wallet and an identity", path)
+	}
+	return p, nil
+}
+
+// populateWallet imports the identity of the profile into its wallet unless it is
+// there already.
+func populateWallet(p *Profile, wallet *gateway.Wallet) error {
+	if wallet.Exists(p.Identity) {
+		return nil
+	}
+	if p.MSPID == "" || p.Cert == "" || p.Key == "" {
+		return fmt.Errorf("%s is not in wallet %s and the profile has no mspId, cert and key to import it from", p.Identity, p.Wallet)
+	}
+	cert, err := ioutil.ReadFile(p.Cert)
+	if err != nil {
+		return err
+	}
+	keyPath := p.Key
+	if info, err := os.Stat(keyPath); err == nil && info.IsDir() {
+		//the keystore of cryptogen holds a single key with a generated name
+		files, err := ioutil.ReadDir(keyPath)
+		if err != nil {
+			return err
+		}
+		if len(files) != 1 {
+			return fmt.Errorf("keystore %s holds %d files, expected one key", keyPath, len(files))
+		}
+		keyPath = filepath.Join(keyPath, files[0].Name())
+	}
+	key, err := ioutil.ReadFile(keyPath)
+	if err != nil {
+		return err
+	}
+	return wallet.Put(p.Identity, gateway.NewX509Identity(p.MSPID, string(cert), string(key)))
+}
+
+// Client is a connection to the chaincode of a profile.
+type Client struct {
+	Gateway  *gateway.Gateway
+	Network  *gateway.Network
+	Contract *gateway.Contract
+}
+
+// Connect connects to the network of the profile as its identity.
+func Connect(p *Profile) (*Client, error) {
+	wallet, err := gateway.NewFileSystemWallet(p.Wallet)
+	if err != nil {
+		return nil, err
+	}
+	if err := populateWallet(p, wallet); err != nil {
+		return nil, err
+	}
+	gw, err := gateway.Connect(
+		gateway.WithConfig(config.FromFile(filepath.Clean(p.Connection))),
+		gateway.WithIdentity(wallet, p.Identity),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect: %s", err)
+	}
+	network, err := gw.GetNetwork(p.Channel)
+	if err != nil {
+		gw.Close()
+		return nil, fmt.Errorf("failed to get channel %s: %s", p.Channel, err)
+	}
+	return &Client{gw, network, network.GetContract(p.Chaincode)}, nil
+}
+
+// Close closes the connection, it can be called more than once.
+func (c *Client) Close() {
+	if c.Gateway != nil {
+		c.Gateway.Close()
+		c.Gateway = nil
+	}
+}
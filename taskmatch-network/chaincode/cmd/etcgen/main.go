@@ -0,0 +1,118 @@
+// Command etcgen generates ETC matrices like those of the Braun et al. benchmark and
+// writes them in its plain text layout, one runtime per line, task by task.
+//
+// A single instance of a class, with the preset parameters of its heterogeneities:
+//
+//	etcgen -class c_hihi -seed 1 -o u_c_hihi.0
+//
+// Any parameter can be set exactly, overriding the preset of the class:
+//
+//	etcgen -class i_lolo -method cvb -mean 500 -task-cv 0.3 -resources 8
+//
+// The whole suite, every class with -instances instances named like u_c_hihi.0, into a
+// directory:
+//
+//	etcgen -suite benchmark -instances 3
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+
+	"github.com/chaincode/scheduling"
+)
+
+func main() {
+	className := flag.String("class", "i_hihi", "instance class giving the preset parameters, e.g. c_hihi")
+	method := flag.String("method", scheduling.RangeBased, "generation method, range or cvb")
+	consistency := flag.String("consistency", "", "consistent, semi-consistent or inconsistent, overrides the class")
+	tasks := flag.Int("tasks", 512, "number of tasks")
+	resources := flag.Int("resources", 16, "number of resources")
+	taskRange := flag.Float64("task-range", 0, "range based: task runtimes are drawn from [1, task-range)")
+	resourceRange := flag.Float64("resource-range", 0, "range based: resource factors are drawn from [1, resource-range)")
+	mean := flag.Float64("mean", 0, "cvb: mean task runtime")
+	taskCV := flag.Float64("task-cv", 0, "cvb: coefficient of variation of the task runtimes")
+	resourceCV := flag.Float64("resource-cv", 0, "cvb: coefficient of variation over the resources")
+	seed := flag.Int64("seed", 1, "seed of the first instance, the following ones count up from it")
+	out := flag.String("o", "", "file to write a single instance to, stdout if empty")
+	suite := flag.String("suite", "", "directory to write instances of every class to")
+	instances := flag.Int("instances", 1, "number of instances per class with -suite")
+	flag.Parse()
+
+	c, err := scheduling.ParseClass(*className)
+	if err != nil {
+		fail(err)
+	}
+	params := c.Params(*method, *tasks, *resources)
+
+	//flags given explicitly win over the preset of the class
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "consistency":
+			params.Consistency = *consistency
+		case "task-range":
+			params.TaskRange = *taskRange
+		case "resource-range":
+			params.ResourceRange = *resourceRange
+		case "mean":
+			params.Mean = *mean
+		case "task-cv":
+			params.TaskCV = *taskCV
+		case "resource-cv":
+			params.ResourceCV = *resourceCV
+		}
+	})
+
+	if *suite != "" {
+		err = writeSuite(*suite, *method, *tasks, *resources, *instances, *seed)
+	} else {
+		err = writeInstance(*out, params, *seed)
+	}
+	if err != nil {
+		fail(err)
+	}
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "etcgen:", err)
+	os.Exit(1)
+}
+
+// writeInstance writes one instance to path, or stdout if path is empty.
+func writeInstance(path string, params scheduling.ETCParams, seed int64) error {
+	etc, err := params.Generate(rand.New(rand.NewSource(seed)))
+	if err != nil {
+		return err
+	}
+	if path == "" {
+		return scheduling.WriteBraun(os.Stdout, etc)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := scheduling.WriteBraun(f, etc); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// writeSuite writes instances of every class with their preset parameters into dir.
+func writeSuite(dir, method string, tasks, resources, instances int, seed int64) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	for _, c := range scheduling.Classes() {
+		for i := 0; i < instances; i++ {
+			path := filepath.Join(dir, scheduling.BraunName(c, method, i))
+			if err := writeInstance(path, c.Params(method, tasks, resources), seed+int64(i)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,183 @@
+// Command tmbench compares the registered schedulers on generated ETC matrices.
+//
+// For every instance class and seed it generates an ETC matrix, runs
+// every scheduler on it with the same seed and reports the makespan, flowtime, gap to
+// the makespan lower bound and wall-clock time of each run as CSV or JSON. A summary
+// table of the averages per class and scheduler goes to stderr.
+//
+//	tmbench -tasks 512 -resources 16 -classes c_hihi,i_lolo -seeds 5 -format json -o runs.json
+//
+// Classes are named as in the benchmark of Braun et al.: the consistency (c, s or i for
+// consistent, semi-consistent or inconsistent) followed by the task and the resource
+// heterogeneity (hi or lo), e.g. s_hilo. Instances are range based unless -method cvb
+// is given. -budget and -evaluations give every run the same wall-clock time or number
+// of makespan evaluations, to compare the searches at equal effort. -tuned loads the
+// profiles written by tmtune, to run them next to the schedulers with their defaults.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/chaincode/scheduling"
+)
+
+// parseClasses parses a comma separated list of class names, or "all".
+func parseClasses(list string) ([]scheduling.Class, error) {
+	if list == "all" {
+		return scheduling.Classes(), nil
+	}
+	var classes []scheduling.Class
+	for _, name := range strings.Split(list, ",") {
+		c, err := scheduling.ParseClass(name)
+		if err != nil {
+			return nil, err
+		}
+		classes = append(classes, c)
+	}
+	return classes, nil
+}
+
+// parseSchedulers parses a comma separated list of registered schedulers, or "all".
+func parseSchedulers(list string) ([]string, error) {
+	if list == "all" {
+		return scheduling.Names(), nil
+	}
+	names := strings.Split(list, ",")
+	for _, name := range names {
+		if _, err := scheduling.Lookup(name); err != nil {
+			return nil, fmt.Errorf("%s, registered are %s", err, strings.Join(scheduling.Names(), ", "))
+		}
+	}
+	return names, nil
+}
+
+// run is the outcome of one scheduler on one instance.
+type run struct {
+	Class      string  `json:"class"`
+	Seed       int64   `json:"seed"`
+	Scheduler  string  `json:"scheduler"`
+	Tasks      int     `json:"tasks"`
+	Resources  int     `json:"resources"`
+	Makespan   float64 `json:"makespan"`
+	Flowtime   float64 `json:"flowtime"`
+	LowerBound float64 `json:"lowerBound"`
+	Gap        float64 `json:"gap"`     //percent the makespan is above the lower bound
+	Seconds    float64 `json:"seconds"` //wall-clock time of the scheduler
+}
+
+// benchmark runs every scheduler on an instance of every class for every seed.
+func benchmark(classes []scheduling.Class, method string, schedulers []string, tasks, resources, seeds int, budget scheduling.Budget, progress io.Writer) ([]run, error) {
+	var runs []run
+	for _, c := range classes {
+		for seed := int64(1); seed <= int64(seeds); seed++ {
+			etc, err := c.Params(method, tasks, resources).Generate(rand.New(rand.NewSource(seed)))
+			if err != nil {
+				return nil, err
+			}
+			lowerBound := scheduling.LowerBound(etc)
+
+			for _, name := range schedulers {
+				schedule, _ := scheduling.Lookup(name)
+				start := time.Now()
+				sol := schedule(context.Background(), etc, budget, rand.New(rand.NewSource(seed)))
+				elapsed := time.Since(start)
+
+				makespan := scheduling.ETCMakespan(etc, sol)
+				runs = append(runs, run{c.String(), seed, name, tasks, resources, makespan, scheduling.Flowtime(etc, sol),
+					lowerBound, 100 * (makespan - lowerBound) / lowerBound, elapsed.Seconds()})
+				fmt.Fprintf(progress, "%s seed %d %s: makespan %.2f in %s\n", c, seed, name, makespan, elapsed)
+			}
+		}
+	}
+	return runs, nil
+}
+
+func main() {
+	tasks := flag.Int("tasks", 512, "number of tasks of the generated instances")
+	resources := flag.Int("resources", 16, "number of resources of the generated instances")
+	classList := flag.String("classes", "all", "comma separated instance classes, e.g. c_hihi,i_lolo, or all")
+	method := flag.String("method", scheduling.RangeBased, "how instances are generated, range or cvb")
+	schedulerList := flag.String("schedulers", "all", "comma separated schedulers to run, or all")
+	seeds := flag.Int("seeds", 3, "number of seeds, every class gets an instance per seed")
+	budget := flag.Duration("budget", 0, "wall-clock time every run gets, no limit if 0")
+	evaluations := flag.Int("evaluations", 0, "makespans every run may compute, no limit if 0")
+	format := flag.String("format", "csv", "output format, csv or json")
+	out := flag.String("o", "", "file to write the runs to, stdout if empty")
+	summary := flag.Bool("summary", true, "print a summary table to stderr")
+	verbose := flag.Bool("v", false, "print every run to stderr as it finishes")
+	tuned := flag.String("tuned", "", "directory of tuned profiles to run as schedulers, see tmtune")
+	flag.Parse()
+
+	if *tuned != "" {
+		if _, err := scheduling.LoadProfiles(*tuned); err != nil {
+			fmt.Fprintln(os.Stderr, "tmbench:", err)
+			os.Exit(1)
+		}
+	}
+
+	limit := scheduling.Budget{Time: *budget, Evaluations: *evaluations}
+	if err := tmbench(*tasks, *resources, *classList, *method, *schedulerList, *seeds, limit, *format, *out, *summary, *verbose); err != nil {
+		fmt.Fprintln(os.Stderr, "tmbench:", err)
+		os.Exit(1)
+	}
+}
+
+func tmbench(tasks, resources int, classList, method, schedulerList string, seeds int, budget scheduling.Budget, format, out string, summary, verbose bool) error {
+	if tasks < 1 || resources < 1 || seeds < 1 {
+		return fmt.Errorf("tasks, resources and seeds must be positive")
+	}
+	if method != scheduling.RangeBased && method != scheduling.CVB {
+		return fmt.Errorf("unknown method %q, expected range or cvb", method)
+	}
+	if format != "csv" && format != "json" {
+		return fmt.Errorf("unknown format %q, expected csv or json", format)
+	}
+	classes, err := parseClasses(classList)
+	if err != nil {
+		return err
+	}
+	schedulers, err := parseSchedulers(schedulerList)
+	if err != nil {
+		return err
+	}
+
+	progress := ioutil.Discard
+	if verbose {
+		progress = os.Stderr
+	}
+	runs, err := benchmark(classes, method, schedulers, tasks, resources, seeds, budget, progress)
+	if err != nil {
+		return err
+	}
+
+	w := os.Stdout
+	if out != "" {
+		f, err := os.Create(out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	if format == "json" {
+		err = writeJSON(w, runs)
+	} else {
+		err = writeCSV(w, runs)
+	}
+	if err != nil {
+		return err
+	}
+
+	if summary {
+		return writeSummary(os.Stderr, runs)
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+)
+
+func writeJSON(w io.Writer, runs []run) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(runs)
+}
+
+func writeCSV(w io.Writer, runs []run) error {
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"class", "seed", "scheduler", "tasks", "resources", "makespan", "flowtime", "lowerBound", "gap", "seconds"})
+	for _, r := range runs {
+		writer.Write([]string{r.Class, strconv.FormatInt(r.Seed, 10), r.Scheduler, strconv.Itoa(r.Tasks), strconv.Itoa(r.Resources),
+			formatFloat(r.Makespan), formatFloat(r.Flowtime), formatFloat(r.LowerBound), formatFloat(r.Gap), formatFloat(r.Seconds)})
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// writeSummary prints a table per class with the mean makespan, flowtime, gap and time
+// of every scheduler over the seeds, and how often it had the best makespan.
+func writeSummary(w io.Writer, runs []run) error {
+	type key struct{ class, scheduler string }
+	type total struct {
+		runs, wins                       int
+		makespan, flowtime, gap, seconds float64
+	}
+	totals := map[key]*total{}
+	var classes, schedulers []string
+
+	//the best makespan of every instance, to count wins
+	best := map[string]float64{}
+	for _, r := range runs {
+		instance := r.Class + "/" + strconv.FormatInt(r.Seed, 10)
+		if b, ok := best[instance]; !ok || r.Makespan < b {
+			best[instance] = r.Makespan
+		}
+	}
+
+	for _, r := range runs {
+		k := key{r.Class, r.Scheduler}
+		t, ok := totals[k]
+		if !ok {
+			t = &total{}
+			totals[k] = t
+			classes = appendNew(classes, r.Class)
+			schedulers = appendNew(schedulers, r.Scheduler)
+		}
+		t.runs++
+		t.makespan += r.Makespan
+		t.flowtime += r.Flowtime
+		t.gap += r.Gap
+		t.seconds += r.Seconds
+		if r.Makespan == best[r.Class+"/"+strconv.FormatInt(r.Seed, 10)] {
+			t.wins++
+		}
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "class\tscheduler\tmakespan\tflowtime\tgap %\tseconds\twins\t")
+	for _, c := range classes {
+		for _, s := range schedulers {
+			t, ok := totals[key{c, s}]
+			if !ok {
+				continue
+			}
+			n := float64(t.runs)
+			fmt.Fprintf(tw, "%s\t%s\t%.2f\t%.2f\t%.2f\t%.4f\t%d/%d\t\n", c, s, t.makespan/n, t.flowtime/n, t.gap/n, t.seconds/n, t.wins, t.runs)
+		}
+	}
+	return tw.Flush()
+}
+
+func appendNew(list []string, s string) []string {
+	for _, l := range list {
+		if l == s {
+			return list
+		}
+	}
+	return append(list, s)
+}
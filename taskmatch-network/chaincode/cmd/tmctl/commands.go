@@ -0,0 +1,277 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/chaincode/gantt"
+	"github.com/chaincode/scheduling"
+	"github.com/hyperledger/fabric-sdk-go/pkg/gateway"
+)
+
+// commands are the commands of tmctl by name, "job" and "solutions" followed by their
+// subcommand.
+var commands = map[string]func(c *session, args []string) error{
+	"init":           initNetwork,
+	"job create":     createJob,
+	"job get":        getJob,
+	"solve":          solve,
+	"solutions list": listSolutions,
+	"history":        history,
+	"gantt":          chart,
+}
+
+func initNetwork(c *session, args []string) error {
+	if len(args) != 0 {
+		return fmt.Errorf("init takes no arguments")
+	}
+	if _, err := c.Contract.SubmitTransaction("Initialize"); err != nil {
+		return err
+	}
+	c.done("initialized the network")
+	return nil
+}
+
+func createJob(c *session, args []string) error {
+	flags := flag.NewFlagSet("tmctl job create", flag.ExitOnError)
+	file := flags.String("file", "", "instance file, CSV, JSON or Braun")
+	format := flags.String("format", "", "format of the file; from the extension if empty")
+	resources := flags.Int("resources", 16, "number of resources of Braun files")
+	id := flags.String("id", defaultJob, "job id")
+	timeout := flags.Int("timeout", 0, "seconds the solvers get to submit, the chaincode's default if 0")
+	commitTimeout := flags.Int("commit-timeout", 0, "seconds of the commit phase, none if 0")
+	collection := flags.String("collection", "", "private data collection for the runtimes")
+	bounty := flags.Int64("bounty", 0, "tokens put on the job")
+	flags.Parse(args)
+	if *file == "" || flags.NArg() != 0 {
+		return fmt.Errorf("job create takes an instance -file")
+	}
+
+	inst, err := scheduling.ReadInstanceFile(*file, *format, *resources)
+	if err != nil {
+		return err
+	}
+	matrix, err := json.Marshal(scheduling.RoundETC(inst.ETC))
+	if err != nil {
+		return err
+	}
+
+	// 0       1          2             3                4               5
+	//id   runtimes  [timeout]  [commit timeout]  [private collection]  [bounty]
+	ccArgs := []string{*id, string(matrix), "", "", *collection, ""}
+	if *timeout > 0 {
+		ccArgs[2] = strconv.Itoa(*timeout)
+	}
+	if *commitTimeout > 0 {
+		ccArgs[3] = strconv.Itoa(*commitTimeout)
+	}
+	if *bounty > 0 {
+		ccArgs[5] = strconv.FormatInt(*bounty, 10)
+	}
+
+	if *collection == "" {
+		_, err = c.Contract.SubmitTransaction("createTaskMatching", ccArgs...)
+	} else {
+		//private runtimes only travel in the transient map
+		ccArgs[1] = ""
+		var txn *gateway.Transaction
+		txn, err = c.Contract.CreateTransaction("createTaskMatching",
+			gateway.WithTransient(map[string][]byte{"runtimes": matrix}))
+		if err == nil {
+			_, err = txn.Submit(ccArgs...)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	c.done(fmt.Sprintf("created job %s with %d tasks on %d resources from %s", *id, inst.Tasks, inst.Resources, *file))
+	return nil
+}
+
+func getJob(c *session, args []string) error {
+	if len(args) > 1 {
+		return fmt.Errorf("job get takes a job id")
+	}
+	id := defaultJob
+	if len(args) == 1 {
+		id = args[0]
+	}
+	payload, err := c.Contract.EvaluateTransaction("readTaskMatching", id)
+	if err != nil {
+		return err
+	}
+	if c.json {
+		return printJSON(payload)
+	}
+	var j job
+	if err := json.Unmarshal(payload, &j); err != nil {
+		return err
+	}
+	printJob(id, &j)
+	return nil
+}
+
+func solve(c *session, args []string) error {
+	flags := flag.NewFlagSet("tmctl solve", flag.ExitOnError)
+	solver := flags.String("solver", "", "solver to calculate, p1, p2 or p3")
+	jobID := flags.String("job", defaultJob, "job to solve")
+	flags.Parse(args)
+	if *solver == "" || flags.NArg() != 0 {
+		return fmt.Errorf("solve takes a -solver")
+	}
+	if _, err := c.Contract.SubmitTransaction("calculateTaskMatching", *solver, *jobID); err != nil {
+		return err
+	}
+
+	//the submission is committed, show what the solver handed in
+	payload, err := c.Contract.EvaluateTransaction("readTaskMatching", "solver", *solver)
+	if err != nil {
+		return err
+	}
+	if c.json {
+		return printJSON(payload)
+	}
+	var s solverRecord
+	if err := json.Unmarshal(payload, &s); err != nil {
+		return err
+	}
+	fmt.Printf("%s (%s) submitted a makespan of %d for job %s, status %s\n", s.Name, *solver, s.Runtime, s.Job, s.Status)
+	return nil
+}
+
+func listSolutions(c *session, args []string) error {
+	flags := flag.NewFlagSet("tmctl solutions list", flag.ExitOnError)
+	jobID := flags.String("job", "", "only the solutions of this job")
+	pageSize := flags.Int("page-size", 10, "solutions per page")
+	bookmark := flags.String("bookmark", "", "bookmark of the page to list, from the previous page")
+	flags.Parse(args)
+	if flags.NArg() != 0 {
+		return fmt.Errorf("solutions list takes no arguments")
+	}
+	payload, err := c.Contract.EvaluateTransaction("listSolutions", *jobID, strconv.Itoa(*pageSize), *bookmark)
+	if err != nil {
+		return err
+	}
+	if c.json {
+		return printJSON(payload)
+	}
+	var page solutionPage
+	if err := json.Unmarshal(payload, &page); err != nil {
+		return err
+	}
+	printSolutions(&page)
+	return nil
+}
+
+func history(c *session, args []string) error {
+	if len(args) == 0 {
+		args = []string{defaultJob}
+	}
+	payload, err := c.Contract.EvaluateTransaction("getHistory", args...)
+	if err != nil {
+		return err
+	}
+	if c.json {
+		return printJSON(payload)
+	}
+	var entries []historyEntry
+	if err := json.Unmarshal(payload, &entries); err != nil {
+		return err
+	}
+	printHistory(entries)
+	return nil
+}
+
+func chart(c *session, args []string) error {
+	flags := flag.NewFlagSet("tmctl gantt", flag.ExitOnError)
+	jobID := flags.String("job", defaultJob, "job whose best solution is drawn")
+	compare := flags.String("compare", "", "comma separated solvers whose submissions for the job are drawn next to it")
+	out := flags.String("o", "", "chart file, text on stdout if empty")
+	format := flags.String("chart", "", "format of the chart, ascii, svg or html; from the extension of -o if empty")
+	width := flags.Int("width", gantt.DefaultWidth, "columns of the time axis of text charts")
+	flags.Parse(args)
+	if flags.NArg() != 0 {
+		return fmt.Errorf("gantt takes no arguments")
+	}
+
+	payload, err := c.Contract.EvaluateTransaction("readTaskMatching", *jobID)
+	if err != nil {
+		return err
+	}
+	var j job
+	if err := json.Unmarshal(payload, &j); err != nil {
+		return err
+	}
+	//private runtimes are only returned by a peer of the collection
+	runtimes, err := c.Contract.EvaluateTransaction("readJobRuntimes", *jobID)
+	if err != nil {
+		return err
+	}
+	var etc [][]float64
+	if err := json.Unmarshal(runtimes, &etc); err != nil {
+		return fmt.Errorf("the runtimes of job %s are not a matrix: %s", *jobID, err)
+	}
+
+	var charts []*gantt.Chart
+	add := func(title, algorithm string, sol []int) error {
+		if algorithm != "" {
+			title += " (" + algorithm + ")"
+		}
+		chart, err := gantt.New(title, etc, sol)
+		if err == nil {
+			charts = append(charts, chart)
+		}
+		return err
+	}
+	if j.BestSolution != 0 {
+		payload, err := c.Contract.EvaluateTransaction("readTaskMatching", "solution", *jobID, strconv.Itoa(j.BestSolution))
+		if err != nil {
+			return err
+		}
+		var best solution
+		if err := json.Unmarshal(payload, &best); err != nil {
+			return err
+		}
+		if err := add(fmt.Sprintf("solution %d of %s", j.BestSolution, best.Owner), best.Algorithm, best.Solution); err != nil {
+			return err
+		}
+	}
+	if *compare != "" {
+		for _, solver := range strings.Split(*compare, ",") {
+			payload, err := c.Contract.EvaluateTransaction("readTaskMatching", "solver", solver)
+			if err != nil {
+				return err
+			}
+			var s solverRecord
+			if err := json.Unmarshal(payload, &s); err != nil {
+				return err
+			}
+			//solvers only keep their submission for the latest job
+			if s.Job != *jobID || len(s.Solution) == 0 {
+				return fmt.Errorf("%s has no submission for job %s", solver, *jobID)
+			}
+			if err := add(solver, s.Algorithm, s.Solution); err != nil {
+				return err
+			}
+		}
+	}
+	if len(charts) == 0 {
+		return fmt.Errorf("job %s has no best solution yet, -compare solvers to draw their submissions", *jobID)
+	}
+
+	if *out == "" {
+		if *format == "" {
+			*format = gantt.FormatASCII
+		}
+		return gantt.Write(os.Stdout, *format, *width, charts...)
+	}
+	if err := gantt.WriteFile(*out, *format, *width, charts...); err != nil {
+		return err
+	}
+	c.done("wrote the chart of job " + *jobID + " to " + *out)
+	return nil
+}
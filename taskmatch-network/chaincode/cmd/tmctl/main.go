@@ -0,0 +1,92 @@
+// Command tmctl talks to the taskmatching chaincode through the Fabric gateway of
+// fabric-sdk-go, instead of peer commands typed into the cli container.
+//
+//	tmctl [-profile tmctl/org1.json] [-o human|json] command [flags] [args]
+//
+// The commands are:
+//
+//	init                                   initialize the network (Initialize)
+//	job create -file etc.csv [-id job] ... create a job from an instance file
+//	job get [job]                          read a job, "work" by default
+//	solve -solver p1 [-job job]            let a solver calculate and submit
+//	solutions list [-job job] [-page-size n] [-bookmark b]
+//	history [job] | history type attr...   every version of a record
+//	gantt [-job job] [-compare p1,p2] [-o chart.svg]
+//	                                       Gantt chart of the best solution of a job
+//
+// The profile names the connection profile of the network, the wallet and the identity
+// to connect as, see package github.com/chaincode/client. Relative paths are taken from
+// the working directory; the profiles in tmctl/ expect to be used from the
+// taskmatch-network directory.
+//
+// Output is human readable unless -o json is given, which prints the JSON the chaincode
+// returned. gantt always draws a chart: text, or an SVG image or HTML page as its -chart
+// or the extension of its -o says. The submissions the solvers made for the job can be
+// compared with the best solution as long as no newer job has been created.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/chaincode/client"
+)
+
+// defaultJob is the job of the chaincode that is worked on when no job is given.
+const defaultJob = "work"
+
+func main() {
+	profilePath := flag.String("profile", envOr("TMCTL_PROFILE", "tmctl/org1.json"), "profile with the connection, wallet and identity to use")
+	output := flag.String("o", "human", "output format, human or json")
+	flag.Usage = usage
+	flag.Parse()
+	if flag.NArg() < 1 {
+		usage()
+	}
+	if *output != "human" && *output != "json" {
+		fail(fmt.Errorf("unknown output format %q, expecting human or json", *output))
+	}
+
+	command, args := flag.Arg(0), flag.Args()[1:]
+	if (command == "job" || command == "solutions") && len(args) > 0 {
+		command, args = command+" "+args[0], args[1:]
+	}
+	run, ok := commands[command]
+	if !ok {
+		usage()
+	}
+
+	profile, err := client.LoadProfile(*profilePath)
+	if err != nil {
+		fail(err)
+	}
+	conn, err := client.Connect(profile)
+	if err != nil {
+		fail(err)
+	}
+	defer conn.Close()
+	s := &session{conn, *output == "json"}
+	if err := run(s, args); err != nil {
+		conn.Close()
+		fail(err)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: tmctl [-profile file] [-o human|json] init|job create|job get|solve|solutions list|history|gantt [flags] [args]")
+	flag.PrintDefaults()
+	os.Exit(2)
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "tmctl:", err)
+	os.Exit(1)
+}
+
+func envOr(name, value string) string {
+	if v := os.Getenv(name); v != "" {
+		return v
+	}
+	return value
+}
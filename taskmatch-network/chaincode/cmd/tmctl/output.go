@@ -0,0 +1,160 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// The records of the chaincode, as far as tmctl prints them.
+
+type job struct {
+	Runtimes       string `json:"runtimes"`
+	State          string `json:"state"`
+	Created        int64  `json:"created"`
+	Deadline       int64  `json:"deadline"`
+	CommitDeadline int64  `json:"commitDeadline"`
+	Collection     string `json:"collection"`
+	RuntimesHash   string `json:"runtimesHash"`
+	Creator        string `json:"creator"`
+	Bounty         int64  `json:"bounty"`
+	RewardRule     string `json:"rewardRule"`
+	BestSolution   int    `json:"bestSolution"`
+	BestRuntime    int    `json:"bestRuntime"`
+}
+
+type solverRecord struct {
+	Status    string `json:"status"`
+	Solution  []int  `json:"sol"`
+	Runtime   int    `json:"runtime"`
+	Name      string `json:"name"`
+	Job       string `json:"job"`
+	Algorithm string `json:"alg"`
+}
+
+type solution struct {
+	Runtime      int    `json:"runtime"`
+	Solution     []int  `json:"sol"`
+	Owner        string `json:"owner"`
+	Algorithm    string `json:"alg"`
+	ImprovedFrom int    `json:"improvedFrom"`
+}
+
+type solutionPage struct {
+	Solutions []struct {
+		Job    string   `json:"job"`
+		Number int      `json:"number"`
+		Record solution `json:"record"`
+	} `json:"solutions"`
+	Bookmark string `json:"bookmark"`
+}
+
+type historyEntry struct {
+	TxID      string          `json:"txId"`
+	Timestamp string          `json:"timestamp"`
+	IsDelete  bool            `json:"isDelete"`
+	Value     json.RawMessage `json:"value"`
+}
+
+// done reports a successful transaction that returns nothing.
+func (c *session) done(message string) {
+	if c.json {
+		json.NewEncoder(os.Stdout).Encode(map[string]string{"result": message})
+	} else {
+		fmt.Println(message)
+	}
+}
+
+func printJSON(payload []byte) error {
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, payload, "", "  "); err != nil {
+		return err
+	}
+	buf.WriteByte('\n')
+	_, err := buf.WriteTo(os.Stdout)
+	return err
+}
+
+func newTable() *tabwriter.Writer {
+	return tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+}
+
+func unixTime(seconds int64) string {
+	if seconds == 0 {
+		return "-"
+	}
+	return time.Unix(seconds, 0).Format(time.RFC3339)
+}
+
+func printJob(id string, j *job) {
+	table := newTable()
+	fmt.Fprintf(table, "job\t%s\n", id)
+	fmt.Fprintf(table, "state\t%s\n", j.State)
+	fmt.Fprintf(table, "creator\t%s\n", j.Creator)
+	fmt.Fprintf(table, "created\t%s\n", unixTime(j.Created))
+	if j.CommitDeadline != j.Created {
+		fmt.Fprintf(table, "commit deadline\t%s\n", unixTime(j.CommitDeadline))
+	}
+	fmt.Fprintf(table, "deadline\t%s\n", unixTime(j.Deadline))
+	if j.Collection != "" {
+		fmt.Fprintf(table, "runtimes\tprivate in %s, sha256 %s\n", j.Collection, j.RuntimesHash)
+	} else {
+		var matrix [][]int
+		json.Unmarshal([]byte(j.Runtimes), &matrix)
+		resources := 0
+		if len(matrix) > 0 {
+			resources = len(matrix[0])
+		}
+		fmt.Fprintf(table, "runtimes\t%d tasks on %d resources\n", len(matrix), resources)
+	}
+	if j.Bounty > 0 {
+		fmt.Fprintf(table, "bounty\t%d (%s)\n", j.Bounty, j.RewardRule)
+	}
+	if j.BestSolution > 0 {
+		fmt.Fprintf(table, "best solution\t%d, makespan %d\n", j.BestSolution, j.BestRuntime)
+	}
+	table.Flush()
+}
+
+func printSolutions(page *solutionPage) {
+	table := newTable()
+	fmt.Fprintln(table, "JOB\tNUMBER\tOWNER\tALGORITHM\tMAKESPAN\tIMPROVES")
+	for _, entry := range page.Solutions {
+		improves := "-"
+		if entry.Record.ImprovedFrom > 0 {
+			improves = fmt.Sprint(entry.Record.ImprovedFrom)
+		}
+		fmt.Fprintf(table, "%s\t%d\t%s\t%s\t%d\t%s\n", entry.Job, entry.Number, entry.Record.Owner,
+			entry.Record.Algorithm, entry.Record.Runtime, improves)
+	}
+	table.Flush()
+	if page.Bookmark != "" {
+		fmt.Printf("more with -bookmark %s\n", page.Bookmark)
+	}
+}
+
+func printHistory(entries []historyEntry) {
+	table := newTable()
+	fmt.Fprintln(table, "TIMESTAMP\tTX\tVALUE")
+	for _, entry := range entries {
+		value := string(entry.Value)
+		if entry.IsDelete {
+			value = "deleted"
+		} else if len(value) > 80 {
+			value = value[:77] + "..."
+		}
+		fmt.Fprintf(table, "%s\t%s\t%s\n", entry.Timestamp, shortTxID(entry.TxID), strings.TrimSpace(value))
+	}
+	table.Flush()
+}
+
+func shortTxID(txID string) string {
+	if len(txID) > 12 {
+		return txID[:12]
+	}
+	return txID
+}
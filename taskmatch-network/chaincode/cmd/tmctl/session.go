@@ -0,0 +1,9 @@
+package main
+
+import "github.com/chaincode/client"
+
+// session is what the commands work with, the connection and how to print.
+type session struct {
+	*client.Client
+	json bool //print the JSON of the chaincode instead of tables
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"strings"
+
+	"github.com/chaincode/client"
+	"github.com/chaincode/rest"
+	"github.com/hyperledger/fabric-sdk-go/pkg/gateway"
+)
+
+// fabricBackend runs the chaincode on the network through the gateway.
+type fabricBackend struct {
+	conn *client.Client
+}
+
+func (b *fabricBackend) Evaluate(function string, args ...string) ([]byte, error) {
+	result, err := b.conn.Contract.EvaluateTransaction(function, args...)
+	return result, chaincodeError(err)
+}
+
+func (b *fabricBackend) Submit(function string, transient map[string][]byte, args ...string) ([]byte, error) {
+	if transient == nil {
+		result, err := b.conn.Contract.SubmitTransaction(function, args...)
+		return result, chaincodeError(err)
+	}
+	tx, err := b.conn.Contract.CreateTransaction(function, gateway.WithTransient(transient))
+	if err != nil {
+		return nil, err
+	}
+	result, err := tx.Submit(args...)
+	return result, chaincodeError(err)
+}
+
+func (b *fabricBackend) Subscribe(ctx context.Context) (<-chan rest.Event, error) {
+	registration, notifier, err := b.conn.Contract.RegisterEvent(".*")
+	if err != nil {
+		return nil, err
+	}
+	events := make(chan rest.Event)
+	go func() {
+		defer close(events)
+		defer b.conn.Contract.Unregister(registration)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-notifier:
+				//the sdk closes the notifier when the event service goes away
+				if !ok {
+					return
+				}
+				select {
+				case events <- rest.Event{Name: event.EventName, Payload: event.Payload}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return events, nil
+}
+
+// chaincodeError turns the error of a transaction the chaincode refused into a
+// rest.ChaincodeError with its message, so it isn't reported as a network failure.
+// The sdk wraps it as "... chaincode error (status: 500, message: <message>)".
+func chaincodeError(err error) error {
+	if err == nil {
+		return nil
+	}
+	text := err.Error()
+	i := strings.Index(text, "message: ")
+	if !strings.Contains(text, "status: 500") || i < 0 {
+		return err
+	}
+	return &rest.ChaincodeError{Message: strings.TrimSuffix(text[i+len("message: "):], ")")}
+}
@@ -0,0 +1,68 @@
+// Command tmgateway serves the REST API of package github.com/chaincode/rest.
+//
+//	tmgateway -addr :8080 -backend fabric -profile tmctl/org1.json
+//	tmgateway -addr :8080 -backend memory
+//
+// The fabric backend connects to the network like tmctl. The memory backend runs the
+// chaincode on an in-memory ledger with the three solvers initialized, every
+// transaction sent as -msp, which is handy to try clients out without a network.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/chaincode/client"
+	"github.com/chaincode/memledger"
+	"github.com/chaincode/rest"
+	"github.com/chaincode/taskmatch"
+)
+
+func main() {
+	addr := flag.String("addr", ":8080", "address to listen on")
+	backendName := flag.String("backend", "fabric", "where the chaincode runs, fabric or memory")
+	profilePath := flag.String("profile", envOr("TMCTL_PROFILE", "tmctl/org1.json"), "profile with the connection, wallet and identity to use with the fabric backend")
+	mspID := flag.String("msp", "Org1MSP", "org the transactions are sent from with the memory backend")
+	flag.Parse()
+
+	var backend rest.Backend
+	switch *backendName {
+	case "fabric":
+		profile, err := client.LoadProfile(*profilePath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		conn, err := client.Connect(profile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer conn.Close()
+		backend = &fabricBackend{conn}
+	case "memory":
+		ledger := memledger.New(new(taskmatch.SimpleChaincode))
+		for name, members := range taskmatch.Collections() {
+			ledger.AddCollection(name, members...)
+		}
+		memory := &rest.LedgerBackend{Ledger: ledger, MSPID: *mspID}
+		if _, err := memory.Submit("Initialize", nil); err != nil {
+			log.Fatalf("failed to initialize the ledger: %s", err)
+		}
+		backend = memory
+	default:
+		fmt.Fprintf(os.Stderr, "tmgateway: unknown backend %s, expected fabric or memory\n", *backendName)
+		os.Exit(2)
+	}
+
+	log.Printf("serving the %s backend on %s", *backendName, *addr)
+	log.Fatal(http.ListenAndServe(*addr, rest.NewServer(backend)))
+}
+
+func envOr(name, value string) string {
+	if v := os.Getenv(name); v != "" {
+		return v
+	}
+	return value
+}
@@ -0,0 +1,293 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/chaincode/gantt"
+	"github.com/chaincode/scheduling"
+)
+
+// ordererCA is where the cli container keeps the TLS CA certificate of the orderer.
+const ordererCA = "/opt/gopath/src/github.com/hyperledger/fabric/peer/crypto/ordererOrganizations/example.com/orderers/orderer.example.com/msp/tlscacerts/tlsca.example.com-cert.pem"
+
+func (f instanceFlags) read(path string) (*scheduling.Instance, error) {
+	return scheduling.ReadInstanceFile(path, *f.format, *f.resources)
+}
+
+func convert(args []string) error {
+	flags, in := newFlagSet("convert")
+	to := flags.String("to", "", "format of the output file; from the extension if empty")
+	flags.Parse(args)
+	if flags.NArg() != 2 {
+		return fmt.Errorf("convert takes an input and an output file")
+	}
+	inst, err := in.read(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	return scheduling.WriteInstanceFile(flags.Arg(1), *to, inst)
+}
+
+// createArgs are the arguments of createTaskMatching for the runtimes, and the
+// transient map if they go into a private collection.
+func createArgs(id string, runtimes [][]int, timeout, commitTimeout int, collection string, bounty int64) ([]string, map[string]string, error) {
+	matrix, err := json.Marshal(runtimes)
+	if err != nil {
+		return nil, nil, err
+	}
+	args := []string{"createTaskMatching", id, string(matrix), strconv.Itoa(timeout), "", collection, ""}
+	var transient map[string]string
+	if commitTimeout > 0 {
+		args[4] = strconv.Itoa(commitTimeout)
+	}
+	if collection != "" {
+		args[2] = ""
+		transient = map[string]string{"runtimes": base64.StdEncoding.EncodeToString(matrix)}
+	}
+	if bounty > 0 {
+		args[6] = strconv.FormatInt(bounty, 10)
+	}
+	for args[len(args)-1] == "" {
+		args = args[:len(args)-1]
+	}
+	return args, transient, nil
+}
+
+func submit(args []string) error {
+	flags, in := newFlagSet("submit")
+	id := flags.String("id", "", "job id, the name of the file if empty")
+	timeout := flags.Int("timeout", 300, "seconds the solvers get to submit")
+	commitTimeout := flags.Int("commit-timeout", 0, "seconds of the commit phase, none if 0")
+	collection := flags.String("collection", "", "private data collection for the runtimes")
+	bounty := flags.Int64("bounty", 0, "tokens put on the job")
+	channel := flags.String("channel", envOr("CHANNEL_NAME", "taskmatch-channel"), "channel the chaincode runs on")
+	chaincode := flags.String("chaincode", "taskmatching", "chaincode name")
+	orderer := flags.String("orderer", "orderer.example.com:7050", "orderer address")
+	cafile := flags.String("cafile", envOr("ORDERER_CA", ordererCA), "TLS CA certificate of the orderer, no TLS if empty")
+	printOnly := flags.Bool("print", false, "print the peer command instead of running it")
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		return fmt.Errorf("submit takes one instance file")
+	}
+	inst, err := in.read(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	if *id == "" {
+		*id = inst.Name
+		if format := scheduling.FormatOf(*id); format != scheduling.FormatBraun {
+			*id = strings.TrimSuffix(*id, filepath.Ext(*id))
+		}
+	}
+
+	ccArgs, transient, err := createArgs(*id, scheduling.RoundETC(inst.ETC), *timeout, *commitTimeout, *collection, *bounty)
+	if err != nil {
+		return err
+	}
+	ctor, _ := json.Marshal(map[string][]string{"Args": ccArgs})
+	peerArgs := []string{"chaincode", "invoke", "-o", *orderer, "-C", *channel, "-n", *chaincode, "-c", string(ctor)}
+	if *cafile != "" {
+		peerArgs = append(peerArgs, "--tls", "true", "--cafile", *cafile)
+	}
+	if transient != nil {
+		transientJSON, _ := json.Marshal(transient)
+		peerArgs = append(peerArgs, "--transient", string(transientJSON))
+	}
+
+	if *printOnly {
+		quoted := make([]string, len(peerArgs))
+		for i, arg := range peerArgs {
+			quoted[i] = shellQuote(arg)
+		}
+		fmt.Println("peer " + strings.Join(quoted, " "))
+		return nil
+	}
+	cmd := exec.Command("peer", peerArgs...)
+	cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+	return cmd.Run()
+}
+
+func solve(args []string) error {
+	flags, in := newFlagSet("solve")
+	name := flags.String("scheduler", "min-min", "registered scheduler to run")
+	seed := flags.Int64("seed", 1, "seed of the scheduler")
+	limits := newBudgetFlags(flags)
+	out := flags.String("o", "", "result file, stdout if empty")
+	traceFile := flags.String("trace", "", "file to write the progress of the search to, JSON if it ends in .json, CSV otherwise")
+	tuned := newTunedFlag(flags)
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		return fmt.Errorf("solve takes one instance file")
+	}
+	if err := loadTuned(*tuned); err != nil {
+		return err
+	}
+	inst, err := in.read(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	scheduler, err := scheduling.Lookup(*name)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	trace := new(scheduling.Trace)
+	if *traceFile != "" {
+		ctx = scheduling.WithObserver(ctx, trace)
+	}
+	res := scheduling.NewResult(inst, *name, scheduler(ctx, inst.ETC, limits.budget(), rand.New(rand.NewSource(*seed))))
+	if *traceFile != "" {
+		if err := writeTrace(*traceFile, trace); err != nil {
+			return err
+		}
+	}
+	if *out == "" {
+		return scheduling.WriteResult(os.Stdout, res)
+	}
+	f, err := os.Create(*out)
+	if err != nil {
+		return err
+	}
+	if err := scheduling.WriteResult(f, res); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func check(args []string) error {
+	flags, in := newFlagSet("check")
+	flags.Parse(args)
+	if flags.NArg() != 2 {
+		return fmt.Errorf("check takes an instance and a result file")
+	}
+	inst, err := in.read(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(flags.Arg(1))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	res, err := scheduling.ReadResult(f)
+	if err != nil {
+		return fmt.Errorf("%s: %s", flags.Arg(1), err)
+	}
+	if err := res.Check(inst.ETC); err != nil {
+		return fmt.Errorf("%s: %s", flags.Arg(1), err)
+	}
+	fmt.Printf("%s: valid, makespan %s\n", flags.Arg(1), strconv.FormatFloat(res.Makespan, 'f', -1, 64))
+	return nil
+}
+
+func writeTrace(path string, trace *scheduling.Trace) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if strings.ToLower(filepath.Ext(path)) == ".json" {
+		err = trace.WriteJSON(f)
+	} else {
+		err = trace.WriteCSV(f)
+	}
+	if err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func chart(args []string) error {
+	flags, in := newFlagSet("gantt")
+	schedulers := flags.String("schedulers", "", "comma separated registered schedulers to chart as well")
+	seed := flags.Int64("seed", 1, "seed of the schedulers")
+	limits := newBudgetFlags(flags)
+	out := flags.String("o", "", "chart file, text on stdout if empty")
+	format := flags.String("chart", "", "format of the chart, ascii, svg or html; from the extension of -o if empty")
+	width := flags.Int("width", gantt.DefaultWidth, "columns of the time axis of text charts")
+	tuned := newTunedFlag(flags)
+	flags.Parse(args)
+	if flags.NArg() < 1 || flags.NArg() == 1 && *schedulers == "" {
+		return fmt.Errorf("gantt takes an instance file and result files or -schedulers")
+	}
+	if err := loadTuned(*tuned); err != nil {
+		return err
+	}
+	inst, err := in.read(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	var charts []*gantt.Chart
+	for _, path := range flags.Args()[1:] {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		res, err := scheduling.ReadResult(f)
+		f.Close()
+		if err == nil {
+			err = res.Check(inst.ETC)
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %s", path, err)
+		}
+		title := res.Scheduler
+		if title == "" {
+			title = filepath.Base(path)
+		}
+		c, err := gantt.New(title, inst.ETC, res.Assignment)
+		if err != nil {
+			return err
+		}
+		charts = append(charts, c)
+	}
+	if *schedulers != "" {
+		for _, name := range strings.Split(*schedulers, ",") {
+			scheduler, err := scheduling.Lookup(name)
+			if err != nil {
+				return err
+			}
+			c, err := gantt.New(name, inst.ETC, scheduler(context.Background(), inst.ETC, limits.budget(), rand.New(rand.NewSource(*seed))))
+			if err != nil {
+				return err
+			}
+			charts = append(charts, c)
+		}
+	}
+
+	if *out == "" {
+		if *format == "" {
+			*format = gantt.FormatASCII
+		}
+		return gantt.Write(os.Stdout, *format, *width, charts...)
+	}
+	return gantt.WriteFile(*out, *format, *width, charts...)
+}
+
+func envOr(name, value string) string {
+	if v := os.Getenv(name); v != "" {
+		return v
+	}
+	return value
+}
+
+// shellQuote quotes s for a POSIX shell.
+func shellQuote(s string) string {
+	if s != "" && strings.IndexFunc(s, func(r rune) bool {
+		return !(r == '-' || r == '.' || r == '/' || r == ':' || r == '_' || r >= '0' && r <= '9' || r >= 'A' && r <= 'Z' || r >= 'a' && r <= 'z')
+	}) < 0 {
+		return s
+	}
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
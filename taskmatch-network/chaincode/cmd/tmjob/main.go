@@ -0,0 +1,114 @@
+// Command tmjob moves ETC instances between files and the chaincode.
+//
+//	tmjob convert [-format f] [-resources n] [-to f] in out
+//	tmjob submit [-format f] [-resources n] [-id job] [-timeout s] ... file
+//	tmjob solve [-format f] [-resources n] [-scheduler name] [-tuned dir] [-seed n] [-budget d] [-evaluations n] [-trace t.csv] [-o result.json] file
+//	tmjob check [-format f] [-resources n] file result.json
+//	tmjob gantt [-format f] [-resources n] [-schedulers a,b] [-tuned dir] [-chart f] [-o chart.svg] file [result.json ...]
+//
+// Instance files are CSV (a row of runtimes per task), JSON with metadata or the plain
+// text layout of the Braun benchmark suite, one runtime per line. Unless -format is given
+// the format follows from the extension: .csv, .json and anything else for Braun files,
+// which need -resources (16 by default).
+//
+// submit creates a job with the runtimes of the file, rounded to whole numbers, with
+// `peer chaincode invoke`. It is meant to run in the cli container, where the peer binary
+// and the CORE_PEER_* environment of the org are set up; -print only prints the command.
+//
+// solve and check round trip results offline: solve writes the assignment of a
+// scheduler with its makespan to a result file, check recomputes the makespan of a
+// result file against the instance and fails if it does not match. The search of solve
+// is cut short after -budget or -evaluations if they are set. With -trace the best and
+// current makespan, the temperature or inertia and the diversity after every iteration
+// go to a CSV or JSON file, to plot how the search converges. solve and gantt also run
+// the tuned profiles of tmtune in the -tuned directory, by their names.
+//
+// gantt draws the assignments of result files, and of the -schedulers run on the
+// instance, as Gantt charts on one time scale with the utilization of every resource:
+// text on the terminal, or an SVG image or HTML page as -chart or the extension of -o
+// says.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/chaincode/scheduling"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+	var err error
+	switch os.Args[1] {
+	case "convert":
+		err = convert(os.Args[2:])
+	case "submit":
+		err = submit(os.Args[2:])
+	case "solve":
+		err = solve(os.Args[2:])
+	case "check":
+		err = check(os.Args[2:])
+	case "gantt":
+		err = chart(os.Args[2:])
+	default:
+		usage()
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "tmjob:", err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: tmjob convert|submit|solve|check|gantt [flags] file ...")
+	os.Exit(2)
+}
+
+// instanceFlags are the flags every command reads its instance file with.
+type instanceFlags struct {
+	format    *string
+	resources *int
+}
+
+func newFlagSet(name string) (*flag.FlagSet, instanceFlags) {
+	flags := flag.NewFlagSet("tmjob "+name, flag.ExitOnError)
+	return flags, instanceFlags{
+		flags.String("format", "", "format of the instance file, csv, json or braun; from the extension if empty"),
+		flags.Int("resources", 16, "number of resources of Braun files"),
+	}
+}
+
+// budgetFlags limit the schedulers a command runs.
+type budgetFlags struct {
+	time        *time.Duration
+	evaluations *int
+}
+
+func newBudgetFlags(flags *flag.FlagSet) budgetFlags {
+	return budgetFlags{
+		flags.Duration("budget", 0, "wall-clock time a scheduler gets, no limit if 0"),
+		flags.Int("evaluations", 0, "makespans a scheduler may compute, no limit if 0"),
+	}
+}
+
+func (f budgetFlags) budget() scheduling.Budget {
+	return scheduling.Budget{Time: *f.time, Evaluations: *f.evaluations}
+}
+
+// newTunedFlag is the flag of the commands that run schedulers, naming a directory of
+// tuned profiles to register as schedulers; loadTuned loads them.
+func newTunedFlag(flags *flag.FlagSet) *string {
+	return flags.String("tuned", "", "directory of tuned profiles to run as schedulers, see tmtune")
+}
+
+func loadTuned(dir string) error {
+	if dir == "" {
+		return nil
+	}
+	_, err := scheduling.LoadProfiles(dir)
+	return err
+}
@@ -0,0 +1,120 @@
+// Command tmsolver is a solver daemon: it competes for every job of the taskmatching
+// chaincode on behalf of one solver, see package github.com/chaincode/solver.
+//
+//	tmsolver -profile tmctl/org1.json -solver p1 -schedulers min-min,pso -budget 30s -state p1.json
+//
+// It connects like tmctl, listens for jobCreated events and also polls the ledger every
+// -poll, runs the schedulers on new jobs for at most -budget and submits the best
+// assignment. Failed transactions are retried -retries times with exponential backoff
+// starting at -backoff. With -state the assignments committed for jobs with a commit
+// phase survive a restart, so they can still be revealed. -tuned loads the profiles of
+// tmtune from a directory, so that -schedulers can name them, e.g. sa@c_hihi.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/chaincode/client"
+	"github.com/chaincode/scheduling"
+	"github.com/chaincode/solver"
+)
+
+func main() {
+	profilePath := flag.String("profile", envOr("TMCTL_PROFILE", "tmctl/org1.json"), "profile with the connection, wallet and identity to use")
+	solverID := flag.String("solver", "", "solver to compete for, p1, p2 or p3")
+	schedulers := flag.String("schedulers", "all", "comma separated registered schedulers to run, or all")
+	budget := flag.Duration("budget", 10*time.Second, "time the schedulers get for a job")
+	poll := flag.Duration("poll", 10*time.Second, "how often the ledger is checked for jobs")
+	retries := flag.Int("retries", 5, "how often a failed transaction is tried again")
+	backoff := flag.Duration("backoff", time.Second, "wait before the first retry, doubled for every further one")
+	maxBackoff := flag.Duration("max-backoff", time.Minute, "longest wait between retries")
+	seed := flag.Int64("seed", 1, "seed of the schedulers")
+	state := flag.String("state", "", "file keeping committed assignments across restarts")
+	events := flag.Bool("events", true, "listen for jobCreated events, only poll if false")
+	tuned := flag.String("tuned", "", "directory of tuned profiles to run as schedulers, see tmtune")
+	flag.Parse()
+
+	if *tuned != "" {
+		if _, err := scheduling.LoadProfiles(*tuned); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	config := solver.Config{
+		Solver:       *solverID,
+		Budget:       *budget,
+		PollInterval: *poll,
+		Retries:      *retries,
+		Backoff:      *backoff,
+		MaxBackoff:   *maxBackoff,
+		Seed:         *seed,
+		StateFile:    *state,
+	}
+	if *schedulers != "all" {
+		config.Schedulers = strings.Split(*schedulers, ",")
+	}
+
+	profile, err := client.LoadProfile(*profilePath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	conn, err := client.Connect(profile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer conn.Close()
+	agent, err := solver.New(conn.Contract, config)
+	if err != nil {
+		conn.Close()
+		log.Fatal(err)
+	}
+
+	var jobs chan string
+	if *events {
+		registration, notifier, err := conn.Contract.RegisterEvent("jobCreated")
+		if err != nil {
+			conn.Close()
+			log.Fatalf("failed to listen for jobCreated events: %s", err)
+		}
+		defer conn.Contract.Unregister(registration)
+		jobs = make(chan string)
+		go func() {
+			for event := range notifier {
+				var payload struct {
+					Job string `json:"job"`
+				}
+				if err := json.Unmarshal(event.Payload, &payload); err == nil {
+					jobs <- payload.Job
+				}
+			}
+		}()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		cancel()
+	}()
+	log.Printf("%s is waiting for jobs", *solverID)
+	if err := agent.Run(ctx, jobs); err != context.Canceled {
+		fmt.Fprintln(os.Stderr, "tmsolver:", err)
+	}
+}
+
+func envOr(name, value string) string {
+	if v := os.Getenv(name); v != "" {
+		return v
+	}
+	return value
+}
@@ -0,0 +1,141 @@
+// Command tmtune tunes the parameters of sa, pso or pso-parallel for instance classes and
+// writes the best ones as profiles, which tmbench, tmjob and tmsolver load with -tuned
+// and run as schedulers of their own.
+//
+//	tmtune -scheduler sa -classes c_hihi,i_lolo -tuner race -evaluations 20000 -o tuned
+//	tmbench -tuned tuned -schedulers sa,sa@c_hihi -classes c_hihi -evaluations 20000
+//
+// For every class the tuner generates instances like etcgen, seeded from -seed
+// upwards, and scores a configuration by the mean makespan over the lower bound of its
+// runs on them. -tuner picks how configurations are searched:
+//
+//	random   -configs configurations drawn from the space, each run on -instances instances
+//	halving  successive halving: -configs configurations on a small share of the budget,
+//	         the best 1/-eta of them go on with -eta times as much until one is left
+//	race     iterated racing like irace: -configs configurations run instance after
+//	         instance, up to -instances, and drop out once they are significantly worse
+//	         than the best; -iterations races, each drawing around the elites of the last
+//
+// sa tunes temp, the starting temperature, and coolingRate; pso and pso-parallel tune
+// c1, c2, w, the inertia, and wdamp, its damping. A profile is named like sa@c_hihi
+// unless -name is given, and written to that name with .json in the -o directory.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/chaincode/scheduling"
+	"github.com/chaincode/tuning"
+)
+
+func main() {
+	scheduler := flag.String("scheduler", "sa", "scheduler to tune, sa, pso or pso-parallel")
+	classList := flag.String("classes", "i_hihi", "comma separated instance classes to tune for, e.g. c_hihi,i_lolo, or all")
+	method := flag.String("method", scheduling.RangeBased, "how instances are generated, range or cvb")
+	tasks := flag.Int("tasks", 512, "number of tasks of the generated instances")
+	resources := flag.Int("resources", 16, "number of resources of the generated instances")
+	tuner := flag.String("tuner", "race", "how configurations are searched, random, halving or race")
+	configs := flag.Int("configs", 20, "configurations to try, per race with -tuner race")
+	instances := flag.Int("instances", 10, "instances to run every configuration on, at most with -tuner race")
+	eta := flag.Int("eta", 3, "halving: 1/eta of the configurations go on to the next round")
+	iterations := flag.Int("iterations", 3, "race: number of races")
+	budget := flag.Duration("budget", 0, "wall-clock time every run gets")
+	evaluations := flag.Int("evaluations", 20000, "makespans every run may compute, no limit if 0")
+	seed := flag.Int64("seed", 1, "seed of the first instance and of the tuner")
+	name := flag.String("name", "", "name of the profile, scheduler@class if empty; only with a single class")
+	out := flag.String("o", "tuned", "directory to write the profiles to")
+	verbose := flag.Bool("v", false, "print every configuration scored to stderr")
+	flag.Parse()
+
+	t := tuning.Tuner{Scheduler: *scheduler, Method: *method, Tasks: *tasks, Resources: *resources,
+		Budget: scheduling.Budget{Time: *budget, Evaluations: *evaluations}, Seed: *seed}
+	if *verbose {
+		t.Progress = os.Stderr
+	}
+	if err := tmtune(t, *classList, *tuner, *configs, *instances, *eta, *iterations, *name, *out); err != nil {
+		fmt.Fprintln(os.Stderr, "tmtune:", err)
+		os.Exit(1)
+	}
+}
+
+func tmtune(t tuning.Tuner, classList, tuner string, configs, instances, eta, iterations int, name, out string) error {
+	if _, err := tuning.SpaceOf(t.Scheduler); err != nil {
+		return err
+	}
+	if tuner != "random" && tuner != "halving" && tuner != "race" {
+		return fmt.Errorf("unknown tuner %q, expected random, halving or race", tuner)
+	}
+	if t.Method != scheduling.RangeBased && t.Method != scheduling.CVB {
+		return fmt.Errorf("unknown method %q, expected range or cvb", t.Method)
+	}
+	classes := scheduling.Classes()
+	if classList != "all" {
+		classes = nil
+		for _, className := range strings.Split(classList, ",") {
+			c, err := scheduling.ParseClass(className)
+			if err != nil {
+				return err
+			}
+			classes = append(classes, c)
+		}
+	}
+	if name != "" && len(classes) > 1 {
+		return fmt.Errorf("-name needs a single class")
+	}
+	if err := os.MkdirAll(out, 0755); err != nil {
+		return err
+	}
+
+	for _, c := range classes {
+		//every class starts from the same tuner, without the instances of the last one
+		ct := t
+		ct.Class = c
+		rng := rand.New(rand.NewSource(t.Seed))
+		start := time.Now()
+		var res tuning.Result
+		var err error
+		switch tuner {
+		case "random":
+			res, err = ct.RandomSearch(configs, instances, rng)
+		case "halving":
+			res, err = ct.SuccessiveHalving(configs, instances, eta, rng)
+		case "race":
+			res, err = ct.Race(configs, instances, iterations, rng)
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %s", c, err)
+		}
+		//both on all the instances, a race may have stopped early
+		runs := res.Runs
+		ct.Progress = nil
+		score, err := ct.Score(res.Config, instances)
+		if err != nil {
+			return err
+		}
+		defaults, err := ct.Score(tuning.Defaults(t.Scheduler), instances)
+		if err != nil {
+			return err
+		}
+
+		profileName := name
+		if profileName == "" {
+			profileName = t.Scheduler + "@" + c.String()
+		}
+		p := tuning.Profile(profileName, t.Scheduler, res.Config)
+		p.Class, p.Tasks, p.Resources, p.Tuner = c.String(), t.Tasks, t.Resources, tuner
+		p.Evaluations, p.Score, p.Default = t.Budget.Evaluations, score, defaults
+		path := filepath.Join(out, profileName+".json")
+		if err := scheduling.WriteProfile(path, p); err != nil {
+			return err
+		}
+		fmt.Printf("%s: %s, makespan %.4f of the lower bound, %.4f with the defaults, %d runs in %s -> %s\n",
+			profileName, ct.Space.Format(res.Config), score, defaults, runs, time.Since(start).Round(time.Millisecond), path)
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package gantt
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// DefaultWidth is the number of columns the time axis of text charts takes by default.
+const DefaultWidth = 60
+
+// WriteASCII writes the charts as text, their time axes width columns wide. Every lane
+// shows the tasks of a resource as runs of # and =, labelled with the task number where
+// it fits, and dots for the time it is idle before the makespan. Several charts are
+// followed by a table comparing them resource by resource.
+func WriteASCII(w io.Writer, width int, charts ...*Chart) error {
+	if width < 10 {
+		return fmt.Errorf("text charts need at least 10 columns, not %d", width)
+	}
+	end := scale(charts)
+	for n, c := range charts {
+		if n > 0 {
+			fmt.Fprintln(w)
+		}
+		writeASCIIChart(w, c, width, end)
+	}
+	if len(charts) > 1 {
+		fmt.Fprintln(w)
+		writeComparison(w, charts)
+	}
+	return nil
+}
+
+func writeASCIIChart(w io.Writer, c *Chart, width int, end float64) {
+	fmt.Fprintf(w, "%s: makespan %s, utilization %.1f%%, idle %s\n", c.Title, formatTime(c.Makespan), 100*c.Utilization(), formatTime(c.Idle()))
+
+	label := len(fmt.Sprintf("r%d", len(c.Lanes)-1))
+	column := func(t float64) int {
+		return int(math.Round(t / end * float64(width)))
+	}
+	axis := []byte(strings.Repeat(" ", width+1))
+	last := formatTime(end)
+	axis[0] = '0'
+	copy(axis[width+1-len(last):], last)
+	fmt.Fprintf(w, "%*s %s\n", label, "", axis)
+
+	for _, lane := range c.Lanes {
+		row := []byte(strings.Repeat(" ", width))
+		for i := column(lane.Busy); i < column(c.Makespan) && i < width; i++ {
+			row[i] = '.'
+		}
+		for n, bar := range lane.Bars {
+			from, to := column(bar.Start), column(bar.End)
+			if to == from && bar.End > bar.Start {
+				to++ //too short to see on this scale, show it anyway
+			}
+			if to > width {
+				to = width
+			}
+			fill := byte('#')
+			if n%2 == 1 {
+				fill = '='
+			}
+			for i := from; i < to; i++ {
+				row[i] = fill
+			}
+			if task := strconv.Itoa(bar.Task); len(task) <= to-from {
+				copy(row[from:], task)
+			}
+		}
+		fmt.Fprintf(w, "%-*s|%s| busy %s, idle %s, %.1f%%\n", label, fmt.Sprintf("r%d", lane.Resource), row,
+			formatTime(lane.Busy), formatTime(lane.Idle), 100*lane.Utilization)
+	}
+}
+
+// writeComparison writes a table with the makespan, utilization and idle time of every
+// chart and the utilization of their resources next to each other.
+func writeComparison(w io.Writer, charts []*Chart) {
+	table := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, c := range charts {
+		fmt.Fprintf(table, "\t%s", c.Title)
+	}
+	fmt.Fprintln(table)
+	row := func(name string, value func(c *Chart) string) {
+		fmt.Fprint(table, name)
+		for _, c := range charts {
+			fmt.Fprintf(table, "\t%s", value(c))
+		}
+		fmt.Fprintln(table)
+	}
+	row("makespan", func(c *Chart) string { return formatTime(c.Makespan) })
+	row("utilization", func(c *Chart) string { return fmt.Sprintf("%.1f%%", 100*c.Utilization()) })
+	row("idle", func(c *Chart) string { return formatTime(c.Idle()) })
+	lanes := 0
+	for _, c := range charts {
+		if len(c.Lanes) > lanes {
+			lanes = len(c.Lanes)
+		}
+	}
+	for j := 0; j < lanes; j++ {
+		row(fmt.Sprintf("r%d", j), func(c *Chart) string {
+			if j >= len(c.Lanes) {
+				return "-"
+			}
+			return fmt.Sprintf("%.1f%%", 100*c.Lanes[j].Utilization)
+		})
+	}
+	table.Flush()
+}
@@ -0,0 +1,172 @@
+// Package gantt draws schedules as Gantt charts, one lane per resource with a bar for
+// every task assigned to it, together with how well the load is balanced: the time
+// every resource is busy and idle before the makespan, and its utilization.
+//
+// Charts are written as SVG, as an HTML page or as text for the terminal. Several charts
+// passed together share one time scale, so the assignments of different solvers for the
+// same instance can be compared side by side.
+package gantt
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// The formats a chart can be written in.
+const (
+	FormatASCII = "ascii"
+	FormatSVG   = "svg"
+	FormatHTML  = "html"
+)
+
+// Formats are the formats a chart can be written in.
+var Formats = []string{FormatASCII, FormatSVG, FormatHTML}
+
+// FormatOf is the format of a file named path: svg or html by its extension, ascii
+// otherwise.
+func FormatOf(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".svg":
+		return FormatSVG
+	case ".html", ".htm":
+		return FormatHTML
+	}
+	return FormatASCII
+}
+
+// Bar is a task running on a resource from Start to End.
+type Bar struct {
+	Task       int
+	Start, End float64
+}
+
+// Lane is a resource with the tasks it runs, in the order it runs them.
+type Lane struct {
+	Resource    int
+	Bars        []Bar
+	Busy        float64 //time spent running tasks
+	Idle        float64 //time left until the makespan
+	Utilization float64 //share of the makespan spent running tasks, from 0 to 1
+}
+
+// Chart is the schedule of an instance.
+type Chart struct {
+	Title    string
+	Lanes    []Lane
+	Makespan float64
+}
+
+// New lays out the schedule sol of the ETC matrix etc, which assigns every task to the
+// index of a resource. A resource runs its tasks in the order of their index without
+// pausing, which is how the makespan of the chaincode counts them.
+func New(title string, etc [][]float64, sol []int) (*Chart, error) {
+	if len(etc) == 0 || len(etc[0]) == 0 {
+		return nil, fmt.Errorf("the ETC matrix has no tasks or no resources")
+	}
+	if len(sol) != len(etc) {
+		return nil, fmt.Errorf("the schedule has %d tasks, the ETC matrix %d", len(sol), len(etc))
+	}
+
+	chart := &Chart{Title: title, Lanes: make([]Lane, len(etc[0]))}
+	for j := range chart.Lanes {
+		chart.Lanes[j].Resource = j
+	}
+	for i, resource := range sol {
+		if resource < 0 || resource >= len(etc[i]) {
+			return nil, fmt.Errorf("task %d is assigned to resource %d, the ETC matrix has %d", i, resource, len(etc[i]))
+		}
+		lane := &chart.Lanes[resource]
+		lane.Bars = append(lane.Bars, Bar{i, lane.Busy, lane.Busy + etc[i][resource]})
+		lane.Busy += etc[i][resource]
+		if lane.Busy > chart.Makespan {
+			chart.Makespan = lane.Busy
+		}
+	}
+	for j := range chart.Lanes {
+		lane := &chart.Lanes[j]
+		lane.Idle = chart.Makespan - lane.Busy
+		if chart.Makespan > 0 {
+			lane.Utilization = lane.Busy / chart.Makespan
+		}
+	}
+	return chart, nil
+}
+
+// Utilization is the share of the time until the makespan all resources together
+// spend running tasks, from 0 to 1. The closer to 1, the better the load is balanced.
+func (c *Chart) Utilization() float64 {
+	if c.Makespan == 0 {
+		return 0
+	}
+	return c.Busy() / (c.Makespan * float64(len(c.Lanes)))
+}
+
+// Busy is the time all resources together spend running tasks.
+func (c *Chart) Busy() float64 {
+	busy := 0.0
+	for _, lane := range c.Lanes {
+		busy += lane.Busy
+	}
+	return busy
+}
+
+// Idle is the time all resources together wait for the busiest one.
+func (c *Chart) Idle() float64 {
+	idle := 0.0
+	for _, lane := range c.Lanes {
+		idle += lane.Idle
+	}
+	return idle
+}
+
+// scale is the time axis the charts share: up to the longest makespan.
+func scale(charts []*Chart) float64 {
+	longest := 0.0
+	for _, c := range charts {
+		if c.Makespan > longest {
+			longest = c.Makespan
+		}
+	}
+	if longest == 0 {
+		return 1
+	}
+	return longest
+}
+
+// formatTime prints a time without trailing zeros, as whole numbers if it is one.
+func formatTime(t float64) string {
+	return strings.TrimSuffix(strings.TrimRight(fmt.Sprintf("%.2f", t), "0"), ".")
+}
+
+// Write writes the charts in one of the Formats, text charts width columns wide.
+func Write(w io.Writer, format string, width int, charts ...*Chart) error {
+	switch format {
+	case FormatASCII:
+		return WriteASCII(w, width, charts...)
+	case FormatSVG:
+		return WriteSVG(w, charts...)
+	case FormatHTML:
+		return WriteHTML(w, charts...)
+	}
+	return fmt.Errorf("unknown chart format %q, expecting one of %s", format, strings.Join(Formats, ", "))
+}
+
+// WriteFile writes the charts to a file, in the format of its extension if format is
+// empty.
+func WriteFile(path, format string, width int, charts ...*Chart) error {
+	if format == "" {
+		format = FormatOf(path)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := Write(f, format, width, charts...); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,140 @@
+package gantt
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+)
+
+var testETC = [][]float64{
+	{2, 4},
+	{3, 1},
+	{4, 2},
+	{1, 5},
+}
+
+func TestNew(t *testing.T) {
+	c, err := New("test", testETC, []int{0, 1, 1, 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Makespan != 3 {
+		t.Fatalf("makespan %v, expected 3", c.Makespan)
+	}
+	r0, r1 := c.Lanes[0], c.Lanes[1]
+	if len(r0.Bars) != 2 || r0.Bars[1] != (Bar{3, 2, 3}) || r0.Busy != 3 || r0.Idle != 0 || r0.Utilization != 1 {
+		t.Errorf("r0 %+v", r0)
+	}
+	if len(r1.Bars) != 2 || r1.Bars[1] != (Bar{2, 1, 3}) || r1.Busy != 3 || r1.Utilization != 1 {
+		t.Errorf("r1 %+v", r1)
+	}
+	if c.Utilization() != 1 || c.Idle() != 0 {
+		t.Errorf("utilization %v, idle %v", c.Utilization(), c.Idle())
+	}
+
+	c, _ = New("unbalanced", testETC, []int{0, 0, 0, 0})
+	if c.Makespan != 10 || c.Lanes[1].Idle != 10 || c.Lanes[1].Utilization != 0 || c.Utilization() != 0.5 {
+		t.Errorf("unbalanced %+v, utilization %v", c, c.Utilization())
+	}
+
+	for _, sol := range [][]int{{0, 1, 1}, {0, 1, 2, 0}, {0, -1, 0, 0}} {
+		if _, err := New("bad", testETC, sol); err == nil {
+			t.Errorf("%v was accepted", sol)
+		}
+	}
+}
+
+func TestWriteASCII(t *testing.T) {
+	c, _ := New("test", testETC, []int{0, 1, 1, 0})
+	var out bytes.Buffer
+	if err := WriteASCII(&out, 12, c); err != nil {
+		t.Fatal(err)
+	}
+	expected := "test: makespan 3, utilization 100.0%, idle 0\n" +
+		"   0           3\n" +
+		"r0|0#######3===| busy 3, idle 0, 100.0%\n" +
+		"r1|1###2=======| busy 3, idle 0, 100.0%\n"
+	if out.String() != expected {
+		t.Fatalf("got\n%s\nexpected\n%s", out.String(), expected)
+	}
+
+	//compared charts share the scale, and idle time shows up as dots
+	other, _ := New("other", testETC, []int{0, 0, 1, 1})
+	out.Reset()
+	WriteASCII(&out, 12, c, other)
+	lines := strings.Split(out.String(), "\n")
+	for n, line := range map[int]string{
+		1:  "   0           7",
+		2:  "r0|0##3=       | busy 3, idle 0, 100.0%",
+		7:  "r0|0##1=====...| busy 5, idle 2, 71.4%",
+		11: "makespan     3       7",
+		14: "r0           100.0%  71.4%",
+	} {
+		if lines[n] != line {
+			t.Errorf("line %d is %q, expected %q in\n%s", n, lines[n], line, out.String())
+		}
+	}
+}
+
+// checkXML fails unless the document is well formed, and counts its elements by name.
+func checkXML(t *testing.T, doc string) map[string]int {
+	t.Helper()
+	decoder := xml.NewDecoder(strings.NewReader(doc))
+	decoder.Strict = false
+	decoder.AutoClose = xml.HTMLAutoClose
+	elements := map[string]int{}
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return elements
+		} else if err != nil {
+			t.Fatalf("%s in\n%s", err, doc)
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			elements[start.Name.Local]++
+		}
+	}
+}
+
+func TestWriteSVG(t *testing.T) {
+	c, _ := New("a <b>", testETC, []int{0, 1, 1, 0})
+	other, _ := New("other", testETC, []int{0, 0, 0, 0})
+	var out bytes.Buffer
+	if err := WriteSVG(&out, c, other); err != nil {
+		t.Fatal(err)
+	}
+	elements := checkXML(t, out.String())
+	//a bar per task and an idle area for the idle resource of the second chart
+	if elements["rect"] != 2*len(testETC)+1 || elements["g"] != 2 {
+		t.Errorf("elements %v", elements)
+	}
+	if !strings.Contains(out.String(), "a &lt;b&gt;") {
+		t.Error("the title isn't escaped")
+	}
+}
+
+func TestWriteHTML(t *testing.T) {
+	c, _ := New("min-min", testETC, []int{0, 1, 1, 0})
+	other, _ := New("max-min", testETC, []int{1, 0, 0, 1})
+	var out bytes.Buffer
+	if err := Write(&out, FormatHTML, DefaultWidth, c, other); err != nil {
+		t.Fatal(err)
+	}
+	elements := checkXML(t, out.String())
+	if elements["svg"] != 2 || elements["table"] != 3 {
+		t.Errorf("elements %v", elements)
+	}
+	if err := Write(&out, "png", DefaultWidth, c); err == nil {
+		t.Error("unknown format accepted")
+	}
+}
+
+func TestFormatOf(t *testing.T) {
+	for path, format := range map[string]string{"a.svg": FormatSVG, "a.HTML": FormatHTML, "a.htm": FormatHTML, "a.txt": FormatASCII, "-": FormatASCII} {
+		if FormatOf(path) != format {
+			t.Errorf("%s has format %s, expected %s", path, FormatOf(path), format)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package gantt
+
+import (
+	"fmt"
+	"html"
+	"io"
+)
+
+const htmlStyle = `body { font-family: sans-serif; margin: 1.5em; }
+.charts { display: flex; flex-wrap: wrap; gap: 2em; }
+table { border-collapse: collapse; margin-top: 0.5em; }
+th, td { padding: 0.2em 0.8em; text-align: right; border-bottom: 1px solid #ddd; }
+th:first-child, td:first-child { text-align: left; }`
+
+// WriteHTML writes the charts as an HTML page, side by side on the same time scale with
+// a table of the load of every resource under each. Several charts are followed by a
+// table comparing them.
+func WriteHTML(w io.Writer, charts ...*Chart) error {
+	end := scale(charts)
+	title := "Schedule"
+	if len(charts) == 1 {
+		title = charts[0].Title
+	} else if len(charts) > 1 {
+		title = "Schedules"
+	}
+	fmt.Fprintf(w, "<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>%s</title>\n<style>\n%s\n</style>\n</head>\n<body>\n",
+		html.EscapeString(title), htmlStyle)
+	fmt.Fprintln(w, `<div class="charts">`)
+	for _, c := range charts {
+		fmt.Fprintln(w, "<div>")
+		if err := writeSVG(w, []*Chart{c}, end); err != nil {
+			return err
+		}
+		fmt.Fprintln(w, "<table>\n<tr><th>resource</th><th>tasks</th><th>busy</th><th>idle</th><th>utilization</th></tr>")
+		for _, lane := range c.Lanes {
+			fmt.Fprintf(w, "<tr><td>r%d</td><td>%d</td><td>%s</td><td>%s</td><td>%.1f%%</td></tr>\n",
+				lane.Resource, len(lane.Bars), formatTime(lane.Busy), formatTime(lane.Idle), 100*lane.Utilization)
+		}
+		fmt.Fprintf(w, "<tr><th>all</th><th>%d</th><th>%s</th><th>%s</th><th>%.1f%%</th></tr>\n</table>\n</div>\n",
+			tasks(c), formatTime(c.Busy()), formatTime(c.Idle()), 100*c.Utilization())
+	}
+	fmt.Fprintln(w, "</div>")
+
+	if len(charts) > 1 {
+		fmt.Fprint(w, "<h2>Comparison</h2>\n<table>\n<tr><th></th>")
+		for _, c := range charts {
+			fmt.Fprintf(w, "<th>%s</th>", html.EscapeString(c.Title))
+		}
+		fmt.Fprintln(w, "</tr>")
+		row := func(name string, value func(c *Chart) string) {
+			fmt.Fprintf(w, "<tr><td>%s</td>", name)
+			for _, c := range charts {
+				fmt.Fprintf(w, "<td>%s</td>", value(c))
+			}
+			fmt.Fprintln(w, "</tr>")
+		}
+		row("makespan", func(c *Chart) string { return formatTime(c.Makespan) })
+		row("utilization", func(c *Chart) string { return fmt.Sprintf("%.1f%%", 100*c.Utilization()) })
+		row("idle", func(c *Chart) string { return formatTime(c.Idle()) })
+		fmt.Fprintln(w, "</table>")
+	}
+	_, err := fmt.Fprintln(w, "</body>\n</html>")
+	return err
+}
+
+func tasks(c *Chart) int {
+	n := 0
+	for _, lane := range c.Lanes {
+		n += len(lane.Bars)
+	}
+	return n
+}
@@ -0,0 +1,110 @@
+package gantt
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"math"
+	"strconv"
+)
+
+// The layout of an SVG chart in pixels.
+const (
+	svgLabelWidth = 40  //resource names left of the lanes
+	svgPlotWidth  = 480 //the time axis
+	svgStatsWidth = 110 //utilization right of the lanes
+	svgPanelWidth = svgLabelWidth + svgPlotWidth + svgStatsWidth
+	svgTop        = 64 //title and axis above the lanes
+	svgLaneHeight = 22
+	svgBarHeight  = 18
+	svgBottom     = 12
+)
+
+// WriteSVG writes the charts as an SVG image, side by side on the same time scale.
+// Hovering over a bar shows the task, when it runs and for how long.
+func WriteSVG(w io.Writer, charts ...*Chart) error {
+	return writeSVG(w, charts, scale(charts))
+}
+
+func writeSVG(w io.Writer, charts []*Chart, end float64) error {
+	height := 0
+	for _, c := range charts {
+		if h := svgHeight(c); h > height {
+			height = h
+		}
+	}
+	width := svgPanelWidth * len(charts)
+	fmt.Fprintf(w, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="sans-serif" font-size="11">`+"\n",
+		width, height, width, height)
+	for n, c := range charts {
+		fmt.Fprintf(w, `<g transform="translate(%d,0)">`+"\n", n*svgPanelWidth)
+		writeSVGPanel(w, c, end)
+		fmt.Fprintln(w, "</g>")
+	}
+	_, err := fmt.Fprintln(w, "</svg>")
+	return err
+}
+
+func svgHeight(c *Chart) int {
+	return svgTop + len(c.Lanes)*svgLaneHeight + svgBottom
+}
+
+func writeSVGPanel(w io.Writer, c *Chart, end float64) {
+	x := func(t float64) float64 {
+		return svgLabelWidth + t/end*svgPlotWidth
+	}
+	bottom := svgTop + len(c.Lanes)*svgLaneHeight
+
+	fmt.Fprintf(w, `<text x="%d" y="16" font-size="14" font-weight="bold">%s</text>`+"\n", svgLabelWidth, html.EscapeString(c.Title))
+	fmt.Fprintf(w, `<text x="%d" y="32">makespan %s, utilization %.1f%%, idle %s</text>`+"\n",
+		svgLabelWidth, formatTime(c.Makespan), 100*c.Utilization(), formatTime(c.Idle()))
+
+	//time axis with gridlines
+	step := tickStep(end)
+	for t := 0.0; t <= end+step/1e6; t += step {
+		fmt.Fprintf(w, `<line x1="%.1f" y1="%d" x2="%.1f" y2="%d" stroke="#ddd"/>`+"\n", x(t), svgTop-4, x(t), bottom)
+		fmt.Fprintf(w, `<text x="%.1f" y="%d" text-anchor="middle" fill="#666">%s</text>`+"\n", x(t), svgTop-8, formatTime(t))
+	}
+
+	for n, lane := range c.Lanes {
+		y := svgTop + n*svgLaneHeight + (svgLaneHeight-svgBarHeight)/2
+		fmt.Fprintf(w, `<text x="4" y="%d">r%d</text>`+"\n", y+svgBarHeight-5, lane.Resource)
+		if lane.Idle > 0 {
+			fmt.Fprintf(w, `<rect x="%.1f" y="%d" width="%.1f" height="%d" fill="#f2f2f2"><title>r%d idle for %s</title></rect>`+"\n",
+				x(lane.Busy), y, x(c.Makespan)-x(lane.Busy), svgBarHeight, lane.Resource, formatTime(lane.Idle))
+		}
+		for _, bar := range lane.Bars {
+			width := x(bar.End) - x(bar.Start)
+			fmt.Fprintf(w, `<rect x="%.1f" y="%d" width="%.1f" height="%d" fill="%s" stroke="#fff" stroke-width="0.5"><title>task %d on r%d from %s to %s (%s)</title></rect>`+"\n",
+				x(bar.Start), y, width, svgBarHeight, taskColor(bar.Task), bar.Task, lane.Resource,
+				formatTime(bar.Start), formatTime(bar.End), formatTime(bar.End-bar.Start))
+			if label := strconv.Itoa(bar.Task); width >= float64(7*len(label)+4) {
+				fmt.Fprintf(w, `<text x="%.1f" y="%d" text-anchor="middle" pointer-events="none">%s</text>`+"\n",
+					x(bar.Start)+width/2, y+svgBarHeight-5, label)
+			}
+		}
+		fmt.Fprintf(w, `<text x="%d" y="%d" fill="#666">%.1f%%</text>`+"\n", svgLabelWidth+svgPlotWidth+8, y+svgBarHeight-5, 100*lane.Utilization)
+	}
+
+	//the makespan of this chart, which is short of the axis when compared to a longer one
+	fmt.Fprintf(w, `<line x1="%.1f" y1="%d" x2="%.1f" y2="%d" stroke="#c00" stroke-dasharray="4 3"><title>makespan %s</title></line>`+"\n",
+		x(c.Makespan), svgTop-4, x(c.Makespan), bottom, formatTime(c.Makespan))
+}
+
+// tickStep is a round step for about five ticks up to end: 1, 2 or 5 times a power of ten.
+func tickStep(end float64) float64 {
+	raw := end / 5
+	power := math.Pow(10, math.Floor(math.Log10(raw)))
+	for _, m := range []float64{1, 2, 5} {
+		if m*power >= raw {
+			return m * power
+		}
+	}
+	return 10 * power
+}
+
+// taskColor gives every task its own color, neighbouring task numbers far apart on the
+// color wheel.
+func taskColor(task int) string {
+	return fmt.Sprintf("hsl(%.0f,60%%,68%%)", math.Mod(float64(task)*137.508, 360))
+}
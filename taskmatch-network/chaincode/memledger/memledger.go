@@ -0,0 +1,277 @@
+// Package memledger runs chaincode against an in-memory ledger, so that the whole
+// taskmatching protocol can be driven from Go tests without a Fabric network.
+//
+// Every Invoke is one transaction: the chaincode sees the committed state, its writes
+// are buffered and only committed if it returns OK, just like on a peer. Transactions
+// are sent by an MSP id and stamped with the ledger's clock, which only moves when the
+// caller sets or advances it.
+package memledger
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	"github.com/hyperledger/fabric/protos/ledger/queryresult"
+	"github.com/hyperledger/fabric/protos/msp"
+	pb "github.com/hyperledger/fabric/protos/peer"
+)
+
+// Event is a chaincode event of a committed transaction.
+type Event struct {
+	TxID    string
+	Name    string
+	Payload []byte
+}
+
+// Ledger is the world state, history, private data and events of one chaincode on one
+// channel. It is safe for concurrent use, transactions are run one at a time.
+type Ledger struct {
+	mu sync.Mutex
+
+	cc      shim.Chaincode
+	channel string
+	now     time.Time
+	txNum   int
+
+	state       map[string][]byte
+	history     map[string][]*queryresult.KeyModification
+	private     map[string]map[string][]byte
+	collections map[string][]string
+	events      []Event
+	listeners   []chan Event
+}
+
+// New returns an empty ledger for cc on channel "memchannel". The clock starts at the
+// current time rounded down to the second.
+func New(cc shim.Chaincode) *Ledger {
+	return &Ledger{
+		cc:          cc,
+		channel:     "memchannel",
+		now:         time.Now().Truncate(time.Second),
+		state:       map[string][]byte{},
+		history:     map[string][]*queryresult.KeyModification{},
+		private:     map[string]map[string][]byte{},
+		collections: map[string][]string{},
+	}
+}
+
+// Now returns the time the next transaction will be stamped with.
+func (l *Ledger) Now() time.Time {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.now
+}
+
+// SetTime sets the time the next transactions are stamped with.
+func (l *Ledger) SetTime(t time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.now = t
+}
+
+// Advance moves the clock forward by d.
+func (l *Ledger) Advance(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.now = l.now.Add(d)
+}
+
+// AddCollection declares a private data collection readable by the given MSPs only.
+// Collections that were never declared can be read by everyone.
+func (l *Ledger) AddCollection(name string, members ...string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.collections[name] = members
+}
+
+// Init runs the chaincode's Init as mspID and commits its writes if it succeeds.
+func (l *Ledger) Init(mspID string, args ...string) pb.Response {
+	return l.run(mspID, nil, args, true, true)
+}
+
+// Invoke runs a transaction as mspID, args are the function name followed by its
+// arguments. The writes are committed if the chaincode returns OK.
+func (l *Ledger) Invoke(mspID string, args ...string) pb.Response {
+	return l.run(mspID, nil, args, false, true)
+}
+
+// InvokeWithTransient is Invoke with a transient map, which is how private data is
+// passed to the chaincode.
+func (l *Ledger) InvokeWithTransient(mspID string, transient map[string][]byte, args ...string) pb.Response {
+	return l.run(mspID, transient, args, false, true)
+}
+
+// Query runs the chaincode as mspID without committing anything, like a peer query.
+func (l *Ledger) Query(mspID string, args ...string) pb.Response {
+	return l.run(mspID, nil, args, false, false)
+}
+
+// State returns the committed value of a key, nil if it doesn't exist.
+func (l *Ledger) State(key string) []byte {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.state[key]
+}
+
+// Events returns the events of every committed transaction so far.
+func (l *Ledger) Events() []Event {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]Event(nil), l.events...)
+}
+
+// Subscribe returns a channel that receives the events of transactions committed from
+// now on. Events are dropped for a subscriber that doesn't keep up.
+func (l *Ledger) Subscribe() <-chan Event {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	ch := make(chan Event, 64)
+	l.listeners = append(l.listeners, ch)
+	return ch
+}
+
+// Unsubscribe stops sending events to a channel returned by Subscribe and closes it.
+func (l *Ledger) Unsubscribe(ch <-chan Event) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for i, listener := range l.listeners {
+		if listener == ch {
+			l.listeners = append(l.listeners[:i], l.listeners[i+1:]...)
+			close(listener)
+			return
+		}
+	}
+}
+
+func (l *Ledger) run(mspID string, transient map[string][]byte, args []string, init bool, commit bool) pb.Response {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	creator, err := proto.Marshal(&msp.SerializedIdentity{Mspid: mspID})
+	if err != nil {
+		return shim.Error(fmt.Sprintf("failed to serialize creator: %s", err))
+	}
+	ts, err := ptypes.TimestampProto(l.now)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("invalid ledger time: %s", err))
+	}
+
+	l.txNum++
+	stub := &Stub{
+		ledger:       l,
+		txID:         "tx" + strconv.Itoa(l.txNum),
+		args:         toBytes(args),
+		creator:      creator,
+		mspID:        mspID,
+		timestamp:    ts,
+		transient:    transient,
+		writes:       map[string][]byte{},
+		deletes:      map[string]bool{},
+		privWrites:   map[string]map[string][]byte{},
+		validation:   map[string][]byte{},
+		decorations:  map[string][]byte{},
+		eventPayload: nil,
+	}
+
+	var res pb.Response
+	if init {
+		res = l.cc.Init(stub)
+	} else {
+		res = l.cc.Invoke(stub)
+	}
+	if res.Status < shim.ERRORTHRESHOLD && commit {
+		l.commit(stub)
+	}
+	return res
+}
+
+// commit applies the write set of a successful transaction.
+func (l *Ledger) commit(stub *Stub) {
+	keys := make([]string, 0, len(stub.writes)+len(stub.deletes))
+	for key := range stub.writes {
+		keys = append(keys, key)
+	}
+	for key := range stub.deletes {
+		if _, ok := stub.writes[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value, written := stub.writes[key]
+		modification := &queryresult.KeyModification{TxId: stub.txID, Timestamp: stub.timestamp}
+		if written {
+			l.state[key] = value
+			modification.Value = value
+		} else {
+			delete(l.state, key)
+			modification.IsDelete = true
+		}
+		l.history[key] = append(l.history[key], modification)
+	}
+
+	for collection, writes := range stub.privWrites {
+		if l.private[collection] == nil {
+			l.private[collection] = map[string][]byte{}
+		}
+		for key, value := range writes {
+			if value == nil {
+				delete(l.private[collection], key)
+			} else {
+				l.private[collection][key] = value
+			}
+		}
+	}
+
+	if stub.eventName != "" {
+		event := Event{stub.txID, stub.eventName, stub.eventPayload}
+		l.events = append(l.events, event)
+		for _, listener := range l.listeners {
+			select {
+			case listener <- event:
+			default:
+			}
+		}
+	}
+}
+
+// canRead reports whether mspID may read a collection.
+func (l *Ledger) canRead(collection string, mspID string) bool {
+	members, ok := l.collections[collection]
+	if !ok {
+		return true
+	}
+	for _, member := range members {
+		if member == mspID {
+			return true
+		}
+	}
+	return false
+}
+
+// sortedKeys returns the committed keys in [startKey, endKey), endKey "" meaning no end.
+func (l *Ledger) sortedKeys(values map[string][]byte, startKey string, endKey string) []string {
+	var keys []string
+	for key := range values {
+		if key >= startKey && (endKey == "" || key < endKey) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func toBytes(args []string) [][]byte {
+	result := make([][]byte, len(args))
+	for i, arg := range args {
+		result[i] = []byte(arg)
+	}
+	return result
+}
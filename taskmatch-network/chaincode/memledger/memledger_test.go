@@ -0,0 +1,147 @@
+package memledger
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	pb "github.com/hyperledger/fabric/protos/peer"
+)
+
+// kvChaincode is a chaincode whose functions map onto single stub calls.
+type kvChaincode struct{}
+
+func (kvChaincode) Init(stub shim.ChaincodeStubInterface) pb.Response {
+	return shim.Success(nil)
+}
+
+func (kvChaincode) Invoke(stub shim.ChaincodeStubInterface) pb.Response {
+	function, args := stub.GetFunctionAndParameters()
+	switch function {
+	case "put":
+		//put key value [fail], the read after the write must not see it
+		stub.PutState(args[0], []byte(args[1]))
+		value, _ := stub.GetState(args[0])
+		if len(args) > 2 {
+			return shim.Error("failing on purpose")
+		}
+		return shim.Success(value)
+	case "get":
+		value, _ := stub.GetState(args[0])
+		return shim.Success(value)
+	case "putComposite":
+		key, err := stub.CreateCompositeKey(args[0], args[1:len(args)-1])
+		if err != nil {
+			return shim.Error(err.Error())
+		}
+		stub.PutState(key, []byte(args[len(args)-1]))
+		return shim.Success(nil)
+	case "page":
+		//page objectType pageSize bookmark
+		size, _ := strconv.Atoi(args[1])
+		it, meta, err := stub.GetStateByPartialCompositeKeyWithPagination(args[0], nil, int32(size), args[2])
+		return iteratorResponse(stub, it, meta, err)
+	case "query":
+		it, meta, err := stub.GetQueryResultWithPagination(args[0], 2, args[1])
+		return iteratorResponse(stub, it, meta, err)
+	case "time":
+		ts, _ := stub.GetTxTimestamp()
+		return shim.Success([]byte(strconv.FormatInt(ts.Seconds, 10)))
+	}
+	return shim.Error("unknown function " + function)
+}
+
+// iteratorResponse lists the values of an iterator followed by the bookmark.
+func iteratorResponse(stub shim.ChaincodeStubInterface, it shim.StateQueryIteratorInterface, meta *pb.QueryResponseMetadata, err error) pb.Response {
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	defer it.Close()
+	var values []string
+	for it.HasNext() {
+		kv, err := it.Next()
+		if err != nil {
+			return shim.Error(err.Error())
+		}
+		values = append(values, string(kv.Value))
+	}
+	return shim.Success([]byte(fmt.Sprintf("%s|%s", strings.Join(values, ","), meta.Bookmark)))
+}
+
+func TestWritesCommitOnlyOnSuccess(t *testing.T) {
+	ledger := New(kvChaincode{})
+
+	res := ledger.Invoke("Org1MSP", "put", "a", "1")
+	if res.Status != shim.OK || res.Payload != nil {
+		t.Fatalf("read in the writing transaction returned %q", res.Payload)
+	}
+	if res := ledger.Query("Org1MSP", "get", "a"); string(res.Payload) != "1" {
+		t.Fatalf("committed value is %q, expected 1", res.Payload)
+	}
+
+	ledger.Invoke("Org1MSP", "put", "a", "2", "fail")
+	ledger.Query("Org1MSP", "put", "a", "3")
+	if value := ledger.State("a"); string(value) != "1" {
+		t.Fatalf("value is %q after a failed transaction and a query, expected 1", value)
+	}
+}
+
+func TestPartialCompositeKeyPagination(t *testing.T) {
+	ledger := New(kvChaincode{})
+	for i := 1; i <= 5; i++ {
+		ledger.Invoke("Org1MSP", "putComposite", "item", fmt.Sprint(i), fmt.Sprint(i))
+	}
+	ledger.Invoke("Org1MSP", "putComposite", "other", "1", "x")
+
+	var got []string
+	bookmark := ""
+	for {
+		res := ledger.Query("Org1MSP", "page", "item", "2", bookmark)
+		parts := strings.Split(string(res.Payload), "|")
+		got = append(got, parts[0])
+		bookmark = parts[1]
+		if bookmark == "" {
+			break
+		}
+	}
+	if strings.Join(got, ";") != "1,2;3,4;5" {
+		t.Fatalf("pages %v", got)
+	}
+}
+
+func TestQuerySelectorAndSort(t *testing.T) {
+	ledger := New(kvChaincode{})
+	for i, doc := range []string{
+		`{"docType":"solution","owner":"p1","runtime":9}`,
+		`{"docType":"solution","owner":"p2","runtime":4}`,
+		`{"docType":"solution","owner":"p1","runtime":7}`,
+		`{"docType":"job","owner":"p1","runtime":1}`,
+	} {
+		ledger.Invoke("Org1MSP", "put", fmt.Sprint("k", i), doc)
+	}
+
+	query := `{"selector":{"docType":"solution","runtime":{"$gt":5}},"sort":[{"runtime":"asc"}]}`
+	res := ledger.Query("Org1MSP", "query", query, "")
+	if !strings.Contains(string(res.Payload), `"runtime":7},{"docType":"solution","owner":"p1","runtime":9}|`) {
+		t.Fatalf("query returned %s", res.Payload)
+	}
+
+	query = `{"selector":{"docType":"solution"},"sort":[{"runtime":"desc"}]}`
+	res = ledger.Query("Org1MSP", "query", query, "2")
+	if !strings.HasSuffix(string(res.Payload), `"runtime":4}|`) {
+		t.Fatalf("second page returned %s", res.Payload)
+	}
+}
+
+func TestClock(t *testing.T) {
+	ledger := New(kvChaincode{})
+	start := time.Unix(1000, 0)
+	ledger.SetTime(start)
+	ledger.Advance(90 * time.Second)
+	if res := ledger.Query("Org1MSP", "time"); string(res.Payload) != "1090" {
+		t.Fatalf("transaction stamped %s, expected 1090", res.Payload)
+	}
+}
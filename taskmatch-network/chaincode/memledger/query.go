@@ -0,0 +1,191 @@
+package memledger
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hyperledger/fabric/protos/ledger/queryresult"
+)
+
+// couchQuery is the part of a CouchDB query the ledger understands: a selector of
+// fields compared with a value or with $eq, $ne, $lt, $lte, $gt and $gte, and a sort.
+// Indexes are ignored, documents that aren't JSON objects never match.
+type couchQuery struct {
+	Selector map[string]interface{} `json:"selector"`
+	Sort     []map[string]string    `json:"sort"`
+	Limit    int                    `json:"limit"`
+	Skip     int                    `json:"skip"`
+}
+
+// queryIterator runs a query over the committed state, limited to pageSize results
+// after the offset in bookmark when pageSize is positive.
+func (s *Stub) queryIterator(query string, pageSize int32, bookmark string) (*stateIterator, error) {
+	var q couchQuery
+	if err := json.Unmarshal([]byte(query), &q); err != nil {
+		return nil, fmt.Errorf("invalid query %s: %s", query, err)
+	}
+	if q.Selector == nil {
+		return nil, fmt.Errorf("query has no selector: %s", query)
+	}
+	offset, err := offsetBookmark(bookmark)
+	if err != nil {
+		return nil, err
+	}
+
+	type document struct {
+		key   string
+		value []byte
+		doc   map[string]interface{}
+	}
+	var docs []document
+	for _, key := range s.ledger.sortedKeys(s.ledger.state, "", "") {
+		var doc map[string]interface{}
+		if json.Unmarshal(s.ledger.state[key], &doc) != nil {
+			continue
+		}
+		ok, err := matches(doc, q.Selector)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			docs = append(docs, document{key, s.ledger.state[key], doc})
+		}
+	}
+
+	if len(q.Sort) > 0 {
+		sort.SliceStable(docs, func(i, j int) bool {
+			for _, field := range q.Sort {
+				for name, direction := range field {
+					c := compare(lookup(docs[i].doc, name), lookup(docs[j].doc, name))
+					if c == 0 {
+						continue
+					}
+					if direction == "desc" {
+						return c > 0
+					}
+					return c < 0
+				}
+			}
+			return false
+		})
+	}
+
+	start := offset + q.Skip
+	if start > len(docs) {
+		start = len(docs)
+	}
+	docs = docs[start:]
+	if q.Limit > 0 && len(docs) > q.Limit {
+		docs = docs[:q.Limit]
+	}
+	next := ""
+	if pageSize > 0 && len(docs) > int(pageSize) {
+		docs = docs[:pageSize]
+		next = fmt.Sprint(start + int(pageSize))
+	}
+
+	kvs := make([]*queryresult.KV, len(docs))
+	for i, doc := range docs {
+		kvs[i] = &queryresult.KV{Namespace: "memledger", Key: doc.key, Value: doc.value}
+	}
+	return &stateIterator{kvs: kvs, bookmark: next}, nil
+}
+
+// matches reports whether a document satisfies every condition of a selector.
+func matches(doc map[string]interface{}, selector map[string]interface{}) (bool, error) {
+	for field, condition := range selector {
+		value := lookup(doc, field)
+		operators, ok := condition.(map[string]interface{})
+		if !ok {
+			if value == nil || compare(value, condition) != 0 {
+				return false, nil
+			}
+			continue
+		}
+		for operator, operand := range operators {
+			if value == nil {
+				return false, nil
+			}
+			c := compare(value, operand)
+			switch operator {
+			case "$eq":
+				ok = c == 0
+			case "$ne":
+				ok = c != 0
+			case "$lt":
+				ok = c < 0
+			case "$lte":
+				ok = c <= 0
+			case "$gt":
+				ok = c > 0
+			case "$gte":
+				ok = c >= 0
+			default:
+				return false, fmt.Errorf("unsupported selector operator %s", operator)
+			}
+			if !ok {
+				return false, nil
+			}
+		}
+	}
+	return true, nil
+}
+
+// lookup returns the value of a possibly dotted field, nil if it doesn't exist.
+func lookup(doc map[string]interface{}, field string) interface{} {
+	var value interface{} = doc
+	for _, name := range strings.Split(field, ".") {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = object[name]
+	}
+	return value
+}
+
+// compare orders JSON values the way CouchDB collates them for the types used here:
+// null < booleans < numbers < strings < everything else.
+func compare(a, b interface{}) int {
+	ra, rb := rank(a), rank(b)
+	if ra != rb {
+		return ra - rb
+	}
+	switch a := a.(type) {
+	case bool:
+		if a == b.(bool) {
+			return 0
+		}
+		if !a {
+			return -1
+		}
+		return 1
+	case float64:
+		switch {
+		case a < b.(float64):
+			return -1
+		case a > b.(float64):
+			return 1
+		}
+		return 0
+	case string:
+		return strings.Compare(a, b.(string))
+	}
+	return 0
+}
+
+func rank(value interface{}) int {
+	switch value.(type) {
+	case nil:
+		return 0
+	case bool:
+		return 1
+	case float64:
+		return 2
+	case string:
+		return 3
+	}
+	return 4
+}
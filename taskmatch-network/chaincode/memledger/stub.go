@@ -0,0 +1,427 @@
+package memledger
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	"github.com/hyperledger/fabric/protos/ledger/queryresult"
+	pb "github.com/hyperledger/fabric/protos/peer"
+)
+
+const (
+	minUnicodeRuneValue   = rune(0)
+	maxUnicodeRuneValue   = utf8.MaxRune
+	compositeKeyNamespace = "\x00"
+)
+
+// errNotSupported is returned by the stub calls that need a real peer.
+var errNotSupported = errors.New("not supported by the in-memory ledger")
+
+// Stub is the shim.ChaincodeStubInterface of one transaction on a Ledger. Reads see
+// the committed state only, never the transaction's own writes, as on a peer.
+type Stub struct {
+	ledger *Ledger
+
+	txID      string
+	args      [][]byte
+	creator   []byte
+	mspID     string
+	timestamp *timestamp.Timestamp
+	transient map[string][]byte
+
+	writes      map[string][]byte
+	deletes     map[string]bool
+	privWrites  map[string]map[string][]byte
+	validation  map[string][]byte
+	decorations map[string][]byte
+
+	eventName    string
+	eventPayload []byte
+}
+
+var _ shim.ChaincodeStubInterface = (*Stub)(nil)
+
+// GetArgs returns the arguments of the transaction.
+func (s *Stub) GetArgs() [][]byte {
+	return s.args
+}
+
+// GetStringArgs returns the arguments of the transaction as strings.
+func (s *Stub) GetStringArgs() []string {
+	args := make([]string, len(s.args))
+	for i, arg := range s.args {
+		args[i] = string(arg)
+	}
+	return args
+}
+
+// GetFunctionAndParameters splits the arguments into function name and parameters.
+func (s *Stub) GetFunctionAndParameters() (string, []string) {
+	args := s.GetStringArgs()
+	if len(args) == 0 {
+		return "", []string{}
+	}
+	return args[0], args[1:]
+}
+
+// GetArgsSlice returns the arguments concatenated.
+func (s *Stub) GetArgsSlice() ([]byte, error) {
+	var result []byte
+	for _, arg := range s.args {
+		result = append(result, arg...)
+	}
+	return result, nil
+}
+
+// GetTxID returns the transaction id.
+func (s *Stub) GetTxID() string {
+	return s.txID
+}
+
+// GetChannelID returns the channel of the ledger.
+func (s *Stub) GetChannelID() string {
+	return s.ledger.channel
+}
+
+// InvokeChaincode is not supported, the ledger only holds one chaincode.
+func (s *Stub) InvokeChaincode(chaincodeName string, args [][]byte, channel string) pb.Response {
+	return shim.Error(errNotSupported.Error())
+}
+
+// GetState returns the committed value of a key.
+func (s *Stub) GetState(key string) ([]byte, error) {
+	return s.ledger.state[key], nil
+}
+
+// PutState adds a write to the transaction.
+func (s *Stub) PutState(key string, value []byte) error {
+	if key == "" {
+		return errors.New("key must not be an empty string")
+	}
+	if value == nil {
+		value = []byte{}
+	}
+	s.writes[key] = append([]byte(nil), value...)
+	delete(s.deletes, key)
+	return nil
+}
+
+// DelState adds a delete to the transaction.
+func (s *Stub) DelState(key string) error {
+	delete(s.writes, key)
+	s.deletes[key] = true
+	return nil
+}
+
+// SetStateValidationParameter records a key level endorsement policy.
+func (s *Stub) SetStateValidationParameter(key string, ep []byte) error {
+	s.validation[key] = ep
+	return nil
+}
+
+// GetStateValidationParameter returns a policy set in this transaction.
+func (s *Stub) GetStateValidationParameter(key string) ([]byte, error) {
+	return s.validation[key], nil
+}
+
+// GetStateByRange iterates over the committed keys in [startKey, endKey).
+func (s *Stub) GetStateByRange(startKey, endKey string) (shim.StateQueryIteratorInterface, error) {
+	return s.rangeIterator(startKey, endKey, 0, "")
+}
+
+// GetStateByRangeWithPagination is GetStateByRange returning at most pageSize keys
+// starting at bookmark.
+func (s *Stub) GetStateByRangeWithPagination(startKey, endKey string, pageSize int32,
+	bookmark string) (shim.StateQueryIteratorInterface, *pb.QueryResponseMetadata, error) {
+	iterator, err := s.rangeIterator(startKey, endKey, pageSize, bookmark)
+	if err != nil {
+		return nil, nil, err
+	}
+	return iterator, iterator.metadata(), nil
+}
+
+// GetStateByPartialCompositeKey iterates over the composite keys starting with the
+// given object type and attributes.
+func (s *Stub) GetStateByPartialCompositeKey(objectType string, keys []string) (shim.StateQueryIteratorInterface, error) {
+	startKey, err := s.CreateCompositeKey(objectType, keys)
+	if err != nil {
+		return nil, err
+	}
+	return s.rangeIterator(startKey, startKey+string(maxUnicodeRuneValue), 0, "")
+}
+
+// GetStateByPartialCompositeKeyWithPagination is the paginated
+// GetStateByPartialCompositeKey.
+func (s *Stub) GetStateByPartialCompositeKeyWithPagination(objectType string, keys []string,
+	pageSize int32, bookmark string) (shim.StateQueryIteratorInterface, *pb.QueryResponseMetadata, error) {
+	startKey, err := s.CreateCompositeKey(objectType, keys)
+	if err != nil {
+		return nil, nil, err
+	}
+	iterator, err := s.rangeIterator(startKey, startKey+string(maxUnicodeRuneValue), pageSize, bookmark)
+	if err != nil {
+		return nil, nil, err
+	}
+	return iterator, iterator.metadata(), nil
+}
+
+// CreateCompositeKey joins an object type and attributes the way Fabric does.
+func (s *Stub) CreateCompositeKey(objectType string, attributes []string) (string, error) {
+	if err := validateCompositeKeyAttribute(objectType); err != nil {
+		return "", err
+	}
+	key := compositeKeyNamespace + objectType + string(minUnicodeRuneValue)
+	for _, attribute := range attributes {
+		if err := validateCompositeKeyAttribute(attribute); err != nil {
+			return "", err
+		}
+		key += attribute + string(minUnicodeRuneValue)
+	}
+	return key, nil
+}
+
+// SplitCompositeKey splits a key made by CreateCompositeKey.
+func (s *Stub) SplitCompositeKey(compositeKey string) (string, []string, error) {
+	if !strings.HasPrefix(compositeKey, compositeKeyNamespace) {
+		return "", nil, fmt.Errorf("not a composite key: %q", compositeKey)
+	}
+	parts := strings.Split(compositeKey[1:], string(minUnicodeRuneValue))
+	if len(parts) < 2 {
+		return "", nil, fmt.Errorf("not a composite key: %q", compositeKey)
+	}
+	//the key ends with a separator, so the last part is always empty
+	return parts[0], parts[1 : len(parts)-1], nil
+}
+
+// GetQueryResult runs a CouchDB selector query over the committed state, see query.go
+// for the supported subset.
+func (s *Stub) GetQueryResult(query string) (shim.StateQueryIteratorInterface, error) {
+	iterator, err := s.queryIterator(query, 0, "")
+	if err != nil {
+		return nil, err
+	}
+	return iterator, nil
+}
+
+// GetQueryResultWithPagination is the paginated GetQueryResult. The bookmark is the
+// offset of the next result.
+func (s *Stub) GetQueryResultWithPagination(query string, pageSize int32,
+	bookmark string) (shim.StateQueryIteratorInterface, *pb.QueryResponseMetadata, error) {
+	iterator, err := s.queryIterator(query, pageSize, bookmark)
+	if err != nil {
+		return nil, nil, err
+	}
+	return iterator, iterator.metadata(), nil
+}
+
+// GetHistoryForKey iterates over the committed modifications of a key, oldest first.
+func (s *Stub) GetHistoryForKey(key string) (shim.HistoryQueryIteratorInterface, error) {
+	return &historyIterator{modifications: append([]*queryresult.KeyModification(nil), s.ledger.history[key]...)}, nil
+}
+
+// GetPrivateData returns the committed value of a key in a collection.
+func (s *Stub) GetPrivateData(collection, key string) ([]byte, error) {
+	if !s.ledger.canRead(collection, s.mspID) {
+		return nil, fmt.Errorf("tx creator does not have read access permission on privatedata in chaincodeName:%s collectionName: %s", "memledger", collection)
+	}
+	return s.ledger.private[collection][key], nil
+}
+
+// GetPrivateDataHash is not supported.
+func (s *Stub) GetPrivateDataHash(collection, key string) ([]byte, error) {
+	return nil, errNotSupported
+}
+
+// PutPrivateData adds a private write to the transaction.
+func (s *Stub) PutPrivateData(collection string, key string, value []byte) error {
+	if key == "" {
+		return errors.New("key must not be an empty string")
+	}
+	if value == nil {
+		value = []byte{}
+	}
+	s.privateWrites(collection)[key] = append([]byte(nil), value...)
+	return nil
+}
+
+// DelPrivateData adds a private delete to the transaction.
+func (s *Stub) DelPrivateData(collection, key string) error {
+	s.privateWrites(collection)[key] = nil
+	return nil
+}
+
+// SetPrivateDataValidationParameter is not supported.
+func (s *Stub) SetPrivateDataValidationParameter(collection, key string, ep []byte) error {
+	return errNotSupported
+}
+
+// GetPrivateDataValidationParameter is not supported.
+func (s *Stub) GetPrivateDataValidationParameter(collection, key string) ([]byte, error) {
+	return nil, errNotSupported
+}
+
+// GetPrivateDataByRange is not supported.
+func (s *Stub) GetPrivateDataByRange(collection, startKey, endKey string) (shim.StateQueryIteratorInterface, error) {
+	return nil, errNotSupported
+}
+
+// GetPrivateDataByPartialCompositeKey is not supported.
+func (s *Stub) GetPrivateDataByPartialCompositeKey(collection, objectType string, keys []string) (shim.StateQueryIteratorInterface, error) {
+	return nil, errNotSupported
+}
+
+// GetPrivateDataQueryResult is not supported.
+func (s *Stub) GetPrivateDataQueryResult(collection, query string) (shim.StateQueryIteratorInterface, error) {
+	return nil, errNotSupported
+}
+
+// GetCreator returns the serialized identity of the sending MSP, without certificate.
+func (s *Stub) GetCreator() ([]byte, error) {
+	return s.creator, nil
+}
+
+// GetTransient returns the transient map of the transaction.
+func (s *Stub) GetTransient() (map[string][]byte, error) {
+	return s.transient, nil
+}
+
+// GetBinding is not supported.
+func (s *Stub) GetBinding() ([]byte, error) {
+	return nil, errNotSupported
+}
+
+// GetDecorations returns no decorations.
+func (s *Stub) GetDecorations() map[string][]byte {
+	return s.decorations
+}
+
+// GetSignedProposal is not supported.
+func (s *Stub) GetSignedProposal() (*pb.SignedProposal, error) {
+	return nil, errNotSupported
+}
+
+// GetTxTimestamp returns the ledger time the transaction was sent at.
+func (s *Stub) GetTxTimestamp() (*timestamp.Timestamp, error) {
+	return s.timestamp, nil
+}
+
+// SetEvent sets the event of the transaction, a later call replaces it.
+func (s *Stub) SetEvent(name string, payload []byte) error {
+	if name == "" {
+		return errors.New("event name can not be nil string")
+	}
+	s.eventName = name
+	s.eventPayload = payload
+	return nil
+}
+
+func (s *Stub) privateWrites(collection string) map[string][]byte {
+	if s.privWrites[collection] == nil {
+		s.privWrites[collection] = map[string][]byte{}
+	}
+	return s.privWrites[collection]
+}
+
+// rangeIterator returns the committed keys in [startKey, endKey), limited to pageSize
+// keys from bookmark on when pageSize is positive.
+func (s *Stub) rangeIterator(startKey, endKey string, pageSize int32, bookmark string) (*stateIterator, error) {
+	if bookmark != "" && bookmark > startKey {
+		startKey = bookmark
+	}
+	keys := s.ledger.sortedKeys(s.ledger.state, startKey, endKey)
+	next := ""
+	if pageSize > 0 && len(keys) > int(pageSize) {
+		next = keys[pageSize]
+		keys = keys[:pageSize]
+	}
+	kvs := make([]*queryresult.KV, len(keys))
+	for i, key := range keys {
+		kvs[i] = &queryresult.KV{Namespace: "memledger", Key: key, Value: s.ledger.state[key]}
+	}
+	return &stateIterator{kvs: kvs, bookmark: next}, nil
+}
+
+func validateCompositeKeyAttribute(attribute string) error {
+	if !utf8.ValidString(attribute) {
+		return fmt.Errorf("not a valid utf8 string: [%x]", attribute)
+	}
+	for _, r := range attribute {
+		if r == minUnicodeRuneValue || r == maxUnicodeRuneValue {
+			return fmt.Errorf("input contains unicode %#U starting at position [%d]. %#U and %#U are not allowed in the input attribute of a composite key",
+				r, strings.IndexRune(attribute, r), minUnicodeRuneValue, maxUnicodeRuneValue)
+		}
+	}
+	return nil
+}
+
+// stateIterator iterates over a snapshot of key/value pairs.
+type stateIterator struct {
+	kvs      []*queryresult.KV
+	next     int
+	bookmark string
+}
+
+// HasNext reports whether there are more results.
+func (it *stateIterator) HasNext() bool {
+	return it.next < len(it.kvs)
+}
+
+// Next returns the next result.
+func (it *stateIterator) Next() (*queryresult.KV, error) {
+	if !it.HasNext() {
+		return nil, errors.New("no more results")
+	}
+	it.next++
+	return it.kvs[it.next-1], nil
+}
+
+// Close releases the iterator.
+func (it *stateIterator) Close() error {
+	return nil
+}
+
+func (it *stateIterator) metadata() *pb.QueryResponseMetadata {
+	return &pb.QueryResponseMetadata{FetchedRecordsCount: int32(len(it.kvs)), Bookmark: it.bookmark}
+}
+
+// historyIterator iterates over the modifications of a key.
+type historyIterator struct {
+	modifications []*queryresult.KeyModification
+	next          int
+}
+
+// HasNext reports whether there are more modifications.
+func (it *historyIterator) HasNext() bool {
+	return it.next < len(it.modifications)
+}
+
+// Next returns the next modification.
+func (it *historyIterator) Next() (*queryresult.KeyModification, error) {
+	if !it.HasNext() {
+		return nil, errors.New("no more results")
+	}
+	it.next++
+	return it.modifications[it.next-1], nil
+}
+
+// Close releases the iterator.
+func (it *historyIterator) Close() error {
+	return nil
+}
+
+// offsetBookmark parses a bookmark made of a result offset.
+func offsetBookmark(bookmark string) (int, error) {
+	if bookmark == "" {
+		return 0, nil
+	}
+	offset, err := strconv.Atoi(bookmark)
+	if err != nil || offset < 0 {
+		return 0, fmt.Errorf("invalid bookmark %q", bookmark)
+	}
+	return offset, nil
+}
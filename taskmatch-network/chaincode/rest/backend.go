@@ -0,0 +1,82 @@
+package rest
+
+import (
+	"context"
+
+	"github.com/chaincode/memledger"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+// Backend runs the functions of the taskmatching chaincode for the server.
+type Backend interface {
+	// Evaluate runs a function that reads the ledger, without a transaction.
+	Evaluate(function string, args ...string) ([]byte, error)
+	// Submit runs a function as a transaction and waits until it is committed.
+	Submit(function string, transient map[string][]byte, args ...string) ([]byte, error)
+	// Subscribe returns the events of the transactions committed from now on, until ctx
+	// is done, when the channel is closed.
+	Subscribe(ctx context.Context) (<-chan Event, error)
+}
+
+// Event is a chaincode event.
+type Event struct {
+	Name    string
+	Payload []byte
+}
+
+// ChaincodeError is the message of a function the chaincode refused to run.
+type ChaincodeError struct {
+	Message string
+}
+
+func (e *ChaincodeError) Error() string {
+	return e.Message
+}
+
+// LedgerBackend runs the chaincode on an in-memory ledger, every transaction sent by
+// the same org. It is meant for trying the server out and for tests.
+type LedgerBackend struct {
+	Ledger *memledger.Ledger
+	MSPID  string
+}
+
+// Evaluate queries the ledger.
+func (b *LedgerBackend) Evaluate(function string, args ...string) ([]byte, error) {
+	res := b.Ledger.Query(b.MSPID, append([]string{function}, args...)...)
+	if res.Status != shim.OK {
+		return nil, &ChaincodeError{res.Message}
+	}
+	return res.Payload, nil
+}
+
+// Submit invokes the chaincode.
+func (b *LedgerBackend) Submit(function string, transient map[string][]byte, args ...string) ([]byte, error) {
+	res := b.Ledger.InvokeWithTransient(b.MSPID, transient, append([]string{function}, args...)...)
+	if res.Status != shim.OK {
+		return nil, &ChaincodeError{res.Message}
+	}
+	return res.Payload, nil
+}
+
+// Subscribe follows the events of the ledger.
+func (b *LedgerBackend) Subscribe(ctx context.Context) (<-chan Event, error) {
+	ledgerEvents := b.Ledger.Subscribe()
+	events := make(chan Event)
+	go func() {
+		defer close(events)
+		defer b.Ledger.Unsubscribe(ledgerEvents)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event := <-ledgerEvents:
+				select {
+				case events <- Event{event.Name, event.Payload}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return events, nil
+}
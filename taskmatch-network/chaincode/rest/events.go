@@ -0,0 +1,77 @@
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// the states a job doesn't leave again
+var finalStates = map[string]bool{"CLOSED": true, "EXPIRED": true, "CANCELLED": true}
+
+// events serves GET /jobs/{id}/events as server-sent events. The stream starts with a
+// "job" event holding the job as it is, followed by the chaincode events of the job
+// (jobCreated, solutionSubmitted, jobFinished, solutionImproved) as they are committed.
+// It ends after jobFinished, or right away if the job is already finished.
+func (s *Server) events(w http.ResponseWriter, r *http.Request, id string, job map[string]json.RawMessage) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "the connection can't stream events")
+		return
+	}
+	ctx := r.Context()
+	events, err := s.backend.Subscribe(ctx)
+	if err != nil {
+		writeBackendError(w, err)
+		return
+	}
+
+	//read the job again now that nothing can be missed, it may have changed meanwhile
+	if current, err := s.readJob(id); err == nil {
+		job = current
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	snapshot, _ := json.Marshal(job)
+	writeEvent(w, "job", snapshot)
+	flusher.Flush()
+	var state string
+	json.Unmarshal(job["state"], &state)
+	if finalStates[state] {
+		return
+	}
+
+	heartbeat := time.NewTicker(s.Heartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+			flusher.Flush()
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			var payload struct {
+				Job string `json:"job"`
+			}
+			if json.Unmarshal(event.Payload, &payload) != nil || payload.Job != id {
+				continue
+			}
+			writeEvent(w, event.Name, event.Payload)
+			flusher.Flush()
+			if event.Name == "jobFinished" {
+				return
+			}
+		}
+	}
+}
+
+func writeEvent(w http.ResponseWriter, name string, data []byte) {
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", name, data)
+}
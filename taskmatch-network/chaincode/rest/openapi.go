@@ -0,0 +1,204 @@
+package rest
+
+import "net/http"
+
+func serveOpenAPI(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, http.MethodGet)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(OpenAPI))
+}
+
+// OpenAPI is the OpenAPI 3 description of the routes of the server.
+const OpenAPI = `{
+  "openapi": "3.0.3",
+  "info": {
+    "title": "taskmatching gateway",
+    "version": "1.0.0",
+    "description": "Create task matching jobs and follow how the solvers of the taskmatching chaincode solve them."
+  },
+  "paths": {
+    "/jobs": {
+      "post": {
+        "summary": "Create a job (createTaskMatching)",
+        "operationId": "createJob",
+        "requestBody": {
+          "required": true,
+          "content": {"application/json": {"schema": {"$ref": "#/components/schemas/JobRequest"}}}
+        },
+        "responses": {
+          "201": {
+            "description": "The job was created",
+            "headers": {"Location": {"schema": {"type": "string"}, "description": "/jobs/{id}"}},
+            "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Job"}}}
+          },
+          "400": {"$ref": "#/components/responses/Error"},
+          "409": {"$ref": "#/components/responses/Error"},
+          "502": {"$ref": "#/components/responses/Error"}
+        }
+      }
+    },
+    "/jobs/{id}": {
+      "parameters": [{"$ref": "#/components/parameters/JobID"}],
+      "get": {
+        "summary": "Read a job (readTaskMatching)",
+        "operationId": "getJob",
+        "responses": {
+          "200": {"description": "The job", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Job"}}}},
+          "404": {"$ref": "#/components/responses/Error"}
+        }
+      }
+    },
+    "/jobs/{id}/solutions": {
+      "parameters": [{"$ref": "#/components/parameters/JobID"}],
+      "get": {
+        "summary": "List the saved solutions of a job (listSolutions)",
+        "operationId": "listSolutions",
+        "parameters": [
+          {"name": "pageSize", "in": "query", "schema": {"type": "integer", "minimum": 1, "default": 10}},
+          {"name": "bookmark", "in": "query", "schema": {"type": "string"}, "description": "bookmark of the previous page"}
+        ],
+        "responses": {
+          "200": {"description": "A page of solutions", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/SolutionPage"}}}},
+          "400": {"$ref": "#/components/responses/Error"},
+          "404": {"$ref": "#/components/responses/Error"}
+        }
+      }
+    },
+    "/jobs/{id}/events": {
+      "parameters": [{"$ref": "#/components/parameters/JobID"}],
+      "get": {
+        "summary": "Follow a job as server-sent events",
+        "description": "A job event with the job as it is, then the chaincode events of the job (jobCreated, solutionSubmitted, jobFinished, solutionImproved) with a JobEvent as data. The stream ends after jobFinished.",
+        "operationId": "jobEvents",
+        "responses": {
+          "200": {"description": "The event stream", "content": {"text/event-stream": {"schema": {"type": "string"}}}},
+          "404": {"$ref": "#/components/responses/Error"}
+        }
+      }
+    },
+    "/solvers": {
+      "get": {
+        "summary": "The solvers with their current submission and statistics (getSolverStats)",
+        "operationId": "listSolvers",
+        "responses": {
+          "200": {
+            "description": "Every solver",
+            "content": {"application/json": {"schema": {"type": "array", "items": {"$ref": "#/components/schemas/Solver"}}}}
+          }
+        }
+      }
+    }
+  },
+  "components": {
+    "parameters": {
+      "JobID": {"name": "id", "in": "path", "required": true, "schema": {"type": "string"}}
+    },
+    "responses": {
+      "Error": {
+        "description": "The request failed",
+        "content": {"application/json": {"schema": {"type": "object", "properties": {"error": {"type": "string"}}}}}
+      }
+    },
+    "schemas": {
+      "JobRequest": {
+        "type": "object",
+        "required": ["id", "runtimes"],
+        "properties": {
+          "id": {"type": "string"},
+          "runtimes": {"type": "array", "items": {"type": "array", "items": {"type": "integer", "minimum": 0}}, "description": "runtime of every task (row) on every resource (column)"},
+          "timeout": {"type": "integer", "description": "seconds the solvers get to submit, 300 if left out"},
+          "commitTimeout": {"type": "integer", "description": "seconds of the commit phase, none if left out"},
+          "collection": {"type": "string", "description": "private data collection to keep the runtimes in"},
+          "bounty": {"type": "integer", "description": "tokens put on the job"}
+        }
+      },
+      "Job": {
+        "type": "object",
+        "properties": {
+          "id": {"type": "string"},
+          "runtimes": {"type": "string", "description": "the matrix as JSON, empty for private jobs"},
+          "state": {"type": "string", "enum": ["OPEN", "SOLVING", "CLOSED", "EXPIRED", "CANCELLED"]},
+          "created": {"type": "integer", "description": "unix time"},
+          "deadline": {"type": "integer", "description": "unix time"},
+          "commitDeadline": {"type": "integer", "description": "unix time"},
+          "collection": {"type": "string"},
+          "runtimesHash": {"type": "string"},
+          "creator": {"type": "string"},
+          "bounty": {"type": "integer"},
+          "rewardRule": {"type": "string"},
+          "winnerShare": {"type": "integer"},
+          "improvementPool": {"type": "integer", "description": "tokens held for the first accepted improvement"},
+          "bestSolution": {"type": "integer"},
+          "bestRuntime": {"type": "integer"}
+        }
+      },
+      "Solution": {
+        "type": "object",
+        "properties": {
+          "runtime": {"type": "integer", "description": "makespan"},
+          "sol": {"type": "array", "items": {"type": "integer"}, "description": "resource of every task"},
+          "owner": {"type": "string"},
+          "alg": {"type": "string"},
+          "job": {"type": "string"},
+          "improvedFrom": {"type": "integer"}
+        }
+      },
+      "SolutionPage": {
+        "type": "object",
+        "properties": {
+          "solutions": {
+            "type": "array",
+            "items": {
+              "type": "object",
+              "properties": {"job": {"type": "string"}, "number": {"type": "integer"}, "record": {"$ref": "#/components/schemas/Solution"}}
+            }
+          },
+          "bookmark": {"type": "string", "description": "pass as bookmark to get the next page, empty on the last one"}
+        }
+      },
+      "Solver": {
+        "type": "object",
+        "properties": {
+          "id": {"type": "string"},
+          "name": {"type": "string"},
+          "status": {"type": "string"},
+          "job": {"type": "string"},
+          "runtime": {"type": "integer"},
+          "sol": {"type": "array", "items": {"type": "integer"}},
+          "org": {"type": "string"},
+          "alg": {"type": "string"},
+          "stats": {
+            "type": "object",
+            "properties": {
+              "jobs": {"type": "integer"},
+              "wins": {"type": "integer"},
+              "solved": {"type": "integer"},
+              "avgGap": {"type": "number"},
+              "missedDeadlines": {"type": "integer"},
+              "invalid": {"type": "integer"}
+            }
+          }
+        }
+      },
+      "JobEvent": {
+        "type": "object",
+        "properties": {
+          "job": {"type": "string"},
+          "state": {"type": "string"},
+          "creator": {"type": "string"},
+          "deadline": {"type": "integer"},
+          "commitDeadline": {"type": "integer"},
+          "collection": {"type": "string"},
+          "bounty": {"type": "integer"},
+          "bestSolution": {"type": "integer"},
+          "bestRuntime": {"type": "integer"},
+          "solver": {"type": "string"}
+        }
+      }
+    }
+  }
+}
+`
@@ -0,0 +1,256 @@
+// Package rest is an HTTP gateway to the taskmatching chaincode for clients that can't
+// talk to the peers themselves. Its JSON routes map onto the functions of the chaincode:
+//
+//	POST /jobs                   createTaskMatching
+//	GET  /jobs/{id}              readTaskMatching
+//	GET  /jobs/{id}/solutions    listSolutions
+//	GET  /jobs/{id}/events       the chaincode events of the job, as server-sent events
+//	GET  /solvers                getSolverStats and readTaskMatching of every solver
+//	GET  /openapi.json           the OpenAPI description of the routes
+//
+// The chaincode is reached through a Backend: a Fabric gateway, or LedgerBackend, which
+// runs it on an in-memory ledger.
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Server serves the routes of the gateway.
+type Server struct {
+	backend Backend
+	mux     *http.ServeMux
+
+	// Heartbeat is how often a comment is sent on idle event streams, so proxies don't
+	// close them.
+	Heartbeat time.Duration
+}
+
+// NewServer returns a server for the chaincode behind backend.
+func NewServer(backend Backend) *Server {
+	s := &Server{backend, http.NewServeMux(), 15 * time.Second}
+	s.mux.HandleFunc("/jobs", s.jobs)
+	s.mux.HandleFunc("/jobs/", s.job)
+	s.mux.HandleFunc("/solvers", s.solvers)
+	s.mux.HandleFunc("/openapi.json", serveOpenAPI)
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// jobRequest is the body of POST /jobs, the arguments of createTaskMatching.
+type jobRequest struct {
+	ID            string  `json:"id"`
+	Runtimes      [][]int `json:"runtimes"`
+	Timeout       int     `json:"timeout"`       //seconds, the chaincode's default if 0
+	CommitTimeout int     `json:"commitTimeout"` //seconds of the commit phase, none if 0
+	Collection    string  `json:"collection"`    //private data collection for the runtimes
+	Bounty        int64   `json:"bounty"`
+}
+
+// jobs serves POST /jobs.
+func (s *Server) jobs(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		methodNotAllowed(w, http.MethodPost)
+		return
+	}
+	var req jobRequest
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid job: "+err.Error())
+		return
+	}
+	if req.ID == "" || len(req.Runtimes) == 0 {
+		writeError(w, http.StatusBadRequest, "a job needs an id and runtimes")
+		return
+	}
+	if req.Timeout < 0 || req.CommitTimeout < 0 || req.Bounty < 0 {
+		writeError(w, http.StatusBadRequest, "timeouts and bounty can't be negative")
+		return
+	}
+	matrix, _ := json.Marshal(req.Runtimes)
+
+	// 0       1          2             3                4               5
+	//id   runtimes  [timeout]  [commit timeout]  [private collection]  [bounty]
+	args := []string{req.ID, string(matrix), "", "", req.Collection, ""}
+	if req.Timeout > 0 {
+		args[2] = strconv.Itoa(req.Timeout)
+	}
+	if req.CommitTimeout > 0 {
+		args[3] = strconv.Itoa(req.CommitTimeout)
+	}
+	if req.Bounty > 0 {
+		args[5] = strconv.FormatInt(req.Bounty, 10)
+	}
+	var transient map[string][]byte
+	if req.Collection != "" {
+		args[1] = ""
+		transient = map[string][]byte{"runtimes": matrix}
+	}
+	if _, err := s.backend.Submit("createTaskMatching", transient, args...); err != nil {
+		writeBackendError(w, err)
+		return
+	}
+
+	job, err := s.readJob(req.ID)
+	if err != nil {
+		writeBackendError(w, err)
+		return
+	}
+	w.Header().Set("Location", "/jobs/"+req.ID)
+	writeJSON(w, http.StatusCreated, job)
+}
+
+// job serves the routes under /jobs/{id}.
+func (s *Server) job(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, http.MethodGet)
+		return
+	}
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/jobs/"), "/")
+	if parts[0] == "" || len(parts) > 2 {
+		writeError(w, http.StatusNotFound, "no such route "+r.URL.Path)
+		return
+	}
+	id := parts[0]
+	job, err := s.readJob(id)
+	if err != nil {
+		writeBackendError(w, err)
+		return
+	}
+
+	if len(parts) == 1 {
+		writeJSON(w, http.StatusOK, job)
+		return
+	}
+	switch parts[1] {
+	case "solutions":
+		s.solutions(w, r, id)
+	case "events":
+		s.events(w, r, id, job)
+	default:
+		writeError(w, http.StatusNotFound, "no such route "+r.URL.Path)
+	}
+}
+
+// solutions serves GET /jobs/{id}/solutions?pageSize=n&bookmark=b.
+func (s *Server) solutions(w http.ResponseWriter, r *http.Request, id string) {
+	pageSize := r.URL.Query().Get("pageSize")
+	if pageSize == "" {
+		pageSize = "10"
+	}
+	if n, err := strconv.Atoi(pageSize); err != nil || n < 1 {
+		writeError(w, http.StatusBadRequest, "pageSize must be a positive number")
+		return
+	}
+	page, err := s.backend.Evaluate("listSolutions", id, pageSize, r.URL.Query().Get("bookmark"))
+	if err != nil {
+		writeBackendError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, json.RawMessage(page))
+}
+
+// solvers serves GET /solvers: the record and the statistics of every solver.
+func (s *Server) solvers(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, http.MethodGet)
+		return
+	}
+	statsAsBytes, err := s.backend.Evaluate("getSolverStats")
+	if err != nil {
+		writeBackendError(w, err)
+		return
+	}
+	var stats []json.RawMessage
+	if err := json.Unmarshal(statsAsBytes, &stats); err != nil {
+		writeError(w, http.StatusBadGateway, "unexpected statistics: "+err.Error())
+		return
+	}
+
+	solvers := []map[string]json.RawMessage{}
+	for _, solverStats := range stats {
+		var id struct {
+			Solver string `json:"solver"`
+		}
+		json.Unmarshal(solverStats, &id)
+		record, err := s.backend.Evaluate("readTaskMatching", "solver", id.Solver)
+		if err != nil {
+			writeBackendError(w, err)
+			return
+		}
+		solver := map[string]json.RawMessage{}
+		if err := json.Unmarshal(record, &solver); err != nil {
+			writeError(w, http.StatusBadGateway, "unexpected solver record: "+err.Error())
+			return
+		}
+		solver["id"], _ = json.Marshal(id.Solver)
+		solver["stats"] = solverStats
+		solvers = append(solvers, solver)
+	}
+	writeJSON(w, http.StatusOK, solvers)
+}
+
+// readJob reads a job record, with its id added, which the chaincode leaves out.
+func (s *Server) readJob(id string) (map[string]json.RawMessage, error) {
+	record, err := s.backend.Evaluate("readTaskMatching", id)
+	if err != nil {
+		return nil, err
+	}
+	job := map[string]json.RawMessage{}
+	if err := json.Unmarshal(record, &job); err != nil {
+		return nil, fmt.Errorf("unexpected job record: %s", err)
+	}
+	job["id"], _ = json.Marshal(id)
+	return job, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(value)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
+
+func methodNotAllowed(w http.ResponseWriter, allowed string) {
+	w.Header().Set("Allow", allowed)
+	writeError(w, http.StatusMethodNotAllowed, "only "+allowed+" is allowed")
+}
+
+// writeBackendError answers with the message of err: 404 for missing records, 409 for
+// conflicts, 400 for anything else the chaincode refused and 502 if the backend failed.
+func writeBackendError(w http.ResponseWriter, err error) {
+	message := err.Error()
+	//readTaskMatching wraps its messages in JSON
+	var wrapped struct {
+		Error string
+	}
+	if json.Unmarshal([]byte(message), &wrapped) == nil && wrapped.Error != "" {
+		message = wrapped.Error
+	}
+
+	_, refused := err.(*ChaincodeError)
+	switch {
+	case strings.Contains(message, "does not exist"):
+		writeError(w, http.StatusNotFound, message)
+	case strings.Contains(message, "already exists") || strings.Contains(message, "still open"):
+		writeError(w, http.StatusConflict, message)
+	case refused:
+		writeError(w, http.StatusBadRequest, message)
+	default:
+		writeError(w, http.StatusBadGateway, message)
+	}
+}
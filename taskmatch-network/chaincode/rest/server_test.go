@@ -0,0 +1,191 @@
+package rest
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/chaincode/memledger"
+	"github.com/chaincode/taskmatch"
+)
+
+func newTestServer(t *testing.T) (*httptest.Server, *LedgerBackend) {
+	t.Helper()
+	ledger := memledger.New(new(taskmatch.SimpleChaincode))
+	for name, members := range taskmatch.Collections() {
+		ledger.AddCollection(name, members...)
+	}
+	backend := &LedgerBackend{ledger, "Org1MSP"}
+	if _, err := backend.Submit("Initialize", nil); err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(NewServer(backend))
+	t.Cleanup(server.Close)
+	return server, backend
+}
+
+// request sends a request and decodes the JSON answer into result, if it isn't nil.
+func request(t *testing.T, method, url, body string, status int, result interface{}) *http.Response {
+	t.Helper()
+	req, _ := http.NewRequest(method, url, strings.NewReader(body))
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	var answer json.RawMessage
+	json.NewDecoder(res.Body).Decode(&answer)
+	if res.StatusCode != status {
+		t.Fatalf("%s %s answered %d %s, expected %d", method, url, res.StatusCode, answer, status)
+	}
+	if result != nil {
+		if err := json.Unmarshal(answer, result); err != nil {
+			t.Fatalf("%s %s answered %s: %s", method, url, answer, err)
+		}
+	}
+	return res
+}
+
+type testJob struct {
+	ID          string `json:"id"`
+	Runtimes    string `json:"runtimes"`
+	State       string `json:"state"`
+	Collection  string `json:"collection"`
+	BestRuntime int    `json:"bestRuntime"`
+}
+
+func solveAll(t *testing.T, backend *LedgerBackend, jobID string) {
+	t.Helper()
+	//every solver submits from its own org
+	for i, peer := range []string{"p1", "p2", "p3"} {
+		solverBackend := &LedgerBackend{backend.Ledger, "Org" + strconv.Itoa(i+1) + "MSP"}
+		if _, err := solverBackend.Submit("calculateTaskMatching", nil, peer, jobID); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestJobs(t *testing.T) {
+	server, backend := newTestServer(t)
+
+	var job testJob
+	res := request(t, "POST", server.URL+"/jobs", `{"id":"work","runtimes":[[1,2,3],[4,5,6],[7,8,9]],"timeout":60}`, http.StatusCreated, &job)
+	if job.ID != "work" || job.State != "OPEN" || job.Runtimes != "[[1,2,3],[4,5,6],[7,8,9]]" {
+		t.Fatalf("created %+v", job)
+	}
+	if location := res.Header.Get("Location"); location != "/jobs/work" {
+		t.Fatalf("location %s", location)
+	}
+
+	request(t, "POST", server.URL+"/jobs", `{"id":"work","runtimes":[[1]]}`, http.StatusConflict, nil)
+	request(t, "POST", server.URL+"/jobs", `{"id":"other","runtimes":[[1.5]]}`, http.StatusBadRequest, nil)
+	request(t, "POST", server.URL+"/jobs", `{"id":"other","runtimes":[[1],[2,3]]}`, http.StatusBadRequest, nil)
+	request(t, "POST", server.URL+"/jobs", `{"id":"other","runtimes":[[1,2],[3,4]]}`, http.StatusConflict, nil) //work is still open
+	request(t, "GET", server.URL+"/jobs", "", http.StatusMethodNotAllowed, nil)
+	request(t, "GET", server.URL+"/jobs/nope", "", http.StatusNotFound, nil)
+	request(t, "GET", server.URL+"/jobs/work/nope", "", http.StatusNotFound, nil)
+
+	var solvers []struct {
+		ID     string `json:"id"`
+		Status string `json:"status"`
+		Job    string `json:"job"`
+		Stats  struct {
+			Jobs int `json:"jobs"`
+			Wins int `json:"wins"`
+		} `json:"stats"`
+	}
+	request(t, "GET", server.URL+"/solvers", "", http.StatusOK, &solvers)
+	if len(solvers) != 3 || solvers[0].ID != "p1" || solvers[0].Status != "waiting" || solvers[0].Job != "work" {
+		t.Fatalf("solvers %+v", solvers)
+	}
+
+	solveAll(t, backend, "work")
+	request(t, "GET", server.URL+"/jobs/work", "", http.StatusOK, &job)
+	if job.State != "CLOSED" || job.BestRuntime == 0 {
+		t.Fatalf("job after solving %+v", job)
+	}
+	var page struct {
+		Solutions []struct {
+			Job    string `json:"job"`
+			Number int    `json:"number"`
+		} `json:"solutions"`
+	}
+	request(t, "GET", server.URL+"/jobs/work/solutions?pageSize=5", "", http.StatusOK, &page)
+	if len(page.Solutions) != 1 || page.Solutions[0].Job != "work" {
+		t.Fatalf("solutions %+v", page)
+	}
+	request(t, "GET", server.URL+"/jobs/work/solutions?pageSize=0", "", http.StatusBadRequest, nil)
+	request(t, "GET", server.URL+"/solvers", "", http.StatusOK, &solvers)
+	if solvers[0].Stats.Jobs != 1 {
+		t.Fatalf("statistics after the job %+v", solvers[0].Stats)
+	}
+
+	//private runtimes go into the transient map
+	request(t, "POST", server.URL+"/jobs", `{"id":"secret","runtimes":[[1,2],[3,4]],"collection":"etcOrg1Org2"}`, http.StatusCreated, &job)
+	if job.Runtimes != "" || job.Collection != "etcOrg1Org2" {
+		t.Fatalf("private job %+v", job)
+	}
+}
+
+func TestJobEvents(t *testing.T) {
+	server, backend := newTestServer(t)
+	request(t, "POST", server.URL+"/jobs", `{"id":"work","runtimes":[[1,2,3],[4,5,6],[7,8,9]]}`, http.StatusCreated, nil)
+
+	res, err := http.Get(server.URL + "/jobs/work/events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	if res.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("content type %s", res.Header.Get("Content-Type"))
+	}
+
+	//the snapshot is sent before anything happens, then the events follow
+	lines := bufio.NewScanner(res.Body)
+	lines.Scan()
+	if lines.Text() != "event: job" {
+		t.Fatalf("stream starts with %q", lines.Text())
+	}
+	solveAll(t, backend, "work")
+	var names []string
+	for lines.Scan() { //the stream ends after jobFinished
+		if strings.HasPrefix(lines.Text(), "event: ") {
+			names = append(names, strings.TrimPrefix(lines.Text(), "event: "))
+		}
+	}
+	if strings.Join(names, ",") != "solutionSubmitted,solutionSubmitted,jobFinished" {
+		t.Fatalf("events %v", names)
+	}
+
+	//a finished job only gets its snapshot
+	res, err = http.Get(server.URL + "/jobs/work/events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	lines = bufio.NewScanner(res.Body)
+	var all []string
+	for lines.Scan() {
+		all = append(all, lines.Text())
+	}
+	if len(all) != 3 || !strings.Contains(all[1], `"state":"CLOSED"`) {
+		t.Fatalf("stream of a finished job %q", all)
+	}
+}
+
+func TestOpenAPI(t *testing.T) {
+	server, _ := newTestServer(t)
+	var doc struct {
+		Paths map[string]interface{} `json:"paths"`
+	}
+	request(t, "GET", server.URL+"/openapi.json", "", http.StatusOK, &doc)
+	for _, path := range []string{"/jobs", "/jobs/{id}", "/jobs/{id}/solutions", "/jobs/{id}/events", "/solvers"} {
+		if doc.Paths[path] == nil {
+			t.Errorf("%s is not described", path)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package scheduling
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// BraunName is the file name of an instance in the layout of the Braun benchmark suite,
+// e.g. u_c_hihi.0: u for range based (uniform) or g for CVB (gamma) instances, the
+// class and the number of the instance.
+func BraunName(c Class, method string, index int) string {
+	distribution := "u"
+	if method == CVB {
+		distribution = "g"
+	}
+	return fmt.Sprintf("%s_%s.%d", distribution, c, index)
+}
+
+// WriteBraun writes etc in the plain text layout of the Braun benchmark suite: one
+// runtime per line, the runtimes of the first task on every resource first, then those
+// of the second task and so on. The standard instances have 512 tasks and 16 resources.
+func WriteBraun(w io.Writer, etc [][]float64) error {
+	writer := bufio.NewWriter(w)
+	for _, row := range etc {
+		for _, runtime := range row {
+			writer.WriteString(strconv.FormatFloat(runtime, 'f', -1, 64))
+			writer.WriteByte('\n')
+		}
+	}
+	return writer.Flush()
+}
+
+// ReadBraun reads a matrix in the plain text layout of the Braun benchmark suite. The
+// files don't say how many resources there are, so it has to be given.
+func ReadBraun(r io.Reader, resources int) ([][]float64, error) {
+	if resources < 1 {
+		return nil, fmt.Errorf("the number of resources must be at least 1")
+	}
+	var runtimes []float64
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		runtime, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %q is not a runtime", line, text)
+		}
+		runtimes = append(runtimes, runtime)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(runtimes) == 0 || len(runtimes)%resources != 0 {
+		return nil, fmt.Errorf("%d runtimes can't be split into tasks on %d resources", len(runtimes), resources)
+	}
+	etc := make([][]float64, len(runtimes)/resources)
+	for i := range etc {
+		etc[i] = runtimes[i*resources : (i+1)*resources]
+	}
+	return etc, nil
+}
@@ -0,0 +1,47 @@
+package scheduling
+
+import (
+	"context"
+	"time"
+)
+
+// Budget limits the search of a scheduler, on top of its context. The zero Budget sets
+// no limit: the scheduler runs its whole schedule, e.g. every iteration of PSO. An
+// evaluation budget stops at the same point on every machine, so the same seed still
+// gives the same schedule.
+type Budget struct {
+	Time        time.Duration //wall-clock time, no limit if 0
+	Evaluations int           //makespans computed, no limit if 0
+}
+
+// stopper tells a search when it has to return the best schedule found so far: when its
+// context is done or its budget is used up.
+type stopper struct {
+	ctx         context.Context
+	deadline    time.Time
+	evaluations int
+	limit       int
+}
+
+func newStopper(ctx context.Context, budget Budget) *stopper {
+	s := &stopper{ctx: ctx, limit: budget.Evaluations}
+	if budget.Time > 0 {
+		s.deadline = time.Now().Add(budget.Time)
+	}
+	return s
+}
+
+// evaluated counts a makespan computed by the search.
+func (s *stopper) evaluated() {
+	s.evaluations++
+}
+
+func (s *stopper) stop() bool {
+	if s.limit > 0 && s.evaluations >= s.limit {
+		return true
+	}
+	if !s.deadline.IsZero() && !time.Now().Before(s.deadline) {
+		return true
+	}
+	return s.ctx.Err() != nil
+}
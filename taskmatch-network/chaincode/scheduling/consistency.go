@@ -0,0 +1,45 @@
+package scheduling
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Consistency classes of an ETC matrix, as in the benchmark of Braun et al. In a
+// consistent matrix a resource that is faster than another for one task is faster for
+// every task. A semi-consistent matrix is consistent on the even numbered resources
+// only, an inconsistent one has no such structure.
+const (
+	Consistent     = "consistent"
+	SemiConsistent = "semi-consistent"
+	Inconsistent   = "inconsistent"
+)
+
+// Consistencies lists the consistency classes.
+var Consistencies = []string{Consistent, SemiConsistent, Inconsistent}
+
+// MakeConsistent reorders the runtimes within every row of etc to give it the
+// consistency class. An inconsistent matrix is left as it is.
+func MakeConsistent(etc [][]float64, consistency string) error {
+	switch consistency {
+	case Consistent:
+		for _, row := range etc {
+			sort.Float64s(row)
+		}
+	case SemiConsistent:
+		for _, row := range etc {
+			even := make([]float64, 0, (len(row)+1)/2)
+			for j := 0; j < len(row); j += 2 {
+				even = append(even, row[j])
+			}
+			sort.Float64s(even)
+			for j := 0; j < len(row); j += 2 {
+				row[j] = even[j/2]
+			}
+		}
+	case Inconsistent:
+	default:
+		return fmt.Errorf("unknown consistency %s", consistency)
+	}
+	return nil
+}
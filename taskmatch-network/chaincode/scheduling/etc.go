@@ -0,0 +1,204 @@
+package scheduling
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+	"time"
+)
+
+/*** ETC matrices as in the benchmarks of Braun et al. and Ali et al.: a runtime is ***/
+/*** drawn for every task, then scaled per resource, and the consistency class     ***/
+/*** decides how the runtimes of a task are ordered over the resources.            ***/
+
+// Generation methods of an ETC matrix.
+const (
+	// RangeBased draws the task runtime from U[1, TaskRange) and multiplies it by a
+	// factor from U[1, ResourceRange) for every resource.
+	RangeBased = "range"
+	// CVB draws the task runtime from a gamma distribution with mean Mean and
+	// coefficient of variation TaskCV, then the runtime on every resource from a gamma
+	// distribution with the task runtime as mean and ResourceCV as coefficient of
+	// variation.
+	CVB = "cvb"
+)
+
+// Heterogeneity presets, "hi" and "lo" in the class names.
+var (
+	taskRanges     = map[string]float64{"hi": 3000, "lo": 100}
+	resourceRanges = map[string]float64{"hi": 1000, "lo": 10}
+	taskCVs        = map[string]float64{"hi": 0.6, "lo": 0.1}
+	resourceCVs    = map[string]float64{"hi": 0.6, "lo": 0.1}
+)
+
+// cvbMean is the mean task runtime of the CVB presets.
+const cvbMean = 1000
+
+// ETCParams describe how to generate an ETC matrix.
+type ETCParams struct {
+	Tasks       int    `json:"tasks"`
+	Resources   int    `json:"resources"`
+	Method      string `json:"method"`
+	Consistency string `json:"consistency"`
+
+	TaskRange     float64 `json:"taskRange,omitempty"`     //range based only
+	ResourceRange float64 `json:"resourceRange,omitempty"` //range based only
+
+	Mean       float64 `json:"mean,omitempty"`       //CVB only
+	TaskCV     float64 `json:"taskCV,omitempty"`     //CVB only
+	ResourceCV float64 `json:"resourceCV,omitempty"` //CVB only
+}
+
+// Check returns an error if the parameters can't generate a matrix.
+func (p ETCParams) Check() error {
+	if p.Tasks < 1 || p.Resources < 1 {
+		return fmt.Errorf("an ETC matrix needs at least one task and one resource")
+	}
+	switch p.Method {
+	case RangeBased:
+		if p.TaskRange < 1 || p.ResourceRange < 1 {
+			return fmt.Errorf("the task and resource ranges must be at least 1")
+		}
+	case CVB:
+		if p.Mean <= 0 || p.TaskCV <= 0 || p.ResourceCV <= 0 {
+			return fmt.Errorf("the mean and the coefficients of variation must be positive")
+		}
+	default:
+		return fmt.Errorf("unknown method %q, expected %s or %s", p.Method, RangeBased, CVB)
+	}
+	switch p.Consistency {
+	case Consistent, SemiConsistent, Inconsistent:
+	default:
+		return fmt.Errorf("unknown consistency %q", p.Consistency)
+	}
+	return nil
+}
+
+// Generate draws an ETC matrix from rng, the same seed gives the same matrix.
+func (p ETCParams) Generate(rng *rand.Rand) ([][]float64, error) {
+	if err := p.Check(); err != nil {
+		return nil, err
+	}
+
+	etc := make([][]float64, p.Tasks)
+	for i := range etc {
+		etc[i] = make([]float64, p.Resources)
+		if p.Method == RangeBased {
+			task := uniform(rng, 1, p.TaskRange)
+			for j := range etc[i] {
+				etc[i][j] = task * uniform(rng, 1, p.ResourceRange)
+			}
+		} else {
+			task := gamma(rng, 1/(p.TaskCV*p.TaskCV), p.Mean*p.TaskCV*p.TaskCV)
+			for j := range etc[i] {
+				etc[i][j] = gamma(rng, 1/(p.ResourceCV*p.ResourceCV), task*p.ResourceCV*p.ResourceCV)
+			}
+		}
+	}
+
+	return etc, MakeConsistent(etc, p.Consistency)
+}
+
+// uniform draws from U[lower, upper).
+func uniform(rng *rand.Rand, lower, upper float64) float64 {
+	return lower + rng.Float64()*(upper-lower)
+}
+
+// gamma draws from the gamma distribution with the given shape and scale, by the method
+// of Marsaglia and Tsang.
+func gamma(rng *rand.Rand, shape, scale float64) float64 {
+	if shape < 1 {
+		return gamma(rng, shape+1, scale) * math.Pow(rng.Float64(), 1/shape)
+	}
+	d := shape - 1.0/3
+	c := 1 / math.Sqrt(9*d)
+	for {
+		x := rng.NormFloat64()
+		v := 1 + c*x
+		if v <= 0 {
+			continue
+		}
+		v = v * v * v
+		u := rng.Float64()
+		if u < 1-0.0331*x*x*x*x || math.Log(u) < 0.5*x*x+d*(1-v+math.Log(v)) {
+			return d * v * scale
+		}
+	}
+}
+
+// Class is an instance class of the Braun benchmark, named like c_hihi: the consistency
+// (c, s or i) followed by the task and the resource heterogeneity (hi or lo).
+type Class struct {
+	Consistency           string
+	TaskHeterogeneity     string
+	ResourceHeterogeneity string
+}
+
+var consistencyLetters = map[string]string{"c": Consistent, "s": SemiConsistent, "i": Inconsistent}
+
+// Classes returns the twelve classes of the benchmark.
+func Classes() []Class {
+	var classes []Class
+	for _, c := range []string{"c", "s", "i"} {
+		for _, task := range []string{"hi", "lo"} {
+			for _, resource := range []string{"hi", "lo"} {
+				classes = append(classes, Class{consistencyLetters[c], task, resource})
+			}
+		}
+	}
+	return classes
+}
+
+// ParseClass parses a class name like c_hihi.
+func ParseClass(name string) (Class, error) {
+	parts := strings.Split(name, "_")
+	if len(parts) == 2 && len(parts[1]) == 4 {
+		c := Class{consistencyLetters[parts[0]], parts[1][:2], parts[1][2:]}
+		if c.Consistency != "" && taskRanges[c.TaskHeterogeneity] != 0 && resourceRanges[c.ResourceHeterogeneity] != 0 {
+			return c, nil
+		}
+	}
+	return Class{}, fmt.Errorf("unknown class %q, expected e.g. c_hihi or i_lolo", name)
+}
+
+func (c Class) String() string {
+	return c.Consistency[:1] + "_" + c.TaskHeterogeneity + c.ResourceHeterogeneity
+}
+
+// Params returns the parameters of an instance of the class with the preset ranges or
+// coefficients of variation of its heterogeneities.
+func (c Class) Params(method string, tasks, resources int) ETCParams {
+	p := ETCParams{Tasks: tasks, Resources: resources, Method: method, Consistency: c.Consistency}
+	if method == CVB {
+		p.Mean = cvbMean
+		p.TaskCV = taskCVs[c.TaskHeterogeneity]
+		p.ResourceCV = resourceCVs[c.ResourceHeterogeneity]
+	} else {
+		p.TaskRange = taskRanges[c.TaskHeterogeneity]
+		p.ResourceRange = resourceRanges[c.ResourceHeterogeneity]
+	}
+	return p
+}
+
+// ETCgenerator : generate an ETC matrix based on # tasks, resources, heterogenety of task and resource
+func ETCgenerator(task int, resource int, taskHetero string, resourceHetero string) [][]float64 {
+	return GenerateETC(rand.New(rand.NewSource(time.Now().UnixNano())), task, resource, taskHetero, resourceHetero)
+}
+
+// GenerateETC : ETCgenerator drawing from rng, the same seed gives the same matrix. The
+// matrix is range based and inconsistent, heterogeneities other than "hi" are low. It is
+// nil without tasks or resources.
+func GenerateETC(rng *rand.Rand, task int, resource int, taskHetero string, resourceHetero string) [][]float64 {
+	if taskHetero != "hi" {
+		taskHetero = "lo"
+	}
+	if resourceHetero != "hi" {
+		resourceHetero = "lo"
+	}
+	etc, err := Class{Inconsistent, taskHetero, resourceHetero}.Params(RangeBased, task, resource).Generate(rng)
+	if err != nil {
+		return nil
+	}
+	return etc
+}
@@ -0,0 +1,149 @@
+package scheduling
+
+import (
+	"bytes"
+	"math"
+	"math/rand"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestGenerateSeeded(t *testing.T) {
+	for _, method := range []string{RangeBased, CVB} {
+		params := Class{SemiConsistent, "hi", "lo"}.Params(method, 20, 5)
+		a, err := params.Generate(rand.New(rand.NewSource(3)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, _ := params.Generate(rand.New(rand.NewSource(3)))
+		c, _ := params.Generate(rand.New(rand.NewSource(4)))
+		if !reflect.DeepEqual(a, b) || reflect.DeepEqual(a, c) {
+			t.Errorf("%s: the matrix does not follow the seed", method)
+		}
+	}
+}
+
+func TestGenerateRanges(t *testing.T) {
+	params := ETCParams{Tasks: 200, Resources: 8, Method: RangeBased, Consistency: Inconsistent, TaskRange: 50, ResourceRange: 4}
+	etc, err := params.Generate(rand.New(rand.NewSource(1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, row := range etc {
+		for _, runtime := range row {
+			if runtime < 1 || runtime >= 50*4 {
+				t.Fatalf("runtime %v outside of [1, 200)", runtime)
+			}
+		}
+	}
+}
+
+func TestGenerateCVB(t *testing.T) {
+	//with many tasks the task runtimes have about the requested mean and variation
+	params := ETCParams{Tasks: 20000, Resources: 1, Method: CVB, Consistency: Inconsistent, Mean: 500, TaskCV: 0.5, ResourceCV: 0.01}
+	etc, err := params.Generate(rand.New(rand.NewSource(1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mean, square := 0.0, 0.0
+	for _, row := range etc {
+		mean += row[0]
+		square += row[0] * row[0]
+	}
+	mean /= float64(len(etc))
+	cv := math.Sqrt(square/float64(len(etc))-mean*mean) / mean
+	if math.Abs(mean-500) > 15 || math.Abs(cv-0.5) > 0.02 {
+		t.Errorf("mean %v and coefficient of variation %v, expected 500 and 0.5", mean, cv)
+	}
+
+	//a CV above 1 needs a gamma shape below 1
+	params = ETCParams{Tasks: 100, Resources: 4, Method: CVB, Consistency: Inconsistent, Mean: 10, TaskCV: 2, ResourceCV: 1.5}
+	etc, _ = params.Generate(rand.New(rand.NewSource(1)))
+	for _, row := range etc {
+		for _, runtime := range row {
+			if !(runtime >= 0) || math.IsInf(runtime, 0) {
+				t.Fatalf("runtime %v", runtime)
+			}
+		}
+	}
+}
+
+func TestGenerateConsistency(t *testing.T) {
+	for _, method := range []string{RangeBased, CVB} {
+		consistent, _ := Class{Consistent, "hi", "hi"}.Params(method, 30, 7).Generate(rand.New(rand.NewSource(1)))
+		semi, _ := Class{SemiConsistent, "hi", "hi"}.Params(method, 30, 7).Generate(rand.New(rand.NewSource(1)))
+		for i := range consistent {
+			if !sort.Float64sAreSorted(consistent[i]) {
+				t.Errorf("%s: consistent row %v", method, consistent[i])
+			}
+			for j := 2; j < len(semi[i]); j += 2 {
+				if semi[i][j] < semi[i][j-2] {
+					t.Errorf("%s: semi-consistent row %v", method, semi[i])
+				}
+			}
+		}
+	}
+}
+
+func TestETCParamsCheck(t *testing.T) {
+	valid := Class{Consistent, "lo", "lo"}.Params(RangeBased, 4, 2)
+	if err := valid.Check(); err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []ETCParams{
+		{Tasks: 0, Resources: 2, Method: RangeBased, Consistency: Consistent, TaskRange: 10, ResourceRange: 10},
+		{Tasks: 2, Resources: 2, Method: "normal", Consistency: Consistent},
+		{Tasks: 2, Resources: 2, Method: RangeBased, Consistency: Consistent, TaskRange: 0.5, ResourceRange: 10},
+		{Tasks: 2, Resources: 2, Method: CVB, Consistency: Consistent, Mean: 100, TaskCV: 0, ResourceCV: 0.1},
+		{Tasks: 2, Resources: 2, Method: CVB, Consistency: "mostly", Mean: 100, TaskCV: 0.1, ResourceCV: 0.1},
+	} {
+		if _, err := p.Generate(rand.New(rand.NewSource(1))); err == nil {
+			t.Errorf("generated a matrix with %+v", p)
+		}
+	}
+}
+
+func TestClasses(t *testing.T) {
+	classes := Classes()
+	if len(classes) != 12 {
+		t.Fatalf("%d classes", len(classes))
+	}
+	for _, c := range classes {
+		parsed, err := ParseClass(c.String())
+		if err != nil || parsed != c {
+			t.Errorf("%s parsed as %v, %v", c, parsed, err)
+		}
+	}
+	for _, name := range []string{"x_hihi", "c_hi", "c_hilx", "chihi", ""} {
+		if _, err := ParseClass(name); err == nil {
+			t.Errorf("parsed class %q", name)
+		}
+	}
+	if name := BraunName(Class{SemiConsistent, "lo", "hi"}, RangeBased, 0); name != "u_s_lohi.0" {
+		t.Errorf("file name %s", name)
+	}
+}
+
+func TestWriteBraun(t *testing.T) {
+	etc, _ := Class{Inconsistent, "hi", "hi"}.Params(RangeBased, 512, 16).Generate(rand.New(rand.NewSource(1)))
+	var buf bytes.Buffer
+	if err := WriteBraun(&buf, etc); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 512*16 {
+		t.Fatalf("%d lines, expected %d", len(lines), 512*16)
+	}
+	//task by task, the runtimes of a task on every resource follow each other
+	if lines[17] != strings.TrimSpace(formatRuntime(etc[1][1])) {
+		t.Errorf("line 17 is %s, expected the runtime of task 1 on resource 1, %v", lines[17], etc[1][1])
+	}
+}
+
+func formatRuntime(runtime float64) string {
+	var buf bytes.Buffer
+	WriteBraun(&buf, [][]float64{{runtime}})
+	return buf.String()
+}
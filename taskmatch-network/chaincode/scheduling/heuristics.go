@@ -0,0 +1,566 @@
+package scheduling
+
+import (
+	"context"
+	"math"
+	"math/rand"
+)
+
+/*** The heuristics the solvers of the taskmatching chaincode run on the integer ***/
+/*** runtimes of a job: min-min, max-min and simulated annealing.              ***/
+
+type indexValuePair struct {
+	index int
+	value int
+}
+
+func calcRuntime(mat [][]int, indices []int) int {
+	var runtimes = make([]int, len(mat[0]))
+
+	//add runtimes
+	for i := 0; i < len(mat); i++ {
+		runtimes[indices[i]] += mat[i][indices[i]]
+	}
+
+	//calculate max
+	var max int
+	max = -1
+
+	for i := 0; i < len(runtimes); i++ {
+		if runtimes[i] > max {
+			max = runtimes[i]
+		}
+	}
+
+	return max
+}
+
+// Newly added code
+// -----------------------------------------------------------------------------------------------------------------
+// -----------------------------------------------------------------------------------------------------------------
+// -----------------------------------------------------------------------------------------------------------------
+func minmin(inputMatrix [][]int) ([]int, int) {
+	//every round the task that can finish first, with the loads of the resources so
+	//far, goes to the resource it finishes on
+	choices := make([]int, len(inputMatrix))
+	done := make([]bool, len(inputMatrix))
+	loads := make([]int, len(inputMatrix[0]))
+	for n := 0; n < len(inputMatrix); n++ {
+		task, resource := -1, 0
+		for i, row := range inputMatrix {
+			if done[i] {
+				continue
+			}
+			for j := range row {
+				if task == -1 || loads[j]+row[j] < loads[resource]+inputMatrix[task][resource] {
+					task, resource = i, j
+				}
+			}
+		}
+		choices[task], done[task] = resource, true
+		loads[resource] += inputMatrix[task][resource]
+	}
+	return choices, calcRuntime(inputMatrix, choices)
+}
+
+func getminIndices(inputMatrix [][]int) []int {
+	result := make([]int, len(inputMatrix))
+	for i := 0; i < len(inputMatrix); i++ {
+		var minofRow int = inputMatrix[i][0]
+		for j := 1; j < len(inputMatrix[i]); j++ {
+			if inputMatrix[i][j] < minofRow {
+				minofRow = inputMatrix[i][j]
+				result[i] = j
+			}
+		}
+	}
+	return result
+}
+
+func shrinkMatrixRow(inputMatrix [][]int, rowRemoved int) [][]int {
+	result := make([][]int, len(inputMatrix)-1)
+	for c := range result {
+		result[c] = make([]int, len(inputMatrix[c]))
+	}
+	if len(inputMatrix) == 1 {
+		return inputMatrix
+	}
+
+	newRow := 0
+	for OriRow := 0; OriRow < len(inputMatrix); OriRow++ {
+		if OriRow != rowRemoved {
+			result[newRow] = inputMatrix[OriRow]
+			newRow++
+		}
+	}
+	return result
+}
+
+func minmax(inputMatrix [][]int) ([]indexValuePair, int) {
+	var emptyArr []indexValuePair
+	var emptyArr1 []int
+	tempMatrix := copyIntMatrix(inputMatrix)
+	choices, timespent := minmaxHelper(tempMatrix, emptyArr, emptyArr1, make([]int, len(inputMatrix[0]))) // choices contains the row and col number of the original matrix
+	timeCost := -1
+	for i := 0; i < len(timespent); i++ {
+		if timespent[i] > timeCost {
+			timeCost = timespent[i]
+		}
+	}
+	return choices, timeCost
+}
+
+func getMaxIndexValuePair(inputMatrix []indexValuePair) *indexValuePair {
+	var max int = -1
+	var maxPair *indexValuePair
+	for i := 0; i < len(inputMatrix); i++ {
+		if inputMatrix[i].value > max {
+			max = inputMatrix[i].value
+			maxPair = &inputMatrix[i]
+		}
+	}
+	return maxPair
+}
+
+// loads holds the completion time of every resource, which the rows of inputMatrix
+// already include
+func minmaxHelper(inputMatrix [][]int, result []indexValuePair, timespent []int, loads []int) ([]indexValuePair, []int) {
+	if len(inputMatrix) == 1 {
+		minIncides := getminIndices(inputMatrix)
+		result = append(result, indexValuePair{0, minIncides[0]})
+		timespent = append(timespent, inputMatrix[0][minIncides[0]])
+		return result, timespent
+	}
+	minIncides := getminIndices(inputMatrix)
+	var indeciesExtracted []indexValuePair
+	var minValues []indexValuePair
+	for i := 0; i < len(inputMatrix); i++ {
+		minValues = append(minValues, indexValuePair{index: i, value: inputMatrix[i][minIncides[i]]})
+		indeciesExtracted = append(indeciesExtracted, indexValuePair{index: i, value: minIncides[i]})
+
+	}
+	maxValuePair := getMaxIndexValuePair(minValues)         // row# and maxValue
+	indexExtracted := indeciesExtracted[maxValuePair.index] // row# and index of maxValue
+	// tempMatrix := copyMatrix(inputMatrix)
+	// for i := 0; i < len(tempMatrix) && i != maxValuePair.index; i++ {
+	// 	tempMatrix[i][indexExtracted.value] += maxValuePair.value
+	// }
+	tempMatrix := shrinkMatrixRow(inputMatrix, maxValuePair.index)
+	added := maxValuePair.value - loads[indexExtracted.value]
+	loads[indexExtracted.value] = maxValuePair.value
+	for i := 0; i < len(tempMatrix); i++ {
+		tempMatrix[i][indexExtracted.value] += added
+	}
+	result = append(result, indexExtracted)
+	row := indexExtracted.index
+	col := indexExtracted.value
+	timespent = append(timespent, inputMatrix[row][col])
+	return minmaxHelper(tempMatrix, result, timespent, loads)
+}
+
+// Newly added code
+// -----------------------------------------------------------------------------------------------------------------
+// -----------------------------------------------------------------------------------------------------------------
+// -----------------------------------------------------------------------------------------------------------------
+
+/*
+ * Min-Max calculates the minimum of every row and then takes the **MAXIMUM** of those minimums
+ * and adds it to our solution. This process is repeated until a solution is created.
+ */
+func minmax_rec(label_matrix [][][]int, min_indices []int, sol []int) []int {
+	if len(label_matrix) == 1 {
+		matrix := getMatrix(label_matrix)
+		var row_ind int = maxOfMins(matrix, min_indices)
+		var col_ind int = min_indices[row_ind]
+
+		var orig_row int = int(label_matrix[row_ind][col_ind][1])
+		var orig_col int = int(label_matrix[row_ind][col_ind][2])
+
+		sol[orig_row] = orig_col
+
+		return sol
+	} else {
+
+		//fmt.Println(len(label_matrix))
+		matrix := getMatrix(label_matrix)
+		var row_ind int = maxOfMins(matrix, min_indices)
+		var col_ind int = min_indices[row_ind]
+
+		var orig_row int = int(label_matrix[row_ind][col_ind][1])
+		var orig_col int = int(label_matrix[row_ind][col_ind][2])
+
+		sol[orig_row] = orig_col
+
+		label_matrix = decreaseSize(label_matrix, row_ind, col_ind)
+		matrix = getMatrix(label_matrix)
+
+		min_indices = fix_min_indices(matrix, min_indices, row_ind, col_ind)
+
+		return minmax_rec(label_matrix, min_indices, sol)
+	}
+}
+
+/*
+ * Decreases the size of a matrix by removing a row & column
+ */
+func decreaseSize(matrix [][][]int, row_ind int, col_ind int) [][][]int {
+	var new_mat [][][]int
+
+	new_mat = make([][][]int, len(matrix)-1)
+
+	for i := range new_mat {
+		new_mat[i] = make([][]int, len(matrix[0])-1)
+		for j := range new_mat[i] {
+			new_mat[i][j] = make([]int, 3)
+		}
+	}
+
+	var row_num int = 0
+	var col_num int = 0
+
+	for i := 0; i < len(matrix); i++ {
+		if i != row_ind {
+			col_num = 0
+
+			for j := 0; j < len(matrix[0]); j++ {
+				if j != col_ind {
+					new_mat[row_num][col_num][0] = matrix[i][j][0]
+					new_mat[row_num][col_num][1] = matrix[i][j][1]
+					new_mat[row_num][col_num][2] = matrix[i][j][2]
+					col_num = col_num + 1
+				}
+			}
+
+			row_num = row_num + 1
+		}
+	}
+
+	return new_mat
+}
+
+/*
+ * Min indices can get broken after the matrix size is decreased so this method fixes them
+ */
+func fix_min_indices(matrix [][]int, min_indices []int, rem_row int, rem_col int) []int {
+	var new_min_ind []int
+
+	new_min_ind = make([]int, len(min_indices)-1)
+	var count int = 0
+
+	for i := 0; i < len(min_indices); i++ {
+		if i != rem_row {
+			if min_indices[i] == rem_col {
+				new_min_ind[count] = min_index(matrix[count])
+			} else if min_indices[i] > rem_col {
+				new_min_ind[count] = min_indices[i] - 1
+			} else {
+				new_min_ind[count] = min_indices[i]
+			}
+			count = count + 1
+		}
+	}
+
+	return new_min_ind
+}
+
+/*
+ * Initialize the minimum indices for the matrix
+ */
+func init_mins(matrix [][]int) []int {
+	var min int
+	var ind int = -1
+	var sol []int
+
+	sol = make([]int, len(matrix))
+
+	for i := 0; i < len(matrix); i++ {
+		min = matrix[i][0]
+		ind = 0
+
+		for j := 1; j < len(matrix[0]); j++ {
+			if matrix[i][j] < min {
+				min = matrix[i][j]
+				ind = j
+			}
+		}
+
+		sol[i] = ind
+	}
+
+	return sol
+}
+
+/*
+ * Method to intialize a matrix with an additional dimension for the x and y values.
+ */
+func initMatrix(matrix [][]int) [][][]int {
+	var new_matrix [][][]int
+
+	new_matrix = make([][][]int, len(matrix))
+
+	for i := range new_matrix {
+		new_matrix[i] = make([][]int, len(matrix[0]))
+		for j := range new_matrix[i] {
+			new_matrix[i][j] = make([]int, 3)
+		}
+	}
+
+	for i := 0; i < len(matrix); i++ {
+		for j := 0; j < len(matrix[0]); j++ {
+			new_matrix[i][j][0] = matrix[i][j]
+			// new_matrix[i][j][1] = float64(i)
+			new_matrix[i][j][1] = (i)
+			// new_matrix[i][j][2] = float64(j)
+			new_matrix[i][j][2] = (j)
+		}
+	}
+
+	return new_matrix
+}
+
+/*
+ * Finds the minimum of the minimums
+ */
+// func minOfMins(matrix [][]int, min_ind []int) int {
+// 	// var min float64 = math.MaxFloat64
+// 	var min int = math.MaxInt8
+// 	var ind int = -1
+
+// 	for i := 0; i < len(matrix); i++ {
+// 		if matrix[i][min_ind[i]] < min {
+// 			min = matrix[i][min_ind[i]]
+// 			ind = i
+// 		}
+// 	}
+
+// 	return ind
+// }
+
+/*
+ * Finds the maximum of the minimums
+ */
+func maxOfMins(matrix [][]int, min_ind []int) int {
+	var max int = -1
+	var ind int = -1
+
+	for i := 0; i < len(matrix); i++ {
+		if matrix[i][min_ind[i]] > max {
+			max = matrix[i][min_ind[i]]
+			ind = i
+		}
+	}
+
+	return ind
+}
+
+/*
+ * Gets just the matrix values from the augmented matrix that also contains column and row data for each value.
+ */
+func getMatrix(matrix [][][]int) [][]int {
+
+	var new_matrix [][]int
+
+	new_matrix = make([][]int, len(matrix))
+
+	for i := range new_matrix {
+		new_matrix[i] = make([]int, len(matrix[0]))
+	}
+
+	for i := 0; i < len(matrix); i++ {
+		for j := 0; j < len(matrix[0]); j++ {
+			new_matrix[i][j] = matrix[i][j][0]
+		}
+	}
+
+	return new_matrix
+}
+
+/*
+ * Find the minimum index of a row
+ */
+func min_index(row []int) int {
+	if len(row) == 0 {
+		return -1
+	}
+
+	var min int = row[0]
+	var ind int = 0
+
+	for i := 1; i < len(row); i++ {
+		if row[i] < min {
+			min = row[i]
+			ind = i
+		}
+	}
+
+	return ind
+}
+
+func iToFMatrix(inputMatrix [][]int) [][]float64 {
+	newMatrix := make([][]float64, len(inputMatrix))
+	for i := range newMatrix {
+		newMatrix[i] = make([]float64, len(inputMatrix[i]))
+	}
+	for j := 0; j < len(inputMatrix); j++ {
+		for k := 0; k < len(inputMatrix[j]); k++ {
+			newMatrix[j][k] = float64(inputMatrix[j][k])
+		}
+	}
+	return newMatrix
+}
+
+/**************************************************
+ **          Simulated Annealing Code           **
+**************************************************/
+
+// SAOptions configure SA. The zero value of a field takes the default in brackets.
+type SAOptions struct {
+	Temperature float64 `json:"temperature,omitempty"` //temperature at the start [10000]
+	CoolingRate float64 `json:"coolingRate,omitempty"` //share of the temperature lost every step [0.003]
+}
+
+func (o SAOptions) withDefaults() SAOptions {
+	if o.Temperature <= 0 {
+		o.Temperature = 10000
+	}
+	if o.CoolingRate <= 0 || o.CoolingRate >= 1 {
+		o.CoolingRate = 0.003
+	}
+	return o
+}
+
+// SA runs the simulated annealing of the "sa" scheduler with other parameters.
+func SA(ctx context.Context, etc [][]float64, opts SAOptions, budget Budget, rng *rand.Rand) []int {
+	return annealing(RoundETC(etc), opts.withDefaults(), nil, rng, newStopper(ctx, budget), newTracer(ctx))
+}
+
+// simulatedAnnealing cools down from a temperature of 10000 until it drops below 1, or
+// stop says to return the best schedule so far. Every step is reported to trace.
+func simulatedAnnealing(matrix [][]int, rng *rand.Rand, stop *stopper, trace *tracer) []int {
+	return annealing(matrix, SAOptions{}.withDefaults(), nil, rng, stop, trace)
+}
+
+// annealing is simulatedAnnealing starting from opts.Temperature and cooling at
+// opts.CoolingRate, and from the schedule start unless it is nil.
+func annealing(matrix [][]int, opts SAOptions, start []int, rng *rand.Rand, stop *stopper, trace *tracer) []int {
+	var temp float64 = opts.Temperature
+	var coolingRate float64 = opts.CoolingRate
+	var currentEnergy float64
+	var newEnergy float64
+
+	//start from the tasks dealt out over the resources in turn
+	var best_sol []int = init_sol(len(matrix))
+	for i := range best_sol {
+		best_sol[i] = i % len(matrix[0])
+	}
+	if start != nil {
+		copy(best_sol, start)
+	}
+	var bestEnergy = float64(calcRuntime(matrix, best_sol))
+	stop.evaluated()
+
+	var curr_sol []int = copyIntArr(best_sol)
+	currentEnergy = bestEnergy
+
+	var new_sol []int
+
+	for i := 0; temp > 1 && !stop.stop(); i++ {
+		new_sol = copyIntArr(curr_sol)
+		new_sol = SA_swap(new_sol, rng)
+		newEnergy = float64(calcRuntime(matrix, new_sol))
+		stop.evaluated()
+
+		if acceptanceProbability(currentEnergy, newEnergy, temp) > rng.Float64() {
+			curr_sol = new_sol
+			currentEnergy = newEnergy
+		}
+
+		if newEnergy < bestEnergy {
+			bestEnergy = newEnergy
+			best_sol = new_sol
+		}
+
+		if trace.on() {
+			trace.observe(Sample{Iteration: i, Evaluations: stop.evaluations, Best: bestEnergy, Current: currentEnergy,
+				Temperature: temp, Diversity: hamming(curr_sol, best_sol)})
+		}
+		temp = temp * (1 - coolingRate)
+	}
+
+	return best_sol
+}
+
+func SA_swap(sol []int, rng *rand.Rand) []int {
+	if len(sol) < 2 {
+		return sol
+	}
+
+	var i_1 int = rng.Intn(len(sol))
+	var i_2 int = rng.Intn(len(sol))
+
+	for i := 0; i_1 == i_2; i++ {
+		i_2 = rng.Intn(len(sol))
+	}
+
+	tmpVal := sol[i_1]
+
+	sol[i_1] = sol[i_2]
+	sol[i_2] = tmpVal
+
+	return sol
+
+}
+
+func copyIntMatrix(matrix [][]int) [][]int {
+	var copyMat [][]int = make([][]int, len(matrix))
+
+	for i := 0; i < len(matrix); i++ {
+		copyMat[i] = copyIntArr(matrix[i])
+	}
+
+	return copyMat
+}
+
+/*
+ * minmax returns (row, resource) pairs where the row is an index into the matrix left at that
+ * step, after the rows picked before it were removed. This maps them back to the original tasks.
+ */
+func pairsToAssignment(pairs []indexValuePair, tasks int) []int {
+	var rows []int = init_sol(tasks)
+	var sol []int = make([]int, tasks)
+
+	for i := 0; i < len(pairs) && len(rows) > 0; i++ {
+		sol[rows[pairs[i].index]] = pairs[i].value
+		rows = append(rows[:pairs[i].index], rows[pairs[i].index+1:]...)
+	}
+
+	return sol
+}
+
+func copyIntArr(arr []int) []int {
+	var copyArr []int = make([]int, len(arr))
+
+	for i := 0; i < len(arr); i++ {
+		copyArr[i] = arr[i]
+	}
+
+	return copyArr
+}
+
+func init_sol(len int) []int {
+	var sol = make([]int, len)
+
+	for i := 0; i < len; i++ {
+		sol[i] = i
+	}
+
+	return sol
+}
+
+func acceptanceProbability(energy float64, newEnergy float64, temperature float64) float64 {
+	if newEnergy < energy {
+		return 1.0
+	}
+
+	return math.Exp((energy - newEnergy) / temperature)
+}
@@ -0,0 +1,251 @@
+package scheduling
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+	"testing/quick"
+)
+
+// etcMatrix is a random runtimes matrix for property tests, with runtimes large enough
+// to catch small sentinel values.
+type etcMatrix [][]int
+
+// Generate makes etcMatrix usable with testing/quick.
+func (etcMatrix) Generate(r *rand.Rand, size int) reflect.Value {
+	tasks := 1 + r.Intn(12)
+	resources := 1 + r.Intn(6)
+	matrix := make(etcMatrix, tasks)
+	for i := range matrix {
+		matrix[i] = make([]int, resources)
+		for j := range matrix[i] {
+			matrix[i][j] = r.Intn(5000)
+		}
+	}
+	return reflect.ValueOf(matrix)
+}
+
+// squareMatrix is an etcMatrix with no more tasks than resources, which minmax_rec
+// needs since it assigns every resource at most once.
+type squareMatrix [][]int
+
+// Generate makes squareMatrix usable with testing/quick.
+func (squareMatrix) Generate(r *rand.Rand, size int) reflect.Value {
+	resources := 1 + r.Intn(8)
+	tasks := 1 + r.Intn(resources)
+	matrix := make(squareMatrix, tasks)
+	for i := range matrix {
+		matrix[i] = make([]int, resources)
+		for j := range matrix[i] {
+			matrix[i][j] = r.Intn(5000)
+		}
+	}
+	return reflect.ValueOf(matrix)
+}
+
+var quickConfig = &quick.Config{MaxCount: 500, Rand: rand.New(rand.NewSource(1))}
+
+func TestCalcRuntime(t *testing.T) {
+	tests := []struct {
+		name    string
+		matrix  [][]int
+		sol     []int
+		runtime int
+	}{
+		{"single task", [][]int{{4, 2}}, []int{1}, 2},
+		{"all on one resource", [][]int{{1, 2, 3}, {4, 5, 6}, {7, 8, 9}}, []int{0, 0, 0}, 12},
+		{"one task each", [][]int{{1, 2, 3}, {4, 5, 6}, {7, 8, 9}}, []int{0, 1, 2}, 9},
+		{"idle resource", [][]int{{3, 1}, {3, 1}}, []int{0, 0}, 6},
+		{"zero runtimes", [][]int{{0, 0}, {0, 0}}, []int{0, 1}, 0},
+	}
+	for _, tt := range tests {
+		if got := calcRuntime(tt.matrix, tt.sol); got != tt.runtime {
+			t.Errorf("%s: calcRuntime = %d, expected %d", tt.name, got, tt.runtime)
+		}
+	}
+}
+
+func TestMinmin(t *testing.T) {
+	tests := []struct {
+		name    string
+		matrix  [][]int
+		sol     []int
+		runtime int
+	}{
+		{"single resource", [][]int{{3}, {4}}, []int{0, 0}, 7},
+		{"spreads load", [][]int{{1, 2, 3}, {4, 5, 6}, {7, 8, 9}}, []int{0, 0, 1}, 8},
+		//resource 0 is busy until 6 after two tasks, counting its load twice would send
+		//the last task to resource 1
+		{"counts load once", [][]int{{5, 9}, {1, 7}, {1, 9}}, []int{0, 0, 0}, 7},
+		{"large runtimes", [][]int{{40000, 50000}, {40000, 60000}}, []int{0, 1}, 60000},
+		//the shortest task goes first whatever its row, in input order task 0 would take
+		//resource 0 and the makespan would be 6
+		{"shortest task first", [][]int{{5, 5}, {1, 9}}, []int{1, 0}, 5},
+	}
+	for _, tt := range tests {
+		sol, runtime := minmin(tt.matrix)
+		if !reflect.DeepEqual(sol, tt.sol) || runtime != tt.runtime {
+			t.Errorf("%s: minmin = %v, %d, expected %v, %d", tt.name, sol, runtime, tt.sol, tt.runtime)
+		}
+	}
+}
+
+func TestMinmax(t *testing.T) {
+	tests := []struct {
+		name    string
+		matrix  [][]int
+		sol     []int
+		runtime int
+	}{
+		{"single task", [][]int{{3, 2}}, []int{1}, 2},
+		{"longest task first", [][]int{{1, 2, 3}, {4, 5, 6}, {7, 8, 9}}, []int{2, 1, 0}, 7},
+		{"single resource", [][]int{{3}, {4}, {1}}, []int{0, 0, 0}, 8},
+	}
+	for _, tt := range tests {
+		pairs, runtime := minmax(tt.matrix)
+		sol := pairsToAssignment(pairs, len(tt.matrix))
+		if !reflect.DeepEqual(sol, tt.sol) || runtime != tt.runtime {
+			t.Errorf("%s: minmax = %v, %d, expected %v, %d", tt.name, sol, runtime, tt.sol, tt.runtime)
+		}
+	}
+}
+
+func TestMinmaxRec(t *testing.T) {
+	matrix := [][]int{{1, 200, 300}, {400, 5, 600}, {700, 800, 9}}
+	sol := minmax_rec(initMatrix(matrix), init_mins(matrix), make([]int, len(matrix)))
+	if !reflect.DeepEqual(sol, []int{0, 1, 2}) {
+		t.Errorf("minmax_rec = %v, expected [0 1 2]", sol)
+	}
+
+	//the task with the largest minimum picks first and takes resource 0 away from task 0
+	matrix = [][]int{{10, 20}, {15, 40}}
+	sol = minmax_rec(initMatrix(matrix), init_mins(matrix), make([]int, len(matrix)))
+	if !reflect.DeepEqual(sol, []int{1, 0}) {
+		t.Errorf("minmax_rec = %v, expected [1 0]", sol)
+	}
+}
+
+func TestDecreaseSize(t *testing.T) {
+	matrix := initMatrix([][]int{{1, 2, 3}, {4, 5, 6}, {7, 8, 9}})
+	tests := []struct {
+		row, col int
+		values   [][]int
+	}{
+		{0, 0, [][]int{{5, 6}, {8, 9}}},
+		{1, 1, [][]int{{1, 3}, {7, 9}}},
+		{2, 0, [][]int{{2, 3}, {5, 6}}},
+	}
+	for _, tt := range tests {
+		smaller := decreaseSize(matrix, tt.row, tt.col)
+		if got := getMatrix(smaller); !reflect.DeepEqual(got, tt.values) {
+			t.Errorf("decreaseSize(%d, %d) = %v, expected %v", tt.row, tt.col, got, tt.values)
+		}
+		//every entry keeps the row and column it had in the original matrix
+		for _, row := range smaller {
+			for _, entry := range row {
+				if entry[1] == tt.row || entry[2] == tt.col || entry[0] != entry[1]*3+entry[2]+1 {
+					t.Errorf("decreaseSize(%d, %d) kept the wrong entry %v", tt.row, tt.col, entry)
+				}
+			}
+		}
+	}
+	if got := getMatrix(matrix); !reflect.DeepEqual(got, [][]int{{1, 2, 3}, {4, 5, 6}, {7, 8, 9}}) {
+		t.Errorf("decreaseSize changed its input to %v", got)
+	}
+}
+
+func TestFixMinIndices(t *testing.T) {
+	tests := []struct {
+		name     string
+		matrix   [][]int //the matrix after the row and column are removed
+		mins     []int
+		row, col int
+		expected []int
+	}{
+		{"shift left", [][]int{{5, 6}, {8, 9}}, []int{0, 1, 2}, 0, 0, []int{0, 1}},
+		{"removed minimum", [][]int{{9, 2}, {1, 8}}, []int{1, 1, 0}, 2, 1, []int{1, 0}},
+		{"left of removed column", [][]int{{1, 9}, {7, 2}}, []int{0, 2, 0}, 2, 1, []int{0, 1}},
+	}
+	for _, tt := range tests {
+		if got := fix_min_indices(tt.matrix, tt.mins, tt.row, tt.col); !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("%s: fix_min_indices = %v, expected %v", tt.name, got, tt.expected)
+		}
+	}
+}
+
+// checkSchedule reports whether sol assigns every task of matrix to a resource.
+func checkSchedule(matrix [][]int, sol []int) bool {
+	if len(sol) != len(matrix) {
+		return false
+	}
+	for _, resource := range sol {
+		if resource < 0 || resource >= len(matrix[0]) {
+			return false
+		}
+	}
+	return true
+}
+
+func TestMinminProperties(t *testing.T) {
+	property := func(matrix etcMatrix) bool {
+		original := copyIntMatrix(matrix)
+		sol, runtime := minmin(matrix)
+		return checkSchedule(matrix, sol) && runtime == calcRuntime(matrix, sol) &&
+			reflect.DeepEqual([][]int(matrix), original)
+	}
+	if err := quick.Check(property, quickConfig); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestMinmaxProperties(t *testing.T) {
+	property := func(matrix etcMatrix) bool {
+		original := copyIntMatrix(matrix)
+		pairs, runtime := minmax(matrix)
+		sol := pairsToAssignment(pairs, len(matrix))
+		return len(pairs) == len(matrix) && checkSchedule(matrix, sol) &&
+			runtime == calcRuntime(matrix, sol) && reflect.DeepEqual([][]int(matrix), original)
+	}
+	if err := quick.Check(property, quickConfig); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestMinmaxRecProperties(t *testing.T) {
+	property := func(matrix squareMatrix) bool {
+		original := copyIntMatrix(matrix)
+		sol := minmax_rec(initMatrix(matrix), init_mins(matrix), make([]int, len(matrix)))
+		used := map[int]bool{}
+		for _, resource := range sol {
+			if used[resource] {
+				return false
+			}
+			used[resource] = true
+		}
+		return checkSchedule(matrix, sol) && reflect.DeepEqual([][]int(matrix), original)
+	}
+	if err := quick.Check(property, quickConfig); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestFixMinIndicesProperties(t *testing.T) {
+	//after removing any row and column the fixed indices point at the row minimums
+	property := func(matrix squareMatrix, r, c uint8) bool {
+		if len(matrix) < 2 {
+			return true
+		}
+		row, col := int(r)%len(matrix), int(c)%len(matrix[0])
+		smaller := getMatrix(decreaseSize(initMatrix(matrix), row, col))
+		mins := fix_min_indices(smaller, init_mins(matrix), row, col)
+		for i, min := range mins {
+			if smaller[i][min] != smaller[i][min_index(smaller[i])] {
+				return false
+			}
+		}
+		return true
+	}
+	if err := quick.Check(property, quickConfig); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,211 @@
+package scheduling
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// The file formats of ETC instances.
+const (
+	FormatCSV   = "csv"   //a row of comma separated runtimes per task
+	FormatJSON  = "json"  //an Instance, the matrix with its metadata
+	FormatBraun = "braun" //one runtime per line, see WriteBraun
+)
+
+// Formats lists the file formats of ETC instances.
+var Formats = []string{FormatCSV, FormatJSON, FormatBraun}
+
+// Instance is an ETC matrix together with where it came from. It is the JSON format
+// of instance files, the metadata is left out of the other formats.
+type Instance struct {
+	Name      string      `json:"name,omitempty"`
+	Tasks     int         `json:"tasks"`
+	Resources int         `json:"resources"`
+	Generator *ETCParams  `json:"generator,omitempty"` //the parameters of generated instances
+	Seed      *int64      `json:"seed,omitempty"`
+	ETC       [][]float64 `json:"etc"`
+}
+
+// NewInstance wraps etc in an Instance named name.
+func NewInstance(name string, etc [][]float64) *Instance {
+	resources := 0
+	if len(etc) > 0 {
+		resources = len(etc[0])
+	}
+	return &Instance{name, len(etc), resources, nil, nil, etc}
+}
+
+// Check returns an error unless the instance has at least one task and one resource,
+// a runtime for every task on every resource and no negative runtimes.
+func (inst *Instance) Check() error {
+	if len(inst.ETC) == 0 || len(inst.ETC[0]) == 0 {
+		return fmt.Errorf("an instance needs at least one task and one resource")
+	}
+	if inst.Tasks != len(inst.ETC) || inst.Resources != len(inst.ETC[0]) {
+		return fmt.Errorf("the instance is said to have %d tasks on %d resources, its matrix has %d on %d",
+			inst.Tasks, inst.Resources, len(inst.ETC), len(inst.ETC[0]))
+	}
+	for i, row := range inst.ETC {
+		if len(row) != inst.Resources {
+			return fmt.Errorf("task %d has %d runtimes, expected %d", i, len(row), inst.Resources)
+		}
+		for j, runtime := range row {
+			if !(runtime >= 0) || math.IsInf(runtime, 1) {
+				return fmt.Errorf("the runtime of task %d on resource %d is %v", i, j, runtime)
+			}
+		}
+	}
+	return nil
+}
+
+// FormatOf guesses the format of a file from its extension: .csv and .json files are
+// CSV and JSON, everything else is taken to be in the Braun layout.
+func FormatOf(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return FormatCSV
+	case ".json":
+		return FormatJSON
+	}
+	return FormatBraun
+}
+
+// ReadInstance reads an instance in the given format. resources is only used for the
+// Braun format, whose files don't say how many resources there are.
+func ReadInstance(r io.Reader, format string, resources int) (*Instance, error) {
+	var inst *Instance
+	switch format {
+	case FormatCSV:
+		etc, err := readCSV(r)
+		if err != nil {
+			return nil, err
+		}
+		inst = NewInstance("", etc)
+	case FormatJSON:
+		inst = new(Instance)
+		if err := json.NewDecoder(r).Decode(inst); err != nil {
+			return nil, err
+		}
+	case FormatBraun:
+		etc, err := ReadBraun(r, resources)
+		if err != nil {
+			return nil, err
+		}
+		inst = NewInstance("", etc)
+	default:
+		return nil, fmt.Errorf("unknown format %q, expecting one of %s", format, strings.Join(Formats, ", "))
+	}
+	if err := inst.Check(); err != nil {
+		return nil, err
+	}
+	return inst, nil
+}
+
+// WriteInstance writes an instance in the given format.
+func WriteInstance(w io.Writer, format string, inst *Instance) error {
+	if err := inst.Check(); err != nil {
+		return err
+	}
+	switch format {
+	case FormatCSV:
+		return writeCSV(w, inst.ETC)
+	case FormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(inst)
+	case FormatBraun:
+		return WriteBraun(w, inst.ETC)
+	}
+	return fmt.Errorf("unknown format %q, expecting one of %s", format, strings.Join(Formats, ", "))
+}
+
+// ReadInstanceFile reads the instance in the file at path. An empty format is guessed
+// from the extension, see FormatOf. Instances read from CSV or Braun files are named
+// after the file.
+func ReadInstanceFile(path, format string, resources int) (*Instance, error) {
+	if format == "" {
+		format = FormatOf(path)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	inst, err := ReadInstance(f, format, resources)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", path, err)
+	}
+	if inst.Name == "" {
+		inst.Name = filepath.Base(path)
+	}
+	return inst, nil
+}
+
+// WriteInstanceFile writes an instance to the file at path. An empty format is guessed
+// from the extension, see FormatOf.
+func WriteInstanceFile(path, format string, inst *Instance) error {
+	if format == "" {
+		format = FormatOf(path)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := WriteInstance(f, format, inst); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// RoundETC rounds the runtimes to whole numbers, as the chaincode takes them.
+func RoundETC(etc [][]float64) [][]int {
+	matrix := make([][]int, len(etc))
+	for i := range etc {
+		matrix[i] = make([]int, len(etc[i]))
+		for j := range etc[i] {
+			matrix[i][j] = int(math.Round(etc[i][j]))
+		}
+	}
+	return matrix
+}
+
+func readCSV(r io.Reader) ([][]float64, error) {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	etc := make([][]float64, len(records))
+	for i, record := range records {
+		etc[i] = make([]float64, len(record))
+		for j, field := range record {
+			if etc[i][j], err = strconv.ParseFloat(field, 64); err != nil {
+				return nil, fmt.Errorf("row %d: %q is not a runtime", i+1, field)
+			}
+		}
+	}
+	return etc, nil
+}
+
+func writeCSV(w io.Writer, etc [][]float64) error {
+	writer := csv.NewWriter(w)
+	for _, row := range etc {
+		record := make([]string, len(row))
+		for j, runtime := range row {
+			record[j] = strconv.FormatFloat(runtime, 'f', -1, 64)
+		}
+		writer.Write(record)
+	}
+	writer.Flush()
+	return writer.Error()
+}
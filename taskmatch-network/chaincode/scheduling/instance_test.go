@@ -0,0 +1,122 @@
+package scheduling
+
+import (
+	"bytes"
+	"math/rand"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestInstanceRoundTrip(t *testing.T) {
+	params := Class{Inconsistent, "hi", "lo"}.Params(CVB, 20, 4)
+	etc, err := params.Generate(rand.New(rand.NewSource(1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	seed := int64(1)
+	inst := NewInstance("g_i_hilo.0", etc)
+	inst.Generator, inst.Seed = &params, &seed
+
+	dir := t.TempDir()
+	for _, format := range Formats {
+		var buf bytes.Buffer
+		if err := WriteInstance(&buf, format, inst); err != nil {
+			t.Fatal(err)
+		}
+		read, err := ReadInstance(&buf, format, 4)
+		if err != nil {
+			t.Fatalf("%s: %s", format, err)
+		}
+		if !reflect.DeepEqual(read.ETC, etc) {
+			t.Errorf("%s: the runtimes changed", format)
+		}
+
+		path := filepath.Join(dir, "instance."+format)
+		if err := WriteInstanceFile(path, "", inst); err != nil {
+			t.Fatal(err)
+		}
+		read, err = ReadInstanceFile(path, "", 4)
+		if err != nil {
+			t.Fatalf("%s: %s", path, err)
+		}
+		if format == FormatJSON && !reflect.DeepEqual(read, inst) {
+			t.Errorf("the metadata changed to %+v", read)
+		} else if format != FormatJSON && read.Name != "instance."+format {
+			t.Errorf("%s is named %s", path, read.Name)
+		}
+	}
+}
+
+func TestReadInstanceErrors(t *testing.T) {
+	for _, c := range []struct {
+		format, input string
+	}{
+		{FormatCSV, ""},
+		{FormatCSV, "1,2\n3\n"},
+		{FormatCSV, "1,x\n"},
+		{FormatCSV, "1,-2\n"},
+		{FormatJSON, `{"tasks":2,"resources":1,"etc":[[1]]}`},
+		{FormatJSON, `{"tasks":2,"resources":2,"etc":[[1,2],[3]]}`},
+		{FormatJSON, `[[1]]`},
+		{FormatBraun, "1\n2\n3\n"},
+		{FormatBraun, "1\nNaN\n"},
+		{"xml", "<etc/>"},
+	} {
+		if _, err := ReadInstance(strings.NewReader(c.input), c.format, 2); err == nil {
+			t.Errorf("%s %q was read", c.format, c.input)
+		}
+	}
+}
+
+func TestReadBraun(t *testing.T) {
+	etc, err := ReadBraun(strings.NewReader("1\n2.5\n\n3e2\n4\n5\n6\n"), 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(etc, [][]float64{{1, 2.5, 300}, {4, 5, 6}}) {
+		t.Errorf("read %v", etc)
+	}
+}
+
+func TestFormatOf(t *testing.T) {
+	for path, format := range map[string]string{"a.csv": FormatCSV, "b.JSON": FormatJSON, "u_c_hihi.0": FormatBraun} {
+		if got := FormatOf(path); got != format {
+			t.Errorf("%s is %s, expected %s", path, got, format)
+		}
+	}
+}
+
+func TestResultRoundTrip(t *testing.T) {
+	inst := NewInstance("small", [][]float64{{1, 2}, {3, 1.5}, {2, 2}})
+	res := NewResult(inst, "min-min", []int{0, 1, 0})
+	if res.Makespan != 3 {
+		t.Fatalf("makespan %v, expected 3", res.Makespan)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteResult(&buf, res); err != nil {
+		t.Fatal(err)
+	}
+	read, err := ReadResult(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(read, res) {
+		t.Fatalf("read %+v, wrote %+v", read, res)
+	}
+	if err := read.Check(inst.ETC); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, bad := range []Result{
+		{Assignment: []int{0, 1}, Makespan: 1.5},
+		{Assignment: []int{0, 2, 0}, Makespan: 3},
+		{Assignment: []int{0, 1, 0}, Makespan: 2.5},
+	} {
+		if err := bad.Check(inst.ETC); err == nil {
+			t.Errorf("%+v passed the check", bad)
+		}
+	}
+}
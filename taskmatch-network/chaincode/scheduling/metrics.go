@@ -0,0 +1,53 @@
+package scheduling
+
+import (
+	"math"
+	"sort"
+)
+
+// ETCMakespan is the time the busiest resource needs to run the tasks assigned to it.
+func ETCMakespan(etc [][]float64, sol []int) float64 {
+	loads := make([]float64, len(etc[0]))
+	for i, resource := range sol {
+		loads[resource] += etc[i][resource]
+	}
+	makespan := 0.0
+	for _, load := range loads {
+		makespan = math.Max(makespan, load)
+	}
+	return makespan
+}
+
+// Flowtime is the sum of the completion times of the tasks, each resource running its
+// tasks shortest first, which is the order that makes the sum smallest.
+func Flowtime(etc [][]float64, sol []int) float64 {
+	runtimes := make([][]float64, len(etc[0]))
+	for i, resource := range sol {
+		runtimes[resource] = append(runtimes[resource], etc[i][resource])
+	}
+	flowtime := 0.0
+	for _, queue := range runtimes {
+		sort.Float64s(queue)
+		finished := 0.0
+		for _, runtime := range queue {
+			finished += runtime
+			flowtime += finished
+		}
+	}
+	return flowtime
+}
+
+// LowerBound is a makespan no schedule can beat: every task needs at least its fastest
+// runtime, and the resources can at best share the sum of those evenly.
+func LowerBound(etc [][]float64) float64 {
+	longest, total := 0.0, 0.0
+	for _, row := range etc {
+		fastest := math.Inf(1)
+		for _, runtime := range row {
+			fastest = math.Min(fastest, runtime)
+		}
+		longest = math.Max(longest, fastest)
+		total += fastest
+	}
+	return math.Max(longest, total/float64(len(etc[0])))
+}
@@ -0,0 +1,73 @@
+package scheduling
+
+/*** Online mapping: tasks that arrive while resources are already busy until their ***/
+/*** ready times, mapped one at a time or in batches.                              ***/
+
+// MCT returns the resource on which a task with the given runtimes completes first,
+// minimum completion time, given the times the resources are ready. Ties go to the
+// lower resource.
+func MCT(runtimes []int, ready []int) int {
+	best := 0
+	for j := range ready {
+		if ready[j]+runtimes[j] < ready[best]+runtimes[best] {
+			best = j
+		}
+	}
+	return best
+}
+
+// OLB returns the resource that is ready first, opportunistic load balancing, whatever
+// the task would take on it.
+func OLB(ready []int) int {
+	best := 0
+	for j := range ready {
+		if ready[j] < ready[best] {
+			best = j
+		}
+	}
+	return best
+}
+
+// MinMinFrom is min-min on resources that are busy until their ready times: it
+// repeatedly maps the task with the earliest completion time to the resource it
+// completes on. ready is moved on by the tasks it maps.
+func MinMinFrom(matrix [][]int, ready []int) []int {
+	sol := make([]int, len(matrix))
+	done := make([]bool, len(matrix))
+	for n := 0; n < len(matrix); n++ {
+		task, resource := -1, 0
+		for i, row := range matrix {
+			if done[i] {
+				continue
+			}
+			j := MCT(row, ready)
+			if task == -1 || ready[j]+row[j] < ready[resource]+matrix[task][resource] {
+				task, resource = i, j
+			}
+		}
+		sol[task], done[task] = resource, true
+		ready[resource] += matrix[task][resource]
+	}
+	return sol
+}
+
+// SufferageFrom is Sufferage on resources that are busy until their ready times, which
+// it moves on by the tasks it maps.
+func SufferageFrom(matrix [][]int, ready []int) []int {
+	etc := make([][]float64, len(matrix))
+	for i, row := range matrix {
+		etc[i] = make([]float64, len(row))
+		for j, runtime := range row {
+			etc[i][j] = float64(runtime)
+		}
+	}
+	readyETC := make([]float64, len(ready))
+	for j, r := range ready {
+		readyETC[j] = float64(r)
+	}
+	sol := sufferage(etc, readyETC)
+	for i, j := range sol {
+		ready[j] += matrix[i][j]
+	}
+	return sol
+}
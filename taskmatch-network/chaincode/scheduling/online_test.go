@@ -0,0 +1,30 @@
+package scheduling
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestOnline(t *testing.T) {
+	ready := []int{5, 0, 2}
+	if j := MCT([]int{2, 9, 3}, ready); j != 2 {
+		t.Errorf("mct picked resource %d, expected 2", j)
+	}
+	if j := MCT([]int{1, 6, 4}, ready); j != 0 {
+		t.Errorf("mct picked resource %d on a tie, expected 0", j)
+	}
+	if j := OLB(ready); j != 1 {
+		t.Errorf("olb picked resource %d, expected 1", j)
+	}
+
+	//task 1 completes first on resource 1, then task 0 on resource 0 before task 2
+	matrix := [][]int{{2, 6}, {4, 1}, {5, 3}}
+	ready = []int{1, 0}
+	if sol := MinMinFrom(matrix, ready); !reflect.DeepEqual(sol, []int{0, 1, 1}) || !reflect.DeepEqual(ready, []int{3, 4}) {
+		t.Errorf("min-min from ready times %v, ready %v", sol, ready)
+	}
+	ready = []int{10, 0}
+	if sol := SufferageFrom([][]int{{1, 5}, {2, 3}, {4, 4}}, ready); !reflect.DeepEqual(sol, []int{0, 1, 1}) || !reflect.DeepEqual(ready, []int{11, 7}) {
+		t.Errorf("sufferage from ready times %v, ready %v", sol, ready)
+	}
+}
@@ -0,0 +1,448 @@
+package scheduling
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"runtime"
+	"sort"
+	"sync"
+)
+
+/*** A concurrent PSO: particles moved and evaluated by a pool of workers, optionally ***/
+/*** split into islands that run on their own goroutines and trade their best ones.  ***/
+
+// PSOOptions configure PSO. The zero value of a field takes the default in brackets.
+type PSOOptions struct {
+	Iterations int     `json:"iterations,omitempty"` //iterations of every island [500]
+	Population int     `json:"population,omitempty"` //particles of every island [50]
+	C1         float64 `json:"c1,omitempty"`         //pull towards the particle's best position [1.796180]
+	C2         float64 `json:"c2,omitempty"`         //pull towards the swarm's best position [1.796180]
+	Inertia    float64 `json:"inertia,omitempty"`    //weight of the velocity at the start [0.729844]
+	Damping    float64 `json:"damping,omitempty"`    //factor the inertia is multiplied with after every iteration [0.995]
+
+	Topology        string  `json:"topology,omitempty"`        //which particles a particle learns from, see TopologyGlobal [global]
+	Neighbors       int     `json:"neighbors,omitempty"`       //particles every particle informs with the random topology [3]
+	InertiaSchedule string  `json:"inertiaSchedule,omitempty"` //how the inertia changes, see InertiaDamped [damped]
+	InertiaEnd      float64 `json:"inertiaEnd,omitempty"`      //inertia at the end of a linear schedule, the lowest of an adaptive one [0.4]
+	//scale the velocity by Clerc's constriction factor worked out from C1 and C2 instead
+	//of an inertia weight, C1 and C2 default to 2.05 then
+	Constriction  bool    `json:"constriction,omitempty"`
+	VelocityClamp float64 `json:"velocityClamp,omitempty"` //largest speed per dimension as a share of the resources, none if 0
+	Boundary      string  `json:"boundary,omitempty"`      //what happens to a particle that leaves the range, see BoundaryClamp [clamp]
+
+	Workers   int `json:"-"`                   //goroutines moving and evaluating particles [the number of CPUs]
+	Islands   int `json:"islands,omitempty"`   //swarms searching side by side, each on its own goroutine [1]
+	Migration int `json:"migration,omitempty"` //iterations between migrations of the islands [10]
+	Migrants  int `json:"migrants,omitempty"`  //particles every island sends to the next one when they migrate [1]
+}
+
+func (o PSOOptions) withDefaults() PSOOptions {
+	if o.Iterations <= 0 {
+		o.Iterations = psoIterations
+	}
+	if o.Population <= 0 {
+		o.Population = psoPopulation
+	}
+	if o.Constriction && o.C1 == 0 && o.C2 == 0 {
+		o.C1, o.C2 = 2.05, 2.05
+	}
+	if o.C1 == 0 {
+		o.C1 = psoC1
+	}
+	if o.C2 == 0 {
+		o.C2 = psoC2
+	}
+	if o.InertiaSchedule == "" {
+		o.InertiaSchedule = InertiaDamped
+	}
+	if o.Inertia == 0 {
+		o.Inertia = psoInertia
+		if o.InertiaSchedule != InertiaDamped {
+			o.Inertia = 0.9
+		}
+	}
+	if o.InertiaEnd == 0 {
+		o.InertiaEnd = 0.4
+	}
+	if o.Damping == 0 {
+		o.Damping = psoDamping
+	}
+	if o.Topology == "" {
+		o.Topology = TopologyGlobal
+	}
+	if o.Neighbors <= 0 {
+		o.Neighbors = 3
+	}
+	if o.Boundary == "" {
+		o.Boundary = BoundaryClamp
+	}
+	if o.Workers <= 0 {
+		o.Workers = runtime.NumCPU()
+	}
+	if o.Islands <= 0 {
+		o.Islands = 1
+	}
+	if o.Migration <= 0 {
+		o.Migration = 10
+	}
+	if o.Migrants <= 0 {
+		o.Migrants = 1
+	}
+	if o.Migrants > o.Population {
+		o.Migrants = o.Population
+	}
+	return o
+}
+
+// PSO runs a particle swarm over the ETC matrix and returns the schedule of the best
+// position found. Unlike the swarm of the registered "pso" scheduler, whose particles
+// move one after another, every particle of an iteration moves relative to the best
+// position of its island at the start of the iteration, so the particles can be moved
+// and evaluated concurrently by opts.Workers goroutines. Every particle draws from its
+// own random source, seeded from rng, which makes the schedule depend on the seed only
+// and not on the number of workers.
+//
+// With several islands every island is a swarm of its own, run on its own goroutine.
+// Every opts.Migration iterations the islands stop, and each sends copies of its best
+// particles to the next one around a ring, where they replace the worst ones.
+//
+// Within an island a particle follows the best position of its neighbours in the
+// opts.Topology, the whole island by default. The options also pick the inertia schedule,
+// a constriction factor, a speed limit and what happens at the bounds of the search
+// space; PSO panics if opts.Check fails.
+//
+// The search stops early when ctx is done or budget is used up. An evaluation budget is
+// shared evenly by the islands. Every iteration of every island is reported to the
+// observer of ctx, see WithObserver.
+func PSO(ctx context.Context, etc [][]float64, opts PSOOptions, budget Budget, rng *rand.Rand) []int {
+	return seededPSO(ctx, etc, opts, nil, budget, rng)
+}
+
+// seededPSO is PSO with the first particle of every island starting from the schedule
+// seed, unless it is nil.
+func seededPSO(ctx context.Context, etc [][]float64, opts PSOOptions, seed []int, budget Budget, rng *rand.Rand) []int {
+	if err := opts.Check(); err != nil {
+		panic("scheduling: " + err.Error())
+	}
+	opts = opts.withDefaults()
+	tasks, resources := len(etc), len(etc[0])
+
+	pool := newWorkerPool(opts.Workers)
+	defer pool.close()
+
+	stop := newStopper(ctx, budget)
+	trace := newTracer(ctx)
+	islands := make([]*island, opts.Islands)
+	for n := range islands {
+		share := 0
+		if budget.Evaluations > 0 {
+			share = budget.Evaluations / opts.Islands
+			if n < budget.Evaluations%opts.Islands {
+				share++
+			}
+			if share == 0 {
+				share = 1 //every island needs a best position
+			}
+		}
+		islands[n] = newIsland(etc, opts, seed, rng, &stopper{ctx: ctx, deadline: stop.deadline, limit: share})
+		islands[n].index, islands[n].trace = n, trace
+	}
+
+	//the first positions are evaluated by the pool as well
+	for _, is := range islands {
+		is.evaluate(pool, etc, len(is.particles))
+	}
+
+	for done := 0; done < opts.Iterations; done += opts.Migration {
+		epoch := opts.Migration
+		if left := opts.Iterations - done; left < epoch {
+			epoch = left
+		}
+		var wg sync.WaitGroup
+		for _, is := range islands {
+			wg.Add(1)
+			go func(is *island) {
+				defer wg.Done()
+				is.run(pool, etc, epoch, opts, resources)
+			}(is)
+		}
+		wg.Wait()
+
+		stopped := false
+		for _, is := range islands {
+			stopped = stopped || is.stopped
+		}
+		if stopped {
+			break
+		}
+		if len(islands) > 1 && done+epoch < opts.Iterations {
+			migrate(islands, opts.Migrants)
+		}
+	}
+
+	best := islands[0]
+	for _, is := range islands[1:] {
+		if is.bestCost < best.bestCost {
+			best = is
+		}
+	}
+	sol := make([]int, tasks)
+	for i, x := range best.best {
+		sol[i] = int(x)
+	}
+	return sol
+}
+
+// swarmParticle is a particle of the concurrent PSO with its own random source.
+type swarmParticle struct {
+	position, velocity []float64
+	cost               float64
+	best               []float64 //personal best position
+	bestCost           float64
+	rng                *rand.Rand
+	loads              []float64 //scratch space of the evaluation
+}
+
+// move updates the velocity and position of the particle towards its own best and the
+// best position of its neighbours, keeping the position in [0, resources).
+func (p *swarmParticle) move(social []float64, m *motion) {
+	for d := range p.position {
+		r1, r2 := p.rng.Float64(), p.rng.Float64()
+		pull := m.c1*r1*(p.best[d]-p.position[d]) + m.c2*r2*(social[d]-p.position[d])
+		v := m.inertia*p.velocity[d] + pull
+		if m.chi > 0 {
+			v = m.chi * (p.velocity[d] + pull)
+		}
+		if m.vmax > 0 {
+			v = math.Min(math.Max(v, -m.vmax), m.vmax)
+		}
+		p.position[d], p.velocity[d] = m.bound(p.position[d]+v, v, p.rng)
+	}
+}
+
+func (p *swarmParticle) evaluate(etc [][]float64) {
+	for j := range p.loads {
+		p.loads[j] = 0
+	}
+	p.cost = 0
+	for i, x := range p.position {
+		j := int(x)
+		p.loads[j] += etc[i][j]
+		if p.loads[j] > p.cost {
+			p.cost = p.loads[j]
+		}
+	}
+}
+
+// island is a swarm of the concurrent PSO.
+type island struct {
+	index     int
+	particles []*swarmParticle
+	best      []float64
+	bestCost  float64
+	inertia   float64
+	iteration int //iterations done
+	stop      *stopper
+	stopped   bool
+	trace     *tracer
+	neighbors [][]int    //the neighbourhood of every particle, nil for the global topology
+	links     *rand.Rand //draws the neighbourhoods of the random topology
+}
+
+func newIsland(etc [][]float64, opts PSOOptions, seed []int, rng *rand.Rand, stop *stopper) *island {
+	tasks, resources := len(etc), len(etc[0])
+	is := &island{bestCost: math.Inf(1), inertia: opts.Inertia, stop: stop}
+	for i := 0; i < opts.Population; i++ {
+		p := &swarmParticle{rng: rand.New(rand.NewSource(rng.Int63())), loads: make([]float64, resources)}
+		p.position = generateRandomArr(p.rng, 0, float64(resources), tasks)
+		if i == 0 && seed != nil {
+			//in the middle of the cell of the seed's resource, away from the edges
+			for d, j := range seed {
+				p.position[d] = float64(j) + 0.5
+			}
+		}
+		p.velocity = generateRandomArr(p.rng, float64(-resources), float64(resources), tasks)
+		if vmax := opts.VelocityClamp * float64(resources); vmax > 0 {
+			for d, v := range p.velocity {
+				p.velocity[d] = math.Min(math.Max(v, -vmax), vmax)
+			}
+		}
+		p.best = make([]float64, tasks)
+		p.bestCost = math.Inf(1)
+		is.particles = append(is.particles, p)
+	}
+	if opts.Topology == TopologyRandom {
+		is.links = rand.New(rand.NewSource(rng.Int63()))
+	}
+	is.neighbors = neighborhoods(opts.Topology, opts.Population, opts.Neighbors, is.links)
+	return is
+}
+
+// run moves the particles of the island for the given number of iterations, or until
+// its stopper says to stop.
+func (is *island) run(pool *workerPool, etc [][]float64, iterations int, opts PSOOptions, resources int) {
+	for iter := 0; iter < iterations; iter++ {
+		if is.stop.stop() {
+			is.stopped = true
+			return
+		}
+		//every particle follows the best position its neighbours had at the start of the
+		//iteration, whatever order they are moved in
+		n := len(is.particles)
+		if is.stop.limit > 0 && is.stop.limit-is.stop.evaluations < n {
+			n = is.stop.limit - is.stop.evaluations
+		}
+		best := append([]float64(nil), is.best...)
+		socials := make([][]float64, n)
+		for i := range socials {
+			socials[i] = is.social(i, best)
+		}
+		m := &motion{inertia: is.inertia, c1: opts.C1, c2: opts.C2, vmax: opts.VelocityClamp * float64(resources),
+			boundary: opts.Boundary, resources: resources}
+		if opts.Constriction {
+			m.inertia, m.chi = 0, constriction(opts.C1, opts.C2)
+		}
+		pool.run(n, func(i int) {
+			p := is.particles[i]
+			p.move(socials[i], m)
+			p.evaluate(etc)
+		})
+		improved, better := is.update(n)
+		if is.trace.on() {
+			costs := make([]float64, len(is.particles))
+			positions := make([][]float64, len(is.particles))
+			for i, p := range is.particles {
+				costs[i], positions[i] = p.cost, p.position
+			}
+			inertia := m.inertia
+			if opts.Constriction {
+				inertia = m.chi
+			}
+			is.trace.observe(swarmSample(is.iteration, is.index, is.stop.evaluations, is.bestCost, inertia, costs, positions))
+		}
+		if is.links != nil && !better {
+			is.neighbors = neighborhoods(opts.Topology, len(is.particles), opts.Neighbors, is.links)
+		}
+		is.inertia = nextInertia(&opts, is.inertia, is.iteration, improved, n)
+		is.iteration++
+	}
+}
+
+// social is the position particle i is pulled towards besides its own best: best, the
+// best position of the island, or the best personal best of its neighbours.
+func (is *island) social(i int, best []float64) []float64 {
+	if is.neighbors == nil {
+		return best
+	}
+	informant := is.particles[i]
+	for _, j := range is.neighbors[i] {
+		if is.particles[j].bestCost < informant.bestCost {
+			informant = is.particles[j]
+		}
+	}
+	return informant.best
+}
+
+// evaluate computes the cost of the first n particles in their initial positions.
+func (is *island) evaluate(pool *workerPool, etc [][]float64, n int) {
+	if is.stop.limit > 0 && is.stop.limit < n {
+		n = is.stop.limit
+	}
+	pool.run(n, func(i int) {
+		is.particles[i].evaluate(etc)
+	})
+	is.update(n)
+}
+
+// update keeps the personal bests of the first n particles and the island's best, in
+// the order of the particles. It returns how many of them improved on their personal
+// best and whether the island's best improved.
+func (is *island) update(n int) (improved int, better bool) {
+	for _, p := range is.particles[:n] {
+		is.stop.evaluated()
+		if p.cost < p.bestCost {
+			improved++
+			p.bestCost = p.cost
+			copy(p.best, p.position)
+			if p.cost < is.bestCost {
+				better = true
+				is.bestCost = p.cost
+				is.best = append(is.best[:0], p.position...)
+			}
+		}
+	}
+	return improved, better
+}
+
+// migrate sends copies of the best particles of every island to the next one, where
+// they take the place of its worst particles.
+func migrate(islands []*island, migrants int) {
+	type migrant struct {
+		position []float64
+		cost     float64
+	}
+	outgoing := make([][]migrant, len(islands))
+	for n, is := range islands {
+		for _, p := range is.ranked()[:migrants] {
+			outgoing[n] = append(outgoing[n], migrant{append([]float64(nil), p.best...), p.bestCost})
+		}
+	}
+	for n, is := range islands {
+		ranked := is.ranked()
+		for k, m := range outgoing[(n+len(islands)-1)%len(islands)] {
+			p := ranked[len(ranked)-1-k]
+			copy(p.position, m.position)
+			copy(p.best, m.position)
+			p.cost, p.bestCost = m.cost, m.cost
+			if m.cost < is.bestCost {
+				is.bestCost = m.cost
+				is.best = append(is.best[:0], m.position...)
+			}
+		}
+	}
+}
+
+// ranked returns the particles of the island from the best personal best to the worst.
+func (is *island) ranked() []*swarmParticle {
+	ranked := append([]*swarmParticle(nil), is.particles...)
+	sort.SliceStable(ranked, func(a, b int) bool {
+		return ranked[a].bestCost < ranked[b].bestCost
+	})
+	return ranked
+}
+
+// workerPool runs the work of the swarms on a fixed number of goroutines.
+type workerPool struct {
+	work chan func()
+}
+
+func newWorkerPool(workers int) *workerPool {
+	pool := &workerPool{make(chan func())}
+	for w := 0; w < workers; w++ {
+		go func() {
+			for f := range pool.work {
+				f()
+			}
+		}()
+	}
+	return pool
+}
+
+// run calls f for 0 to n-1 on the workers and returns when all calls have returned.
+func (pool *workerPool) run(n int, f func(i int)) {
+	var wg sync.WaitGroup
+	wg.Add(n)
+	for i := 0; i < n; i++ {
+		i := i
+		pool.work <- func() {
+			defer wg.Done()
+			f(i)
+		}
+	}
+	wg.Wait()
+}
+
+func (pool *workerPool) close() {
+	close(pool.work)
+}
@@ -0,0 +1,131 @@
+package scheduling
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestPSODeterministicPerSeed(t *testing.T) {
+	etc := GenerateETC(rand.New(rand.NewSource(1)), 120, 8, "hi", "lo")
+	for _, islands := range []int{1, 3} {
+		for _, budget := range []Budget{{}, {Evaluations: 1000}} {
+			opts := PSOOptions{Iterations: 40, Population: 12, Islands: islands, Migration: 5, Migrants: 2}
+			var first []int
+			for _, workers := range []int{1, 2, 7} {
+				opts.Workers = workers
+				sol := PSO(context.Background(), etc, opts, budget, rand.New(rand.NewSource(3)))
+				if !checkETCSchedule(etc, sol) {
+					t.Fatalf("%d islands, %d workers: invalid schedule %v", islands, workers, sol)
+				}
+				if first == nil {
+					first = sol
+				} else if !reflect.DeepEqual(sol, first) {
+					t.Errorf("%d islands, budget %+v: %d workers give another schedule than 1", islands, budget, workers)
+				}
+			}
+		}
+	}
+}
+
+func TestPSOSearches(t *testing.T) {
+	etc := GenerateETC(rand.New(rand.NewSource(2)), 100, 6, "hi", "hi")
+	rng := rand.New(rand.NewSource(1))
+	random := make([]int, len(etc))
+	for i := range random {
+		random[i] = rng.Intn(len(etc[0]))
+	}
+	for _, opts := range []PSOOptions{{Iterations: 100}, {Iterations: 100, Population: 10, Islands: 4}} {
+		sol := PSO(context.Background(), etc, opts, Budget{}, rand.New(rand.NewSource(1)))
+		if ETCMakespan(etc, sol) >= ETCMakespan(etc, random) {
+			t.Errorf("%+v found %v, a random schedule %v", opts, ETCMakespan(etc, sol), ETCMakespan(etc, random))
+		}
+	}
+
+	//cancelled before it starts it still returns the best first position
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if sol := PSO(ctx, etc, PSOOptions{Islands: 2}, Budget{}, rand.New(rand.NewSource(1))); !checkETCSchedule(etc, sol) {
+		t.Errorf("cancelled search returned %v", sol)
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	newTestIsland := func(costs ...float64) *island {
+		is := &island{bestCost: math.Inf(1)}
+		for _, cost := range costs {
+			p := &swarmParticle{position: []float64{cost}, best: []float64{cost}, cost: cost, bestCost: cost}
+			is.particles = append(is.particles, p)
+			if cost < is.bestCost {
+				is.bestCost, is.best = cost, []float64{cost}
+			}
+		}
+		return is
+	}
+	islands := []*island{newTestIsland(5, 1, 9), newTestIsland(4, 8, 6), newTestIsland(7, 3, 2)}
+	migrate(islands, 1)
+
+	//the best of every island replaces the worst of the next one around the ring
+	expected := [][]float64{{5, 1, 2}, {4, 1, 6}, {4, 3, 2}}
+	for n, is := range islands {
+		var costs []float64
+		for _, p := range is.particles {
+			costs = append(costs, p.bestCost)
+		}
+		if !reflect.DeepEqual(costs, expected[n]) {
+			t.Errorf("island %d has %v after the migration, expected %v", n, costs, expected[n])
+		}
+	}
+	if islands[1].bestCost != 1 || islands[1].best[0] != 1 {
+		t.Errorf("island 1 has best %v at %v", islands[1].bestCost, islands[1].best)
+	}
+}
+
+func checkETCSchedule(etc [][]float64, sol []int) bool {
+	if len(sol) != len(etc) {
+		return false
+	}
+	for _, resource := range sol {
+		if resource < 0 || resource >= len(etc[0]) {
+			return false
+		}
+	}
+	return true
+}
+
+// The speedup of the workers on a large instance, e.g.
+// go test -run XXX -bench PSO -cpu 8 github.com/chaincode/scheduling
+func BenchmarkPSOWorkers(b *testing.B) {
+	etc := GenerateETC(rand.New(rand.NewSource(1)), 2048, 32, "hi", "hi")
+	for _, workers := range []int{1, 2, 4, 8} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			opts := PSOOptions{Iterations: 20, Workers: workers}
+			for i := 0; i < b.N; i++ {
+				PSO(context.Background(), etc, opts, Budget{}, rand.New(rand.NewSource(1)))
+			}
+		})
+	}
+}
+
+func BenchmarkPSOIslands(b *testing.B) {
+	etc := GenerateETC(rand.New(rand.NewSource(1)), 2048, 32, "hi", "hi")
+	for _, islands := range []int{1, 2, 4, 8} {
+		b.Run(fmt.Sprintf("islands=%d", islands), func(b *testing.B) {
+			//the same number of particles, split into islands
+			opts := PSOOptions{Iterations: 20, Population: 64 / islands, Islands: islands, Migration: 5}
+			for i := 0; i < b.N; i++ {
+				PSO(context.Background(), etc, opts, Budget{}, rand.New(rand.NewSource(1)))
+			}
+		})
+	}
+}
+
+func BenchmarkPSOSerial(b *testing.B) {
+	etc := GenerateETC(rand.New(rand.NewSource(1)), 2048, 32, "hi", "hi")
+	for i := 0; i < b.N; i++ {
+		pso(Problem{len(etc), 0, len(etc[0])}, etc, serialOptions(PSOOptions{Iterations: 20}), rand.New(rand.NewSource(1)), newStopper(context.Background(), Budget{}), newTracer(context.Background()))
+	}
+}
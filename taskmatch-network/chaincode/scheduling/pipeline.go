@@ -0,0 +1,165 @@
+package scheduling
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+	"time"
+)
+
+/*** Hybrid pipelines: stages run one after another, each starting from the best    ***/
+/*** schedule of the stages before it, e.g. min-min, then PSO, then a tabu polish.   ***/
+
+// StageFunc runs a stage of a pipeline. seed is the best schedule of the stages before
+// it, nil for the first stage; the stage may start from it or ignore it.
+type StageFunc func(ctx context.Context, etc [][]float64, seed []int, budget Budget, rng *rand.Rand) []int
+
+// Stage is a step of a Pipeline.
+type Stage struct {
+	Name string
+	//share of the pipeline's budget relative to the other stages, 0 for a heuristic
+	//that builds its schedule in one pass and needs none
+	Weight float64
+	Run    StageFunc
+}
+
+var stages = map[string]Stage{}
+
+// RegisterStage makes a stage available to pipelines under its name. It panics if the
+// name is taken.
+func RegisterStage(s Stage) {
+	if _, ok := stages[s.Name]; ok {
+		panic("scheduling: stage " + s.Name + " registered twice")
+	}
+	stages[s.Name] = s
+}
+
+// LookupStage returns the stage registered under name. Any other registered scheduler
+// makes a stage too, one that ignores the schedule handed on to it.
+func LookupStage(name string) (Stage, error) {
+	if s, ok := stages[name]; ok {
+		return s, nil
+	}
+	f, ok := registry[name]
+	if !ok {
+		return Stage{}, fmt.Errorf("unknown stage %s", name)
+	}
+	return Stage{name, 1, func(ctx context.Context, etc [][]float64, seed []int, budget Budget, rng *rand.Rand) []int {
+		return f(ctx, etc, budget, rng)
+	}}, nil
+}
+
+// Pipeline chains stages: each gets the best schedule found so far and hands on its own
+// if it is better. The stages that search share the budget by their weights, every one
+// getting its share of what the stages before it left.
+type Pipeline []Stage
+
+// ParsePipeline parses the names of stages joined by +, like min-min+pso+tabu.
+// Lookup does so for names with a + that aren't registered, so such a name can be
+// run wherever a scheduler is named.
+func ParsePipeline(spec string) (Pipeline, error) {
+	var p Pipeline
+	for _, name := range strings.Split(spec, "+") {
+		s, err := LookupStage(name)
+		if err != nil {
+			return nil, fmt.Errorf("pipeline %s: %s", spec, err)
+		}
+		p = append(p, s)
+	}
+	return p, nil
+}
+
+func (p Pipeline) String() string {
+	var names []string
+	for _, s := range p {
+		names = append(names, s.Name)
+	}
+	return strings.Join(names, "+")
+}
+
+// Func returns the pipeline as a scheduler.
+func (p Pipeline) Func() Func {
+	return p.Run
+}
+
+// Run runs the stages in turn and returns the best schedule of them all. Once ctx is
+// done or the time of budget is up, the stages left are skipped; the first stage
+// always runs. Every stage draws from a random source of its own seeded from rng.
+func (p Pipeline) Run(ctx context.Context, etc [][]float64, budget Budget, rng *rand.Rand) []int {
+	var deadline time.Time
+	if budget.Time > 0 {
+		deadline = time.Now().Add(budget.Time)
+	}
+	evaluations := budget.Evaluations
+
+	var best []int
+	bestCost := math.Inf(1)
+	for n, s := range p {
+		if best != nil && (ctx.Err() != nil || !deadline.IsZero() && !time.Now().Before(deadline)) {
+			break
+		}
+		var share Budget
+		if s.Weight > 0 {
+			left := 0.0
+			for _, later := range p[n:] {
+				left += later.Weight
+			}
+			part := s.Weight / left
+			if budget.Time > 0 {
+				share.Time = time.Duration(float64(time.Until(deadline)) * part)
+				if share.Time <= 0 {
+					share.Time = time.Nanosecond
+				}
+			}
+			if budget.Evaluations > 0 {
+				share.Evaluations = int(float64(evaluations) * part)
+				if share.Evaluations < 1 {
+					share.Evaluations = 1
+				}
+				evaluations -= share.Evaluations
+			}
+		}
+		sol := s.Run(ctx, etc, append([]int(nil), best...), share, rand.New(rand.NewSource(rng.Int63())))
+		if cost := ETCMakespan(etc, sol); cost < bestCost {
+			best, bestCost = sol, cost
+		}
+	}
+	return best
+}
+
+func init() {
+	heuristic := func(name string, f func(etc [][]float64) []int) {
+		RegisterStage(Stage{name, 0, func(ctx context.Context, etc [][]float64, seed []int, budget Budget, rng *rand.Rand) []int {
+			return f(etc)
+		}})
+	}
+	heuristic("min-min", func(etc [][]float64) []int {
+		sol, _ := MinMin(RoundETC(etc))
+		return sol
+	})
+	heuristic("max-min", func(etc [][]float64) []int {
+		sol, _ := MaxMin(RoundETC(etc))
+		return sol
+	})
+	heuristic("sufferage", Sufferage)
+
+	RegisterStage(Stage{"sa", 1, func(ctx context.Context, etc [][]float64, seed []int, budget Budget, rng *rand.Rand) []int {
+		return annealing(RoundETC(etc), SAOptions{}.withDefaults(), seed, rng, newStopper(ctx, budget), newTracer(ctx))
+	}})
+	RegisterStage(Stage{"pso", 1, func(ctx context.Context, etc [][]float64, seed []int, budget Budget, rng *rand.Rand) []int {
+		return seededPSO(ctx, etc, PSOOptions{}, seed, budget, rng)
+	}})
+	RegisterStage(Stage{"tabu", 1, func(ctx context.Context, etc [][]float64, seed []int, budget Budget, rng *rand.Rand) []int {
+		if seed == nil {
+			seed = Sufferage(etc)
+		}
+		return tabuSearch(etc, seed, TabuOptions{}.withDefaults(), newStopper(ctx, budget), newTracer(ctx))
+	}})
+
+	for _, spec := range []string{"min-min+pso+tabu", "sufferage+sa"} {
+		p, _ := ParsePipeline(spec)
+		Register(spec, p.Func())
+	}
+}
@@ -0,0 +1,114 @@
+package scheduling
+
+import (
+	"context"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestSufferage(t *testing.T) {
+	//task 0 suffers most without resource 0, then task 2 without resource 1
+	etc := [][]float64{{1, 5}, {2, 3}, {4, 4}}
+	if sol := Sufferage(etc); !reflect.DeepEqual(sol, []int{0, 0, 1}) {
+		t.Errorf("sufferage %v", sol)
+	}
+	//resource 1 takes tasks 2 and 1, after which task 0 finishes first on resource 0
+	ready := []float64{10, 0}
+	if sol := sufferage(etc, ready); !reflect.DeepEqual(sol, []int{0, 1, 1}) || !reflect.DeepEqual(ready, []float64{11, 7}) {
+		t.Errorf("sufferage after resource 0 is busy %v, ready %v", sol, ready)
+	}
+	if sol := Sufferage([][]float64{{3}, {1}}); !reflect.DeepEqual(sol, []int{0, 0}) {
+		t.Errorf("sufferage on one resource %v", sol)
+	}
+}
+
+func TestTabu(t *testing.T) {
+	etc := GenerateETC(rand.New(rand.NewSource(3)), 60, 5, "hi", "hi")
+	start := make([]int, len(etc))
+	for i := range start {
+		start[i] = i % 5
+	}
+	polished := tabuSearch(etc, start, TabuOptions{}.withDefaults(), newStopper(context.Background(), Budget{}), newTracer(context.Background()))
+	if !checkETCSchedule(etc, polished) || ETCMakespan(etc, polished) >= ETCMakespan(etc, start) {
+		t.Errorf("tabu polished %v to %v", ETCMakespan(etc, start), ETCMakespan(etc, polished))
+	}
+	if start[7] != 2 {
+		t.Error("tabu changed the schedule it started from")
+	}
+	//it doesn't make the best of a heuristic worse
+	seed := Sufferage(etc)
+	if sol := tabuSearch(etc, seed, TabuOptions{Iterations: 50}.withDefaults(), newStopper(context.Background(), Budget{}), newTracer(context.Background())); ETCMakespan(etc, sol) > ETCMakespan(etc, seed) {
+		t.Errorf("tabu made sufferage worse, %v from %v", ETCMakespan(etc, sol), ETCMakespan(etc, seed))
+	}
+
+	stop := newStopper(context.Background(), Budget{Evaluations: 500})
+	tabuSearch(etc, start, TabuOptions{}.withDefaults(), stop, newTracer(context.Background()))
+	if stop.evaluations < 500 || stop.evaluations > 500+len(etc)+5 {
+		t.Errorf("tabu made %d evaluations with a budget of 500", stop.evaluations)
+	}
+	if sol := tabuSearch([][]float64{{2}, {3}}, []int{0, 0}, TabuOptions{}.withDefaults(), newStopper(context.Background(), Budget{}), newTracer(context.Background())); !reflect.DeepEqual(sol, []int{0, 0}) {
+		t.Errorf("tabu on one resource %v", sol)
+	}
+}
+
+func TestSeededSearches(t *testing.T) {
+	etc := GenerateETC(rand.New(rand.NewSource(5)), 80, 6, "hi", "lo")
+	seed, _ := MinMin(RoundETC(etc))
+	sol := seededPSO(context.Background(), etc, PSOOptions{Population: 10}, seed, Budget{Evaluations: 30}, rand.New(rand.NewSource(1)))
+	if ETCMakespan(etc, sol) > ETCMakespan(etc, seed) {
+		t.Errorf("pso seeded with %v returned %v", ETCMakespan(etc, seed), ETCMakespan(etc, sol))
+	}
+	matrix := RoundETC(etc)
+	sol = annealing(matrix, SAOptions{}.withDefaults(), seed, rand.New(rand.NewSource(1)), newStopper(context.Background(), Budget{Evaluations: 30}), newTracer(context.Background()))
+	if Makespan(matrix, sol) > Makespan(matrix, seed) {
+		t.Errorf("sa seeded with %v returned %v", Makespan(matrix, seed), Makespan(matrix, sol))
+	}
+}
+
+func TestPipeline(t *testing.T) {
+	etc := [][]float64{{1, 2}, {2, 1}, {3, 3}}
+	good, bad := []int{0, 1, 0}, []int{1, 0, 1}
+	var seeds [][]int
+	var budgets []Budget
+	stage := func(name string, weight float64, sol []int) Stage {
+		return Stage{name, weight, func(ctx context.Context, etc [][]float64, seed []int, budget Budget, rng *rand.Rand) []int {
+			seeds, budgets = append(seeds, seed), append(budgets, budget)
+			return sol
+		}}
+	}
+	p := Pipeline{stage("a", 0, good), stage("b", 1, bad), stage("c", 3, bad)}
+	if p.String() != "a+b+c" {
+		t.Errorf("pipeline %s", p)
+	}
+
+	//the worse schedule of b is not handed on
+	sol := p.Run(context.Background(), etc, Budget{Evaluations: 100}, rand.New(rand.NewSource(1)))
+	if !reflect.DeepEqual(sol, good) || !reflect.DeepEqual(seeds, [][]int{nil, good, good}) {
+		t.Errorf("returned %v with seeds %v", sol, seeds)
+	}
+	if expected := []Budget{{}, {Evaluations: 25}, {Evaluations: 75}}; !reflect.DeepEqual(budgets, expected) {
+		t.Errorf("budgets %v, expected %v", budgets, expected)
+	}
+
+	//once cancelled only the first stage runs
+	seeds, budgets = nil, nil
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if sol := p.Run(ctx, etc, Budget{}, rand.New(rand.NewSource(1))); !reflect.DeepEqual(sol, good) || len(seeds) != 1 {
+		t.Errorf("cancelled pipeline returned %v after %d stages", sol, len(seeds))
+	}
+
+	if f, err := Lookup("sufferage+tabu"); err != nil {
+		t.Error(err)
+	} else if sol := f(context.Background(), etc, Budget{}, rand.New(rand.NewSource(1))); !checkETCSchedule(etc, sol) {
+		t.Errorf("sufferage+tabu returned %v", sol)
+	}
+	//registered schedulers are stages that start from scratch
+	if p, err := ParsePipeline("max-min+pso-islands+sa"); err != nil || p[1].Weight != 1 {
+		t.Errorf("%v, %v", p, err)
+	}
+	if _, err := Lookup("min-min+nope"); err == nil {
+		t.Error("found a pipeline with an unknown stage")
+	}
+}
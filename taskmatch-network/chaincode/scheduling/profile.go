@@ -0,0 +1,138 @@
+package scheduling
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+/*** Tuned profiles: parameters of a search found for a class of instances, kept in ***/
+/*** JSON files and registered as schedulers of their own.                          ***/
+
+// Profile is a set of parameters of sa, pso or pso-parallel, usually found by tuning
+// them on instances of a class, see the command tmtune. Once loaded it is a scheduler
+// of its own, registered under its name.
+type Profile struct {
+	Name      string      `json:"name"`
+	Scheduler string      `json:"scheduler"` //the search it sets up: sa, pso or pso-parallel
+	SA        *SAOptions  `json:"sa,omitempty"`
+	PSO       *PSOOptions `json:"pso,omitempty"` //pso uses all of them but the islands and workers
+
+	//what it was tuned on, for the record
+	Class       string  `json:"class,omitempty"`
+	Tasks       int     `json:"tasks,omitempty"`
+	Resources   int     `json:"resources,omitempty"`
+	Tuner       string  `json:"tuner,omitempty"`
+	Evaluations int     `json:"evaluations,omitempty"` //makespan evaluations every run got, no limit if 0
+	Score       float64 `json:"score,omitempty"`       //mean makespan over the lower bound with the tuned parameters
+	Default     float64 `json:"default,omitempty"`     //the same with the default parameters
+}
+
+// Tunable are the schedulers a Profile can set up.
+var Tunable = []string{"sa", "pso", "pso-parallel"}
+
+// Check returns an error if the profile can't be registered as a scheduler.
+func (p Profile) Check() error {
+	if p.Name == "" || strings.ContainsAny(p.Name, ", \t\n") {
+		return fmt.Errorf("profile name %q is empty or has commas or spaces", p.Name)
+	}
+	switch p.Scheduler {
+	case "sa":
+		if p.SA == nil {
+			return fmt.Errorf("profile %s of sa has no sa parameters", p.Name)
+		}
+	case "pso", "pso-parallel":
+		if p.PSO == nil {
+			return fmt.Errorf("profile %s of %s has no pso parameters", p.Name, p.Scheduler)
+		}
+		if err := p.PSO.Check(); err != nil {
+			return fmt.Errorf("profile %s: %s", p.Name, err)
+		}
+	default:
+		return fmt.Errorf("profile %s is for %q, only %s can be tuned", p.Name, p.Scheduler, strings.Join(Tunable, ", "))
+	}
+	return nil
+}
+
+// Func returns the scheduler the profile sets up.
+func (p Profile) Func() (Func, error) {
+	if err := p.Check(); err != nil {
+		return nil, err
+	}
+	switch p.Scheduler {
+	case "sa":
+		opts := *p.SA
+		return func(ctx context.Context, etc [][]float64, budget Budget, rng *rand.Rand) []int {
+			return SA(ctx, etc, opts, budget, rng)
+		}, nil
+	case "pso":
+		opts := serialOptions(*p.PSO)
+		return func(ctx context.Context, etc [][]float64, budget Budget, rng *rand.Rand) []int {
+			problem := Problem{len(etc), 0, len(etc[0])}
+			gbest, _ := pso(problem, etc, opts, rng, newStopper(ctx, budget), newTracer(ctx))
+			return positionToSchedule(gbest.position)
+		}, nil
+	}
+	opts := *p.PSO
+	return func(ctx context.Context, etc [][]float64, budget Budget, rng *rand.Rand) []int {
+		return PSO(ctx, etc, opts, budget, rng)
+	}, nil
+}
+
+// ReadProfile reads a profile from a JSON file.
+func ReadProfile(path string) (Profile, error) {
+	var p Profile
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return p, err
+	}
+	if err := json.Unmarshal(data, &p); err != nil {
+		return p, fmt.Errorf("%s: %s", path, err)
+	}
+	if err := p.Check(); err != nil {
+		return p, fmt.Errorf("%s: %s", path, err)
+	}
+	return p, nil
+}
+
+// WriteProfile writes p to a JSON file.
+func WriteProfile(path string, p Profile) error {
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// LoadProfiles registers the profiles of the .json files in dir as schedulers and
+// returns their names. A directory that doesn't exist holds no profiles.
+func LoadProfiles(dir string) ([]string, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(dir); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	sort.Strings(paths)
+	var names []string
+	for _, path := range paths {
+		p, err := ReadProfile(path)
+		if err != nil {
+			return names, err
+		}
+		if _, err := Lookup(p.Name); err == nil {
+			return names, fmt.Errorf("%s: a scheduler %s is registered already", path, p.Name)
+		}
+		f, _ := p.Func()
+		Register(p.Name, f)
+		names = append(names, p.Name)
+	}
+	return names, nil
+}
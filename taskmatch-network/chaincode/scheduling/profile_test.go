@@ -0,0 +1,64 @@
+package scheduling
+
+import (
+	"context"
+	"io/ioutil"
+	"math/rand"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestProfiles(t *testing.T) {
+	dir := t.TempDir()
+	profiles := []Profile{
+		{Name: "test-sa@i_hihi", Scheduler: "sa", SA: &SAOptions{Temperature: 800, CoolingRate: 0.01}, Class: "i_hihi", Score: 1.02},
+		{Name: "test-pso@i_hihi", Scheduler: "pso-parallel", PSO: &PSOOptions{C1: 1.2, C2: 2.1, Topology: TopologyRing}},
+	}
+	for _, p := range profiles {
+		if err := WriteProfile(filepath.Join(dir, p.Name+".json"), p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	read, err := ReadProfile(filepath.Join(dir, "test-sa@i_hihi.json"))
+	if err != nil || !reflect.DeepEqual(read, profiles[0]) {
+		t.Fatalf("read %+v, %v", read, err)
+	}
+
+	names, err := LoadProfiles(dir)
+	if err != nil || !reflect.DeepEqual(names, []string{"test-pso@i_hihi", "test-sa@i_hihi"}) {
+		t.Fatalf("loaded %v, %v", names, err)
+	}
+	etc := GenerateETC(rand.New(rand.NewSource(1)), 40, 5, "hi", "lo")
+	f, err := Lookup("test-sa@i_hihi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sol := f(context.Background(), etc, Budget{}, rand.New(rand.NewSource(1))); !reflect.DeepEqual(sol, SA(context.Background(), etc, *profiles[0].SA, Budget{}, rand.New(rand.NewSource(1)))) {
+		t.Error("the profile schedules differently from its parameters")
+	}
+	//loaded twice the names are taken
+	if _, err := LoadProfiles(dir); err == nil {
+		t.Error("registered a profile twice")
+	}
+	if names, err := LoadProfiles(filepath.Join(dir, "missing")); err != nil || names != nil {
+		t.Errorf("missing directory: %v, %v", names, err)
+	}
+
+	for _, p := range []Profile{
+		{Name: "", Scheduler: "sa", SA: &SAOptions{}},
+		{Name: "a,b", Scheduler: "sa", SA: &SAOptions{}},
+		{Name: "x", Scheduler: "min-min"},
+		{Name: "x", Scheduler: "sa"},
+		{Name: "x", Scheduler: "pso", PSO: &PSOOptions{Boundary: "wrap"}},
+	} {
+		if p.Check() == nil {
+			t.Errorf("%+v passed the check", p)
+		}
+	}
+	path := filepath.Join(dir, "broken.json")
+	ioutil.WriteFile(path, []byte(`{"name": "broken", "scheduler": "sa"}`), 0644)
+	if _, err := ReadProfile(path); err == nil {
+		t.Error("read a profile without parameters")
+	}
+}
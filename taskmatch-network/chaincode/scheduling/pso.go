@@ -0,0 +1,267 @@
+package scheduling
+
+import (
+	"math"
+	"math/rand"
+)
+
+// Position : this contains the currrent position and the point's fitness value
+type Position struct {
+	position []float64
+	cost     float64
+}
+
+// Problem : defines the structure of a problem, including the number of tasks and
+// resources
+type Problem struct {
+	nVar   int // number of tasks
+	varmin int
+	varMax int
+}
+
+// Particle : this is the particle struct
+type Particle struct {
+	position []float64
+	velocity []float64
+	pBest    []float64
+	cost     float64
+	bestCost float64
+}
+
+func multiplyNumAndArr(factor float64, arrIn []float64) []float64 {
+	result := make([]float64, len(arrIn))
+	for i := 0; i < len(arrIn); i++ {
+		result[i] = arrIn[i] * factor
+	}
+	return result
+}
+
+func addArrs(arrs ...[]float64) []float64 {
+	result := make([]float64, len(arrs[0]))
+	for _, arr := range arrs {
+		for i := 0; i < len(result); i++ {
+			result[i] += arr[i]
+		}
+	}
+	return result
+}
+
+func multiplyArrs(arr1 []float64, arr2 []float64) []float64 {
+	result := make([]float64, len(arr1))
+	for i := 0; i < len(arr1); i++ {
+		result[i] = arr1[i] * arr2[i]
+	}
+	return result
+}
+
+func subtractArrs(arr1 []float64, arr2 []float64) []float64 {
+	result := make([]float64, len(arr1))
+	for i := 0; i < len(arr1); i++ {
+		result[i] = arr1[i] - arr2[i]
+	}
+	return result
+}
+
+func generateRandomArr(rng *rand.Rand, lower float64, upper float64, size int) []float64 {
+	result := make([]float64, size)
+	for i := 0; i < size; i++ {
+		result[i] = rng.Float64()*(upper-lower) + lower
+	}
+	return result
+}
+
+func fetchRunTime(inputMatrix [][]float64, task int, resource int) float64 {
+	return inputMatrix[task][resource]
+}
+
+func evaluate(inputMatrix [][]float64, inputSol []int) float64 {
+	makespan := make([]float64, len(inputMatrix[0]))
+	resources := len(inputMatrix[0])
+	for i := 0; i < len(inputSol); i++ { // length of input solution = # of tasks
+		temp := inputSol[i] // temp => corresponding resource assigned to each task
+		if temp >= resources || temp < 0 {
+			temp = ((temp % resources) + resources) % resources
+		}
+		result := fetchRunTime(inputMatrix, i, temp)
+		makespan[temp] = makespan[temp] + result
+	}
+	maxCompletion := float64(-1)
+	for j := 0; j < len(makespan); j++ {
+		if makespan[j] > maxCompletion {
+			maxCompletion = makespan[j]
+		}
+	}
+	return maxCompletion
+}
+
+// serialOptions fills in the defaults of opts for pso, with the speed limit and bounds of
+// the registered pso unless opts set them.
+func serialOptions(opts PSOOptions) PSOOptions {
+	if opts.VelocityClamp == 0 {
+		opts.VelocityClamp = psoVelocityClamp
+	}
+	if opts.Boundary == "" {
+		opts.Boundary = psoBoundary
+	}
+	return opts.withDefaults()
+}
+
+// pso returns the best position found and the swarm after opts.Iterations iterations, or
+// as soon as stop says so. The swarm is then smaller than opts.Population if it was still
+// being set up. The particles move one after another, each following the best position
+// found so far by the swarm, or by its neighbours in opts.Topology, with the inertia
+// schedule, constriction, speed limit and boundary handling of opts, see PSOOptions.
+// opts must have their defaults filled in, islands and workers are not used. Every
+// iteration is reported to trace.
+func pso(inputProblem Problem, inputMatrix [][]float64, opts PSOOptions, rng *rand.Rand, stop *stopper, trace *tracer) (Position, []Particle) {
+	// Initialize an empty object of type "Particle"
+	var emptyParticle Particle
+
+	// Extract problem information
+	varMin := inputProblem.varmin
+	varMax := inputProblem.varMax
+	nVar := inputProblem.nVar
+	maxIter, popSize, w := opts.Iterations, opts.Population, opts.Inertia
+
+	gBest := Position{nil, math.Inf(1)}
+	m := &motion{c1: opts.C1, c2: opts.C2, vmax: opts.VelocityClamp * float64(varMax), boundary: opts.Boundary, resources: varMax}
+	if opts.Constriction {
+		m.chi = constriction(opts.C1, opts.C2)
+	}
+
+	pop := []Particle{}
+
+	// This loop is for initialization
+	for i := 0; i < popSize; i++ {
+		//at least one particle, so that there is a best position
+		if i > 0 && stop.stop() {
+			return gBest, pop
+		}
+		pop = append(pop, emptyParticle)
+		pop[i].position = generateRandomArr(rng, float64(varMin), float64(varMax), nVar)
+		pop[i].velocity = generateRandomArr(rng, float64(-varMax), float64(varMax), nVar)
+		clampVelocity(pop[i].velocity, m.vmax)
+		x := make([]int, len(pop[i].position))
+		for j := 0; j < len(x); j++ {
+			x[j] = int(pop[i].position[j])
+		}
+		pop[i].cost = evaluate(inputMatrix, x)
+		stop.evaluated()
+		// copy(pop[i].pBest, pop[i].position)
+		pop[i].pBest = pop[i].position
+		pop[i].bestCost = pop[i].cost
+
+		if pop[i].bestCost < gBest.cost {
+			// copy(gBest.position, pop[i].pBest)
+			gBest.position = pop[i].pBest
+			gBest.cost = pop[i].bestCost
+		}
+	}
+	neighbors := neighborhoods(opts.Topology, popSize, opts.Neighbors, rng)
+
+	//PSO loop
+	for iter := 0; iter < maxIter; iter++ {
+		improved, better := 0, false
+		for i := 0; i < popSize; i++ {
+			if stop.stop() {
+				return gBest, pop
+			}
+			//the best personal best of the neighbours, the particle's own included
+			social := gBest.position
+			if neighbors != nil {
+				informant := i
+				for _, j := range neighbors[i] {
+					if pop[j].bestCost < pop[informant].bestCost {
+						informant = j
+					}
+				}
+				social = pop[informant].pBest
+			}
+			cognitive := multiplyArrs(multiplyNumAndArr(m.c1, generateRandomArr(rng, 0, 1, nVar)), subtractArrs(pop[i].pBest, pop[i].position))
+			pull := multiplyArrs(multiplyNumAndArr(m.c2, generateRandomArr(rng, 0, 1, nVar)), subtractArrs(social, pop[i].position))
+			if m.chi > 0 {
+				pop[i].velocity = multiplyNumAndArr(m.chi, addArrs(pop[i].velocity, cognitive, pull))
+			} else {
+				pop[i].velocity = addArrs(multiplyNumAndArr(w, pop[i].velocity), cognitive, pull)
+			}
+
+			clampVelocity(pop[i].velocity, m.vmax)
+
+			pop[i].position = addArrs(pop[i].position, pop[i].velocity)
+			for d := range pop[i].position {
+				pop[i].position[d], pop[i].velocity[d] = m.bound(pop[i].position[d], pop[i].velocity[d], rng)
+			}
+
+			x := make([]int, len(pop[i].position))
+			for j := 0; j < len(x); j++ {
+				x[j] = int(pop[i].position[j])
+			}
+
+			pop[i].cost = evaluate(inputMatrix, x)
+			stop.evaluated()
+			if pop[i].cost < pop[i].bestCost {
+				improved++
+				// copy(pop[i].pBest, pop[i].position)
+				pop[i].pBest = pop[i].position
+				pop[i].bestCost = pop[i].cost
+				if pop[i].bestCost < gBest.cost {
+					better = true
+					// copy(gBest.position, pop[i].pBest)
+					gBest.position = pop[i].pBest
+					gBest.cost = pop[i].bestCost
+				}
+			}
+		}
+		if trace.on() {
+			costs := make([]float64, len(pop))
+			positions := make([][]float64, len(pop))
+			for i := range pop {
+				costs[i], positions[i] = pop[i].cost, pop[i].position
+			}
+			inertia := w
+			if m.chi > 0 {
+				inertia = m.chi
+			}
+			trace.observe(swarmSample(iter, 0, stop.evaluations, gBest.cost, inertia, costs, positions))
+		}
+		if opts.Topology == TopologyRandom && !better {
+			neighbors = neighborhoods(opts.Topology, popSize, opts.Neighbors, rng)
+		}
+		w = nextInertia(&opts, w, iter, improved, popSize)
+	}
+	return gBest, pop
+}
+
+// clampVelocity limits every coordinate of velocity to [-vmax, vmax], unless vmax is 0.
+func clampVelocity(velocity []float64, vmax float64) {
+	if vmax <= 0 {
+		return
+	}
+	for d, v := range velocity {
+		velocity[d] = math.Min(math.Max(v, -vmax), vmax)
+	}
+}
+
+func deepcopy(inputMatrix [][]float64) [][]float64 {
+	result := make([][]float64, len(inputMatrix))
+	for i := range result {
+		result[i] = make([]float64, len(inputMatrix[i]))
+	}
+	for i := 0; i < len(inputMatrix); i++ {
+		for j := 0; j < len(inputMatrix[i]); j++ {
+			result[i][j] = inputMatrix[i][j]
+		}
+	}
+	return result
+}
+
+// swarmSample is the sample of an iteration of a swarm whose particles have the costs
+// and positions given.
+func swarmSample(iter, island, evaluations int, best, inertia float64, costs []float64, positions [][]float64) Sample {
+	mean := 0.0
+	for _, cost := range costs {
+		mean += cost / float64(len(costs))
+	}
+	return Sample{Iteration: iter, Island: island, Evaluations: evaluations, Best: best, Current: mean,
+		Inertia: inertia, Diversity: spread(positions)}
+}
@@ -0,0 +1,91 @@
+package scheduling
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+	"testing/quick"
+)
+
+func TestEvaluate(t *testing.T) {
+	tests := []struct {
+		name     string
+		matrix   [][]float64
+		sol      []int
+		makespan float64
+	}{
+		{"one task each", [][]float64{{1, 2, 3}, {4, 5, 6}, {7, 8, 9}}, []int{0, 1, 2}, 9},
+		{"all on one resource", [][]float64{{1, 2, 3}, {4, 5, 6}, {7, 8, 9}}, []int{2, 2, 2}, 18},
+		{"fractions", [][]float64{{0.5, 1}, {0.25, 1}}, []int{0, 0}, 0.75},
+		//a resource index one past the end wraps around to resource 0
+		{"wraps invalid resource", [][]float64{{1, 2, 3}, {4, 5, 6}}, []int{3, 0}, 5},
+		{"wraps negative resource", [][]float64{{1, 2, 3}, {4, 5, 6}}, []int{-1, 0}, 4},
+		{"wraps on one resource", [][]float64{{2}, {3}}, []int{1, 0}, 5},
+	}
+	for _, tt := range tests {
+		if got := evaluate(tt.matrix, tt.sol); got != tt.makespan {
+			t.Errorf("%s: evaluate = %v, expected %v", tt.name, got, tt.makespan)
+		}
+	}
+}
+
+func TestEvaluateMatchesCalcRuntime(t *testing.T) {
+	property := func(matrix etcMatrix, seed int64) bool {
+		r := rand.New(rand.NewSource(seed))
+		sol := make([]int, len(matrix))
+		for i := range sol {
+			sol[i] = r.Intn(len(matrix[0]))
+		}
+		return evaluate(iToFMatrix(matrix), sol) == float64(calcRuntime(matrix, sol))
+	}
+	if err := quick.Check(property, quickConfig); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestPSOProperties(t *testing.T) {
+	property := func(matrix etcMatrix) bool {
+		etc := iToFMatrix(matrix)
+		original := deepcopy(etc)
+		problem := Problem{len(matrix), 0, len(matrix[0])}
+		gbest, pop := pso(problem, etc, serialOptions(PSOOptions{Iterations: 20, Population: 10}), rand.New(rand.NewSource(1)), newStopper(context.Background(), Budget{}), newTracer(context.Background()))
+
+		//the best position maps to a valid assignment with the reported cost
+		sol := make([]int, len(gbest.position))
+		for i, x := range gbest.position {
+			sol[i] = int(x)
+		}
+		if !checkSchedule(matrix, sol) || gbest.cost != evaluate(etc, sol) {
+			return false
+		}
+		//the particles stay in range, no faster than the clamp
+		vmax := psoVelocityClamp * float64(len(matrix[0]))
+		for _, particle := range pop {
+			if particle.bestCost < gbest.cost {
+				return false
+			}
+			for d, x := range particle.position {
+				if x < 0 || x >= float64(len(matrix[0])) || math.Abs(particle.velocity[d]) > vmax {
+					return false
+				}
+			}
+		}
+		return reflect.DeepEqual(etc, original)
+	}
+	config := &quick.Config{MaxCount: 50, Rand: rand.New(rand.NewSource(1))}
+	if err := quick.Check(property, config); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestPSONoWorseThanRandomStart(t *testing.T) {
+	//with every particle starting at a random position the swarm can't end up worse
+	//than assigning all tasks to the slowest resource
+	matrix := [][]float64{{1, 100}, {1, 100}, {1, 100}, {100, 1}}
+	gbest, _ := pso(Problem{4, 0, 2}, matrix, serialOptions(PSOOptions{Iterations: 50, Population: 20}), rand.New(rand.NewSource(1)), newStopper(context.Background(), Budget{}), newTracer(context.Background()))
+	if gbest.cost > 300 {
+		t.Errorf("pso found makespan %v", gbest.cost)
+	}
+}
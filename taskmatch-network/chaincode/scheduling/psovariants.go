@@ -0,0 +1,162 @@
+package scheduling
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+)
+
+/*** The variants of the concurrent PSO: who the particles learn from, how the     ***/
+/*** inertia changes, how fast they may move and what happens at the bounds.        ***/
+
+// Topologies: which particles a particle learns from besides itself.
+const (
+	TopologyGlobal     = "global"      //every particle of the island
+	TopologyRing       = "ring"        //the particles before and after it
+	TopologyVonNeumann = "von-neumann" //the four around it, the particles laid out on a torus
+	TopologyRandom     = "random"      //random ones, drawn again whenever the island doesn't improve
+)
+
+// Inertia schedules.
+const (
+	InertiaDamped   = "damped"   //multiplied by Damping after every iteration
+	InertiaLinear   = "linear"   //falls in a straight line from Inertia to InertiaEnd
+	InertiaAdaptive = "adaptive" //between InertiaEnd and Inertia as the share of particles that improved
+)
+
+// Boundary handling: what happens to a coordinate that leaves [0, resources).
+const (
+	BoundaryClamp   = "clamp"   //it is put back on the bound
+	BoundaryAbsorb  = "absorb"  //it is put back on the bound and stops there
+	BoundaryReflect = "reflect" //it bounces off the bound and turns around
+	BoundaryRandom  = "random"  //it is put anywhere in the range
+)
+
+// Check returns an error if the options name an unknown variant or can't work, e.g. a
+// constriction factor with C1+C2 of 4 or less. PSO panics on such options, so options
+// read from a file should be checked first.
+func (o PSOOptions) Check() error {
+	o = o.withDefaults()
+	switch o.Topology {
+	case TopologyGlobal, TopologyRing, TopologyVonNeumann, TopologyRandom:
+	default:
+		return fmt.Errorf("unknown topology %q", o.Topology)
+	}
+	switch o.InertiaSchedule {
+	case InertiaDamped, InertiaLinear, InertiaAdaptive:
+	default:
+		return fmt.Errorf("unknown inertia schedule %q", o.InertiaSchedule)
+	}
+	switch o.Boundary {
+	case BoundaryClamp, BoundaryAbsorb, BoundaryReflect, BoundaryRandom:
+	default:
+		return fmt.Errorf("unknown boundary handling %q", o.Boundary)
+	}
+	if o.Constriction && o.C1+o.C2 <= 4 {
+		return fmt.Errorf("the constriction factor needs C1+C2 above 4, not %v", o.C1+o.C2)
+	}
+	if o.C1 < 0 || o.C2 < 0 || o.Inertia < 0 || o.InertiaEnd < 0 || o.Damping < 0 || o.VelocityClamp < 0 {
+		return fmt.Errorf("the coefficients of the swarm can't be negative")
+	}
+	return nil
+}
+
+// constriction is Clerc's constriction factor for the acceleration coefficients c1 and
+// c2, whose sum must be above 4.
+func constriction(c1, c2 float64) float64 {
+	phi := c1 + c2
+	return 2 / math.Abs(2-phi-math.Sqrt(phi*phi-4*phi))
+}
+
+// neighborhoods lists, for each of n particles, the particles it learns from, itself
+// included. It is nil for the global topology, where every particle follows the best of
+// the island.
+func neighborhoods(topology string, n, k int, rng *rand.Rand) [][]int {
+	if topology == TopologyGlobal {
+		return nil
+	}
+	neighbors := make([][]int, n)
+	for i := range neighbors {
+		neighbors[i] = []int{i}
+	}
+	link := func(i, j int) {
+		for _, known := range neighbors[i] {
+			if known == j {
+				return
+			}
+		}
+		neighbors[i] = append(neighbors[i], j)
+	}
+	switch topology {
+	case TopologyRing:
+		for i := range neighbors {
+			link(i, (i+n-1)%n)
+			link(i, (i+1)%n)
+		}
+	case TopologyVonNeumann:
+		//rows of width columns, wrapping around at the edges
+		columns := int(math.Ceil(math.Sqrt(float64(n))))
+		for i := range neighbors {
+			link(i, (i+n-1)%n)
+			link(i, (i+1)%n)
+			link(i, (i+n-columns%n)%n)
+			link(i, (i+columns)%n)
+		}
+	case TopologyRandom:
+		//every particle informs k random ones, as in SPSO 2007
+		for i := range neighbors {
+			for l := 0; l < k; l++ {
+				link(rng.Intn(n), i)
+			}
+		}
+	}
+	return neighbors
+}
+
+// motion is how the particles of an island move in an iteration.
+type motion struct {
+	inertia, c1, c2 float64
+	chi             float64 //constriction factor, 0 to use the inertia
+	vmax            float64 //largest speed, 0 for any
+	boundary        string
+	resources       int
+}
+
+// bound handles a coordinate x that moved with velocity v, returning them within the
+// range [0, resources).
+func (m *motion) bound(x, v float64, rng *rand.Rand) (float64, float64) {
+	upper := math.Nextafter(float64(m.resources), 0)
+	if x >= 0 && x <= upper {
+		return x, v
+	}
+	switch m.boundary {
+	case BoundaryAbsorb:
+		v = 0
+	case BoundaryReflect:
+		if x < 0 {
+			x = -x
+		} else {
+			x = 2*upper - x
+		}
+		v = -v
+	case BoundaryRandom:
+		return rng.Float64() * float64(m.resources), v
+	}
+	return math.Min(math.Max(x, 0), upper), v
+}
+
+// nextInertia is the inertia of the iteration after the given one, in which improved
+// of the island's particles found a better position.
+func nextInertia(o *PSOOptions, inertia float64, iteration, improved, particles int) float64 {
+	switch o.InertiaSchedule {
+	case InertiaLinear:
+		if o.Iterations <= 1 {
+			return o.InertiaEnd
+		}
+		progress := math.Min(float64(iteration+1)/float64(o.Iterations-1), 1)
+		return o.Inertia - (o.Inertia-o.InertiaEnd)*progress
+	case InertiaAdaptive:
+		return o.InertiaEnd + (o.Inertia-o.InertiaEnd)*float64(improved)/float64(particles)
+	}
+	return inertia * o.Damping
+}
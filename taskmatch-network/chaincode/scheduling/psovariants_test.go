@@ -0,0 +1,255 @@
+package scheduling
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestPSOVariants(t *testing.T) {
+	etc := GenerateETC(rand.New(rand.NewSource(4)), 80, 6, "hi", "lo")
+	rng := rand.New(rand.NewSource(1))
+	random := make([]int, len(etc))
+	for i := range random {
+		random[i] = rng.Intn(len(etc[0]))
+	}
+	variants := []PSOOptions{
+		{Topology: TopologyRing},
+		{Topology: TopologyVonNeumann},
+		{Topology: TopologyRandom, Neighbors: 2},
+		{InertiaSchedule: InertiaLinear},
+		{InertiaSchedule: InertiaAdaptive, Inertia: 0.8, InertiaEnd: 0.3},
+		{Constriction: true},
+		{VelocityClamp: 0.2, Boundary: BoundaryAbsorb},
+		{Boundary: BoundaryReflect},
+		{Boundary: BoundaryRandom, Islands: 2},
+	}
+	for _, opts := range variants {
+		opts.Iterations, opts.Population = 60, 15
+		var first []int
+		for _, workers := range []int{1, 3} {
+			opts.Workers = workers
+			sol := PSO(context.Background(), etc, opts, Budget{}, rand.New(rand.NewSource(5)))
+			if !checkETCSchedule(etc, sol) {
+				t.Fatalf("%+v: invalid schedule %v", opts, sol)
+			}
+			if first == nil {
+				first = sol
+			} else if !reflect.DeepEqual(sol, first) {
+				t.Errorf("%+v: %d workers give another schedule than 1", opts, workers)
+			}
+		}
+		if ETCMakespan(etc, first) >= ETCMakespan(etc, random) {
+			t.Errorf("%+v found %v, a random schedule %v", opts, ETCMakespan(etc, first), ETCMakespan(etc, random))
+		}
+	}
+}
+
+func TestSerialPSOVariants(t *testing.T) {
+	etc := GenerateETC(rand.New(rand.NewSource(4)), 80, 6, "hi", "lo")
+	rng := rand.New(rand.NewSource(1))
+	random := make([]int, len(etc))
+	for i := range random {
+		random[i] = rng.Intn(len(etc[0]))
+	}
+	variants := []PSOOptions{
+		{Topology: TopologyRing},
+		{Topology: TopologyVonNeumann},
+		{Topology: TopologyRandom, Neighbors: 2},
+		{InertiaSchedule: InertiaLinear},
+		{InertiaSchedule: InertiaAdaptive, Inertia: 0.8, InertiaEnd: 0.3},
+		{Constriction: true, Boundary: BoundaryReflect},
+		{VelocityClamp: 0.2, Boundary: BoundaryRandom},
+	}
+	problem := Problem{len(etc), 0, len(etc[0])}
+	for _, variant := range variants {
+		variant.Iterations, variant.Population = 60, 15
+		opts := serialOptions(variant)
+		trace := new(Trace)
+		gbest, pop := pso(problem, etc, opts, rand.New(rand.NewSource(5)), newStopper(context.Background(), Budget{}),
+			newTracer(WithObserver(context.Background(), trace)))
+		sol := positionToSchedule(gbest.position)
+		if !checkETCSchedule(etc, sol) || ETCMakespan(etc, sol) >= ETCMakespan(etc, random) {
+			t.Fatalf("%+v found %v, a random schedule %v", variant, sol, ETCMakespan(etc, random))
+		}
+		again, _ := pso(problem, etc, opts, rand.New(rand.NewSource(5)), newStopper(context.Background(), Budget{}), newTracer(context.Background()))
+		if !reflect.DeepEqual(positionToSchedule(again.position), sol) {
+			t.Errorf("%+v is not deterministic", variant)
+		}
+		vmax := opts.VelocityClamp * float64(len(etc[0]))
+		for _, particle := range pop {
+			for d, x := range particle.position {
+				if x < 0 || x >= float64(len(etc[0])) || math.Abs(particle.velocity[d]) > vmax {
+					t.Fatalf("%+v: particle at %v with velocity %v", variant, x, particle.velocity[d])
+				}
+			}
+		}
+
+		//the inertia follows the schedule, or is replaced by the constriction factor
+		first, last := trace.Samples[0].Inertia, trace.Samples[len(trace.Samples)-1].Inertia
+		switch {
+		case variant.Constriction:
+			if first != constriction(opts.C1, opts.C2) || last != first {
+				t.Errorf("%+v reported an inertia of %v and %v", variant, first, last)
+			}
+		case variant.InertiaSchedule == InertiaLinear:
+			if first != 0.9 || math.Abs(last-0.4) > 1e-9 {
+				t.Errorf("%+v went from %v to %v", variant, first, last)
+			}
+		case variant.InertiaSchedule == InertiaAdaptive:
+			for _, sample := range trace.Samples {
+				if sample.Inertia < 0.3 || sample.Inertia > 0.8 {
+					t.Fatalf("%+v reported an inertia of %v", variant, sample.Inertia)
+				}
+			}
+		}
+	}
+
+	//a tuned profile of pso takes the variants as well
+	profile := Profile{Name: "ring", Scheduler: "pso", PSO: &PSOOptions{Iterations: 60, Population: 15, Topology: TopologyRing, Constriction: true}}
+	f, err := profile.Func()
+	if err != nil {
+		t.Fatal(err)
+	}
+	trace := new(Trace)
+	f(WithObserver(context.Background(), trace), etc, Budget{}, rand.New(rand.NewSource(5)))
+	if len(trace.Samples) != 60 || trace.Samples[0].Inertia != constriction(2.05, 2.05) {
+		t.Errorf("the profile reported %d samples, the first %+v", len(trace.Samples), trace.Samples[0])
+	}
+}
+
+func TestPSOOptionsCheck(t *testing.T) {
+	for _, opts := range []PSOOptions{{}, {Topology: TopologyRandom, InertiaSchedule: InertiaAdaptive, Boundary: BoundaryReflect}, {Constriction: true}} {
+		if err := opts.Check(); err != nil {
+			t.Errorf("%+v: %v", opts, err)
+		}
+	}
+	for _, opts := range []PSOOptions{
+		{Topology: "star"},
+		{InertiaSchedule: "chaotic"},
+		{Boundary: "wrap"},
+		{Constriction: true, C1: 1.5, C2: 1.5},
+		{VelocityClamp: -1},
+	} {
+		if opts.Check() == nil {
+			t.Errorf("%+v passed the check", opts)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("PSO ran with an unknown topology")
+		}
+	}()
+	PSO(context.Background(), [][]float64{{1}}, PSOOptions{Topology: "star"}, Budget{}, rand.New(rand.NewSource(1)))
+}
+
+func TestNeighborhoods(t *testing.T) {
+	if neighborhoods(TopologyGlobal, 5, 3, nil) != nil {
+		t.Error("the global topology has neighbourhoods")
+	}
+	sorted := func(neighbors []int) []int {
+		neighbors = append([]int(nil), neighbors...)
+		sort.Ints(neighbors)
+		return neighbors
+	}
+
+	ring := neighborhoods(TopologyRing, 5, 0, nil)
+	if got := sorted(ring[0]); !reflect.DeepEqual(got, []int{0, 1, 4}) {
+		t.Errorf("ring neighbours of 0: %v", got)
+	}
+	if got := sorted(ring[2]); !reflect.DeepEqual(got, []int{1, 2, 3}) {
+		t.Errorf("ring neighbours of 2: %v", got)
+	}
+
+	//9 particles on a 3 by 3 torus
+	grid := neighborhoods(TopologyVonNeumann, 9, 0, nil)
+	if got := sorted(grid[0]); !reflect.DeepEqual(got, []int{0, 1, 3, 6, 8}) {
+		t.Errorf("von Neumann neighbours of 0: %v", got)
+	}
+	if got := sorted(grid[4]); !reflect.DeepEqual(got, []int{1, 3, 4, 5, 7}) {
+		t.Errorf("von Neumann neighbours of 4: %v", got)
+	}
+
+	//every particle knows itself and informs up to k others
+	random := neighborhoods(TopologyRandom, 10, 3, rand.New(rand.NewSource(1)))
+	informs := make([]int, 10)
+	for i, neighbors := range random {
+		if neighbors[0] != i {
+			t.Errorf("particle %d doesn't know itself: %v", i, neighbors)
+		}
+		for _, j := range neighbors[1:] {
+			informs[j]++
+		}
+	}
+	for i, n := range informs {
+		if n > 3 {
+			t.Errorf("particle %d informs %d others", i, n)
+		}
+	}
+}
+
+func TestInertiaAndConstriction(t *testing.T) {
+	//the usual c1 = c2 = 2.05 give the χ of the default inertia
+	if chi := constriction(2.05, 2.05); math.Abs(chi-0.729844) > 1e-6 {
+		t.Errorf("constriction factor %v", chi)
+	}
+
+	linear := PSOOptions{Iterations: 11, InertiaSchedule: InertiaLinear}.withDefaults()
+	w := linear.Inertia
+	for iteration := 0; iteration < 10; iteration++ {
+		w = nextInertia(&linear, w, iteration, 0, 10)
+	}
+	if linear.Inertia != 0.9 || math.Abs(w-0.4) > 1e-12 {
+		t.Errorf("linear inertia starts at %v and ends at %v", linear.Inertia, w)
+	}
+	if w := nextInertia(&linear, 0, 4, 0, 10); math.Abs(w-0.65) > 1e-12 {
+		t.Errorf("linear inertia %v after 5 of 10 steps", w)
+	}
+
+	adaptive := PSOOptions{InertiaSchedule: InertiaAdaptive}.withDefaults()
+	if w := nextInertia(&adaptive, 0, 0, 5, 10); math.Abs(w-0.65) > 1e-12 {
+		t.Errorf("adaptive inertia %v when half the particles improved", w)
+	}
+	damped := PSOOptions{}.withDefaults()
+	if w := nextInertia(&damped, 1, 0, 0, 10); w != psoDamping {
+		t.Errorf("damped inertia %v", w)
+	}
+}
+
+func TestBoundaries(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	upper := math.Nextafter(4, 0)
+	tests := []struct {
+		boundary string
+		x, v     float64
+		expected [2]float64
+	}{
+		{BoundaryClamp, 2.5, 1, [2]float64{2.5, 1}},
+		{BoundaryClamp, 5, 2, [2]float64{upper, 2}},
+		{BoundaryAbsorb, -1, -3, [2]float64{0, 0}},
+		{BoundaryReflect, -1, -3, [2]float64{1, 3}},
+		{BoundaryReflect, 5, 2, [2]float64{2*upper - 5, -2}},
+		{BoundaryReflect, -10, -12, [2]float64{upper, 12}}, //too far to bounce back in
+	}
+	for _, tt := range tests {
+		m := &motion{boundary: tt.boundary, resources: 4}
+		if x, v := m.bound(tt.x, tt.v, rng); x != tt.expected[0] || v != tt.expected[1] {
+			t.Errorf("%s of %v moving %v gave %v moving %v, expected %v", tt.boundary, tt.x, tt.v, x, v, tt.expected)
+		}
+	}
+	m := &motion{boundary: BoundaryRandom, resources: 4}
+	if x, v := m.bound(7, 3, rng); x < 0 || x >= 4 || v != 3 {
+		t.Errorf("random boundary gave %v moving %v", x, v)
+	}
+
+	//a clamped particle never moves faster than the limit
+	p := &swarmParticle{position: []float64{0, 3}, velocity: []float64{9, -9}, best: []float64{3, 0}, rng: rng}
+	p.move([]float64{3, 0}, &motion{inertia: 1, c1: 2, c2: 2, vmax: 0.5, boundary: BoundaryClamp, resources: 4})
+	if !reflect.DeepEqual(p.velocity, []float64{0.5, -0.5}) || !reflect.DeepEqual(p.position, []float64{0.5, 2.5}) {
+		t.Errorf("clamped particle moved to %v at %v", p.position, p.velocity)
+	}
+}
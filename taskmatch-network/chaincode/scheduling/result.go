@@ -0,0 +1,56 @@
+package scheduling
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+)
+
+// Result is a schedule for an instance with its makespan, the JSON format of result
+// files. Results can be checked offline against the instance with Check.
+type Result struct {
+	Instance   string  `json:"instance,omitempty"`
+	Scheduler  string  `json:"scheduler,omitempty"`
+	Assignment []int   `json:"assignment"` //the resource of every task
+	Makespan   float64 `json:"makespan"`
+}
+
+// NewResult is the result of assigning the tasks of inst as sol says.
+func NewResult(inst *Instance, scheduler string, sol []int) *Result {
+	return &Result{inst.Name, scheduler, sol, ETCMakespan(inst.ETC, sol)}
+}
+
+// Check returns an error unless the assignment gives every task of etc a resource and
+// has the makespan the result claims.
+func (res *Result) Check(etc [][]float64) error {
+	if len(res.Assignment) != len(etc) {
+		return fmt.Errorf("the assignment has %d tasks, the instance %d", len(res.Assignment), len(etc))
+	}
+	for i, resource := range res.Assignment {
+		if resource < 0 || resource >= len(etc[i]) {
+			return fmt.Errorf("task %d is assigned to resource %d, the instance has %d", i, resource, len(etc[i]))
+		}
+	}
+	makespan := ETCMakespan(etc, res.Assignment)
+	if math.Abs(makespan-res.Makespan) > 1e-9*math.Max(1, makespan) {
+		return fmt.Errorf("the makespan of the assignment is %v, not %v", makespan, res.Makespan)
+	}
+	return nil
+}
+
+// ReadResult reads a result in its JSON format.
+func ReadResult(r io.Reader) (*Result, error) {
+	res := new(Result)
+	if err := json.NewDecoder(r).Decode(res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// WriteResult writes a result in its JSON format.
+func WriteResult(w io.Writer, res *Result) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(res)
+}
@@ -0,0 +1,141 @@
+// Package scheduling holds the task matching heuristics run by the solvers of the
+// taskmatching chaincode, and a registry of schedulers that the benchmark tools run
+// against each other.
+//
+// An ETC (expected time to compute) matrix has a row per task and a column per resource,
+// etc[i][j] being the runtime of task i on resource j. A schedule assigns every task to
+// the index of a resource.
+package scheduling
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+)
+
+// Func schedules the tasks of an ETC matrix. rng is its only source of randomness, so
+// the same seed gives the same schedule. Searches like sa and pso return the best
+// schedule found so far as soon as ctx is done or budget is used up; heuristics that
+// build a schedule in one pass, like min-min, always finish it.
+type Func func(ctx context.Context, etc [][]float64, budget Budget, rng *rand.Rand) []int
+
+var registry = map[string]Func{}
+
+// Register makes a scheduler available under name. It panics if the name is taken.
+func Register(name string, f Func) {
+	if _, ok := registry[name]; ok {
+		panic("scheduling: scheduler " + name + " registered twice")
+	}
+	registry[name] = f
+}
+
+// Lookup returns the scheduler registered under name, or the pipeline of the stages it
+// names joined by +, see ParsePipeline.
+func Lookup(name string) (Func, error) {
+	f, ok := registry[name]
+	if !ok && strings.Contains(name, "+") {
+		p, err := ParsePipeline(name)
+		if err != nil {
+			return nil, err
+		}
+		return p.Func(), nil
+	}
+	if !ok {
+		return nil, fmt.Errorf("unknown scheduler %s", name)
+	}
+	return f, nil
+}
+
+// Names returns the names of the registered schedulers in order.
+func Names() []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// PSO parameters used by the registered "pso" scheduler.
+const (
+	psoIterations = 500
+	psoPopulation = 50
+	psoC1         = 1.796180
+	psoC2         = 1.796180
+	psoInertia    = 0.729844
+	psoDamping    = 0.995
+	//the speed is limited to half the resources and a particle leaving the range stops
+	//on its bound, without them the swarm keeps piling up on the edges
+	psoVelocityClamp = 0.5
+	psoBoundary      = BoundaryAbsorb
+)
+
+func init() {
+	Register("min-min", intScheduler(func(matrix [][]int, rng *rand.Rand, stop *stopper, trace *tracer) []int {
+		sol, _ := MinMin(matrix)
+		return sol
+	}))
+	Register("max-min", intScheduler(func(matrix [][]int, rng *rand.Rand, stop *stopper, trace *tracer) []int {
+		sol, _ := MaxMin(matrix)
+		return sol
+	}))
+	Register("sufferage", func(ctx context.Context, etc [][]float64, budget Budget, rng *rand.Rand) []int {
+		return Sufferage(etc)
+	})
+	Register("sa", intScheduler(simulatedAnnealing))
+	Register("pso", func(ctx context.Context, etc [][]float64, budget Budget, rng *rand.Rand) []int {
+		problem := Problem{len(etc), 0, len(etc[0])}
+		gbest, _ := pso(problem, etc, serialOptions(PSOOptions{}), rng, newStopper(ctx, budget), newTracer(ctx))
+		return positionToSchedule(gbest.position)
+	})
+	Register("pso-parallel", func(ctx context.Context, etc [][]float64, budget Budget, rng *rand.Rand) []int {
+		return PSO(ctx, etc, PSOOptions{}, budget, rng)
+	})
+	Register("pso-islands", func(ctx context.Context, etc [][]float64, budget Budget, rng *rand.Rand) []int {
+		return PSO(ctx, etc, PSOOptions{Population: 20, Islands: 4, Migration: 25, Migrants: 2}, budget, rng)
+	})
+	Register("pso-ring", func(ctx context.Context, etc [][]float64, budget Budget, rng *rand.Rand) []int {
+		return PSO(ctx, etc, PSOOptions{Topology: TopologyRing, Constriction: true, VelocityClamp: 0.5, Boundary: BoundaryReflect}, budget, rng)
+	})
+	Register("pso-adaptive", func(ctx context.Context, etc [][]float64, budget Budget, rng *rand.Rand) []int {
+		return PSO(ctx, etc, PSOOptions{Topology: TopologyVonNeumann, InertiaSchedule: InertiaAdaptive, VelocityClamp: 0.5, Boundary: BoundaryAbsorb}, budget, rng)
+	})
+}
+
+// intScheduler runs a heuristic written for the integer runtimes of the chaincode on
+// the ETC rounded to whole units.
+func intScheduler(f func(matrix [][]int, rng *rand.Rand, stop *stopper, trace *tracer) []int) Func {
+	return func(ctx context.Context, etc [][]float64, budget Budget, rng *rand.Rand) []int {
+		return f(RoundETC(etc), rng, newStopper(ctx, budget), newTracer(ctx))
+	}
+}
+
+// positionToSchedule turns a PSO position, whose coordinates lie in [0, resources), into
+// a schedule.
+func positionToSchedule(position []float64) []int {
+	sol := make([]int, len(position))
+	for i, x := range position {
+		sol[i] = int(x)
+	}
+	return sol
+}
+
+// MinMin repeatedly assigns the task whose earliest completion time is the smallest to
+// the resource giving it that time. It returns the schedule and its makespan.
+func MinMin(matrix [][]int) ([]int, int) {
+	return minmin(matrix)
+}
+
+// MaxMin repeatedly assigns the task whose earliest completion time is the largest to
+// the resource giving it that time. It returns the schedule and its makespan.
+func MaxMin(matrix [][]int) ([]int, int) {
+	pairs, runtime := minmax(matrix)
+	return pairsToAssignment(pairs, len(matrix)), runtime
+}
+
+// Makespan is the time the busiest resource needs to run the tasks assigned to it.
+func Makespan(matrix [][]int, sol []int) int {
+	return calcRuntime(matrix, sol)
+}
@@ -0,0 +1,170 @@
+package scheduling
+
+import (
+	"context"
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestRegisteredSchedulers(t *testing.T) {
+	for _, name := range []string{"max-min", "min-min", "min-min+pso+tabu", "pso", "pso-adaptive", "pso-islands", "pso-parallel", "pso-ring", "sa", "sufferage", "sufferage+sa"} {
+		if _, err := Lookup(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := Lookup("nope"); err == nil {
+		t.Fatal("found an unregistered scheduler")
+	}
+
+	etc := GenerateETC(rand.New(rand.NewSource(1)), 40, 6, "hi", "hi")
+	original := deepcopy(etc)
+	for _, name := range Names() {
+		f, _ := Lookup(name)
+		sol := f(context.Background(), etc, Budget{}, rand.New(rand.NewSource(7)))
+		if len(sol) != len(etc) {
+			t.Fatalf("%s scheduled %d of %d tasks", name, len(sol), len(etc))
+		}
+		for _, resource := range sol {
+			if resource < 0 || resource >= len(etc[0]) {
+				t.Fatalf("%s assigned a task to resource %d", name, resource)
+			}
+		}
+		if ETCMakespan(etc, sol) < LowerBound(etc) {
+			t.Fatalf("%s beat the lower bound", name)
+		}
+		//the same seed gives the same schedule
+		if again := f(context.Background(), etc, Budget{}, rand.New(rand.NewSource(7))); !reflect.DeepEqual(sol, again) {
+			t.Fatalf("%s is not deterministic per seed", name)
+		}
+	}
+	if !reflect.DeepEqual(etc, original) {
+		t.Fatal("a scheduler changed the ETC matrix")
+	}
+}
+
+func TestSchedulerBudgets(t *testing.T) {
+	etc := GenerateETC(rand.New(rand.NewSource(1)), 200, 8, "hi", "hi")
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	valid := func(name string, sol []int) {
+		t.Helper()
+		if len(sol) != len(etc) {
+			t.Fatalf("%s scheduled %d of %d tasks", name, len(sol), len(etc))
+		}
+		for _, resource := range sol {
+			if resource < 0 || resource >= len(etc[0]) {
+				t.Fatalf("%s assigned a task to resource %d", name, resource)
+			}
+		}
+	}
+
+	for _, name := range Names() {
+		f, _ := Lookup(name)
+
+		//a cancelled search still returns a schedule, right away
+		start := time.Now()
+		valid(name, f(cancelled, etc, Budget{}, rand.New(rand.NewSource(1))))
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("%s took %s after it was cancelled", name, elapsed)
+		}
+
+		start = time.Now()
+		valid(name, f(context.Background(), etc, Budget{Time: 20 * time.Millisecond}, rand.New(rand.NewSource(1))))
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("%s took %s with a budget of 20ms", name, elapsed)
+		}
+
+		//an evaluation budget is as deterministic as a full run
+		sol := f(context.Background(), etc, Budget{Evaluations: 120}, rand.New(rand.NewSource(1)))
+		valid(name, sol)
+		if again := f(context.Background(), etc, Budget{Evaluations: 120}, rand.New(rand.NewSource(1))); !reflect.DeepEqual(sol, again) {
+			t.Errorf("%s is not deterministic with an evaluation budget", name)
+		}
+	}
+
+	//the searches stop at exactly the budget
+	stop := newStopper(context.Background(), Budget{Evaluations: 75})
+	_, pop := pso(Problem{len(etc), 0, len(etc[0])}, etc, serialOptions(PSOOptions{}), rand.New(rand.NewSource(1)), stop, newTracer(context.Background()))
+	if stop.evaluations != 75 || len(pop) != psoPopulation {
+		t.Errorf("pso made %d evaluations with a swarm of %d", stop.evaluations, len(pop))
+	}
+	stop = newStopper(context.Background(), Budget{Evaluations: 10})
+	_, pop = pso(Problem{len(etc), 0, len(etc[0])}, etc, serialOptions(PSOOptions{}), rand.New(rand.NewSource(1)), stop, newTracer(context.Background()))
+	if stop.evaluations != 10 || len(pop) != 10 {
+		t.Errorf("pso made %d evaluations with a swarm of %d, stopped while it was set up", stop.evaluations, len(pop))
+	}
+	stop = newStopper(context.Background(), Budget{Evaluations: 75})
+	simulatedAnnealing(RoundETC(etc), rand.New(rand.NewSource(1)), stop, newTracer(context.Background()))
+	if stop.evaluations != 75 {
+		t.Errorf("sa made %d evaluations", stop.evaluations)
+	}
+
+	//more evaluations never give a worse schedule than fewer, the best so far is kept
+	sa, _ := Lookup("sa")
+	short := ETCMakespan(etc, sa(context.Background(), etc, Budget{Evaluations: 100}, rand.New(rand.NewSource(1))))
+	long := ETCMakespan(etc, sa(context.Background(), etc, Budget{Evaluations: 1000}, rand.New(rand.NewSource(1))))
+	if long > short {
+		t.Errorf("sa found %v with 1000 evaluations, %v with 100", long, short)
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	etc := [][]float64{{2, 4}, {1, 3}, {3, 1}}
+	tests := []struct {
+		sol      []int
+		makespan float64
+		flowtime float64
+	}{
+		//resource 0 runs 1 then 2, resource 1 runs 1
+		{[]int{0, 0, 1}, 3, 1 + 3 + 1},
+		{[]int{1, 1, 1}, 8, 1 + 4 + 8},
+		{[]int{0, 1, 0}, 5, 2 + 5 + 3},
+	}
+	for _, tt := range tests {
+		if got := ETCMakespan(etc, tt.sol); got != tt.makespan {
+			t.Errorf("makespan of %v = %v, expected %v", tt.sol, got, tt.makespan)
+		}
+		if got := Flowtime(etc, tt.sol); got != tt.flowtime {
+			t.Errorf("flowtime of %v = %v, expected %v", tt.sol, got, tt.flowtime)
+		}
+	}
+
+	//the fastest runtimes add up to 4, which two resources need at least 2 for, but
+	//task 0 alone needs 2
+	if got := LowerBound(etc); got != 2 {
+		t.Errorf("lower bound %v, expected 2", got)
+	}
+	if got := LowerBound([][]float64{{5, 9}, {5, 9}, {5, 9}}); got != 7.5 {
+		t.Errorf("lower bound %v, expected 7.5", got)
+	}
+}
+
+func TestMakeConsistent(t *testing.T) {
+	rows := func() [][]float64 { return [][]float64{{5, 4, 3, 2, 1}, {1, 9, 0, 8, 2}} }
+
+	etc := rows()
+	MakeConsistent(etc, Consistent)
+	for _, row := range etc {
+		if !sort.Float64sAreSorted(row) {
+			t.Errorf("consistent row %v", row)
+		}
+	}
+
+	etc = rows()
+	MakeConsistent(etc, SemiConsistent)
+	if expected := [][]float64{{1, 4, 3, 2, 5}, {0, 9, 1, 8, 2}}; !reflect.DeepEqual(etc, expected) {
+		t.Errorf("semi-consistent %v, expected %v", etc, expected)
+	}
+
+	etc = rows()
+	MakeConsistent(etc, Inconsistent)
+	if !reflect.DeepEqual(etc, rows()) {
+		t.Errorf("inconsistent matrix changed to %v", etc)
+	}
+	if MakeConsistent(etc, "mostly") == nil {
+		t.Error("accepted an unknown consistency")
+	}
+}
@@ -0,0 +1,45 @@
+package scheduling
+
+import "math"
+
+// Sufferage repeatedly assigns the task that would suffer most from not getting its
+// best resource, the one with the largest gap between its earliest and second earliest
+// completion time, to the resource where it finishes first.
+func Sufferage(etc [][]float64) []int {
+	return sufferage(etc, make([]float64, len(etc[0])))
+}
+
+// sufferage is Sufferage on resources that are busy until their ready times, which it
+// moves on by the tasks it assigns.
+func sufferage(etc [][]float64, ready []float64) []int {
+	sol := make([]int, len(etc))
+	done := make([]bool, len(etc))
+	for n := 0; n < len(etc); n++ {
+		task, resource, most := -1, 0, -1.0
+		for i, row := range etc {
+			if done[i] {
+				continue
+			}
+			first, second, best := math.Inf(1), math.Inf(1), 0
+			for j, runtime := range row {
+				completion := ready[j] + runtime
+				if completion < first {
+					first, second, best = completion, first, j
+				} else if completion < second {
+					second = completion
+				}
+			}
+			//a single resource leaves nothing to suffer
+			suffers := second - first
+			if math.IsInf(second, 1) {
+				suffers = 0
+			}
+			if suffers > most {
+				task, resource, most = i, best, suffers
+			}
+		}
+		sol[task], done[task] = resource, true
+		ready[resource] += etc[task][resource]
+	}
+	return sol
+}
@@ -0,0 +1,144 @@
+package scheduling
+
+import "math"
+
+// TabuOptions configure the tabu search that polishes schedules in pipelines. The zero
+// value of a field takes the default in brackets.
+type TabuOptions struct {
+	Iterations int `json:"iterations,omitempty"` //moves made [1000]
+	Tenure     int `json:"tenure,omitempty"`     //iterations a task may not go back to a resource it left [7]
+}
+
+func (o TabuOptions) withDefaults() TabuOptions {
+	if o.Iterations <= 0 {
+		o.Iterations = 1000
+	}
+	if o.Tenure <= 0 {
+		o.Tenure = 7
+	}
+	return o
+}
+
+// tabuMove takes a task off the busiest resource, to resource to, or swaps it with
+// task other on that resource if other isn't -1.
+type tabuMove struct {
+	task, other, to int
+	makespan        float64
+	balance         float64 //change of the sum of the squared loads, lower spreads them more evenly
+}
+
+func (m tabuMove) better(than tabuMove) bool {
+	return m.makespan < than.makespan || m.makespan == than.makespan && m.balance < than.balance
+}
+
+// tabuSearch starts from the schedule start and makes the best move off the busiest
+// resource in every iteration, moving one of its tasks elsewhere or swapping it with a
+// task of another resource, even if that makes the schedule worse. A task may not go
+// back to the resource it left for opts.Tenure iterations, unless that gives the best
+// makespan so far. Every move considered counts as an evaluation; the best schedule
+// found is returned once stop says so.
+func tabuSearch(etc [][]float64, start []int, opts TabuOptions, stop *stopper, trace *tracer) []int {
+	resources := len(etc[0])
+	sol := append([]int(nil), start...)
+	loads := make([]float64, resources)
+	for i, j := range sol {
+		loads[j] += etc[i][j]
+	}
+	best := append([]int(nil), sol...)
+	bestCost := maxLoad(loads, -1, 0, -1, 0)
+	stop.evaluated()
+
+	//tabu[i][j] is the first iteration task i may move to resource j again
+	tabu := make([][]int, len(etc))
+	for i := range tabu {
+		tabu[i] = make([]int, resources)
+	}
+
+	for iter := 0; iter < opts.Iterations && !stop.stop(); iter++ {
+		critical := 0
+		for j, load := range loads {
+			if load > loads[critical] {
+				critical = j
+			}
+		}
+		chosen := tabuMove{makespan: math.Inf(1)}
+		consider := func(m tabuMove, forbidden bool) {
+			stop.evaluated()
+			if forbidden && m.makespan >= bestCost {
+				return
+			}
+			if m.better(chosen) {
+				chosen = m
+			}
+		}
+		square := func(x float64) float64 { return x * x }
+
+		stopped := false
+		for i, on := range sol {
+			if on != critical {
+				continue
+			}
+			for j := range loads {
+				if j == critical {
+					continue
+				}
+				off, onto := loads[critical]-etc[i][critical], loads[j]+etc[i][j]
+				consider(tabuMove{i, -1, j, maxLoad(loads, critical, off, j, onto),
+					square(off) + square(onto) - square(loads[critical]) - square(loads[j])}, tabu[i][j] > iter)
+			}
+			for k, j := range sol {
+				if j == critical {
+					continue
+				}
+				off, onto := loads[critical]-etc[i][critical]+etc[k][critical], loads[j]-etc[k][j]+etc[i][j]
+				consider(tabuMove{i, k, j, maxLoad(loads, critical, off, j, onto),
+					square(off) + square(onto) - square(loads[critical]) - square(loads[j])}, tabu[i][j] > iter || tabu[k][critical] > iter)
+			}
+			if stop.stop() {
+				stopped = true
+				break
+			}
+		}
+		if stopped || math.IsInf(chosen.makespan, 1) {
+			break
+		}
+
+		i, j := chosen.task, chosen.to
+		loads[critical] -= etc[i][critical]
+		loads[j] += etc[i][j]
+		sol[i] = j
+		tabu[i][critical] = iter + opts.Tenure
+		if k := chosen.other; k != -1 {
+			loads[j] -= etc[k][j]
+			loads[critical] += etc[k][critical]
+			sol[k] = critical
+			tabu[k][j] = iter + opts.Tenure
+		}
+		if chosen.makespan < bestCost {
+			bestCost = chosen.makespan
+			copy(best, sol)
+		}
+		if trace.on() {
+			trace.observe(Sample{Iteration: iter, Evaluations: stop.evaluations, Best: bestCost, Current: chosen.makespan,
+				Diversity: hamming(sol, best)})
+		}
+	}
+	return best
+}
+
+// maxLoad is the largest of the loads with those of resources a and b replaced by la
+// and lb; a and b may be -1 to replace nothing.
+func maxLoad(loads []float64, a int, la float64, b int, lb float64) float64 {
+	max := 0.0
+	for j, load := range loads {
+		if j == a {
+			load = la
+		} else if j == b {
+			load = lb
+		}
+		if load > max {
+			max = load
+		}
+	}
+	return max
+}
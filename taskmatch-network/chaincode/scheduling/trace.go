@@ -0,0 +1,151 @@
+package scheduling
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Sample is the state of a search after one of its iterations.
+type Sample struct {
+	Iteration   int     `json:"iteration"`
+	Island      int     `json:"island"`      //the island of PSO with islands, 0 otherwise
+	Evaluations int     `json:"evaluations"` //makespans computed so far
+	Seconds     float64 `json:"seconds"`     //wall-clock time since the search started
+	Best        float64 `json:"best"`        //makespan of the best schedule found so far
+	Current     float64 `json:"current"`     //sa: makespan of the current schedule, pso: mean makespan of the particles
+	Temperature float64 `json:"temperature,omitempty"`
+	Inertia     float64 `json:"inertia,omitempty"`
+	//sa: share of the tasks the current schedule assigns elsewhere than the best one,
+	//pso: mean distance of the particles from their centroid, per task
+	Diversity float64 `json:"diversity"`
+}
+
+// Observer is told how a search is going after each of its iterations. The searches of
+// sa and the PSO swarms report to the observer of their context; min-min and max-min
+// build their schedule in one go and report nothing.
+type Observer interface {
+	Observe(s Sample)
+}
+
+// ObserverFunc is a function used as an Observer.
+type ObserverFunc func(s Sample)
+
+// Observe calls f.
+func (f ObserverFunc) Observe(s Sample) {
+	f(s)
+}
+
+type observerKey struct{}
+
+// WithObserver returns a copy of ctx that makes the schedulers it is passed to report
+// to obs. Islands of PSO run concurrently, but obs is never called by two of them at
+// the same time.
+func WithObserver(ctx context.Context, obs Observer) context.Context {
+	return context.WithValue(ctx, observerKey{}, obs)
+}
+
+// tracer passes the samples of a search to the observer of its context, if there is one.
+type tracer struct {
+	observer Observer
+	start    time.Time
+	mu       sync.Mutex
+}
+
+func newTracer(ctx context.Context) *tracer {
+	obs, _ := ctx.Value(observerKey{}).(Observer)
+	return &tracer{observer: obs, start: time.Now()}
+}
+
+// on is true if somebody listens, so that a search only works out samples if they are used.
+func (t *tracer) on() bool {
+	return t.observer != nil
+}
+
+func (t *tracer) observe(s Sample) {
+	if t.observer == nil {
+		return
+	}
+	s.Seconds = time.Since(t.start).Seconds()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.observer.Observe(s)
+}
+
+// hamming is the share of the tasks two schedules assign differently.
+func hamming(a, b []int) float64 {
+	differ := 0
+	for i := range a {
+		if a[i] != b[i] {
+			differ++
+		}
+	}
+	return float64(differ) / float64(len(a))
+}
+
+// spread is the mean distance of the positions from their centroid, divided by the
+// square root of the number of dimensions so that it doesn't grow with the tasks.
+func spread(positions [][]float64) float64 {
+	if len(positions) == 0 {
+		return 0
+	}
+	centroid := make([]float64, len(positions[0]))
+	for _, x := range positions {
+		for d := range x {
+			centroid[d] += x[d] / float64(len(positions))
+		}
+	}
+	total := 0.0
+	for _, x := range positions {
+		sum := 0.0
+		for d := range x {
+			sum += (x[d] - centroid[d]) * (x[d] - centroid[d])
+		}
+		total += math.Sqrt(sum / float64(len(x)))
+	}
+	return total / float64(len(positions))
+}
+
+// Trace is an Observer that keeps every sample, to write them out once the search is done.
+type Trace struct {
+	Samples []Sample
+}
+
+// Observe keeps s.
+func (t *Trace) Observe(s Sample) {
+	t.Samples = append(t.Samples, s)
+}
+
+// traceHeader are the columns of a trace in CSV.
+var traceHeader = []string{"iteration", "island", "evaluations", "seconds", "best", "current", "temperature", "inertia", "diversity"}
+
+// WriteCSV writes the samples as CSV with a header row, a sample per row.
+func (t *Trace) WriteCSV(w io.Writer) error {
+	out := csv.NewWriter(w)
+	out.Write(traceHeader)
+	format := func(x float64) string {
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	}
+	for _, s := range t.Samples {
+		out.Write([]string{strconv.Itoa(s.Iteration), strconv.Itoa(s.Island), strconv.Itoa(s.Evaluations), format(s.Seconds),
+			format(s.Best), format(s.Current), format(s.Temperature), format(s.Inertia), format(s.Diversity)})
+	}
+	out.Flush()
+	return out.Error()
+}
+
+// WriteJSON writes the samples as a JSON array.
+func (t *Trace) WriteJSON(w io.Writer) error {
+	samples := t.Samples
+	if samples == nil {
+		samples = []Sample{}
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(samples)
+}
@@ -0,0 +1,107 @@
+package scheduling
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func traceOf(t *testing.T, name string, etc [][]float64) *Trace {
+	t.Helper()
+	trace := new(Trace)
+	f, err := Lookup(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f(WithObserver(context.Background(), trace), etc, Budget{}, rand.New(rand.NewSource(1)))
+	return trace
+}
+
+// checkConvergence fails unless the best makespan never gets worse and the evaluations
+// only grow, island by island.
+func checkConvergence(t *testing.T, name string, samples []Sample) {
+	t.Helper()
+	last := map[int]Sample{}
+	for _, s := range samples {
+		if prev, ok := last[s.Island]; ok {
+			if s.Best > prev.Best || s.Evaluations <= prev.Evaluations || s.Iteration != prev.Iteration+1 || s.Seconds < prev.Seconds {
+				t.Fatalf("%s: sample %+v after %+v", name, s, prev)
+			}
+		}
+		last[s.Island] = s
+	}
+}
+
+func TestTraces(t *testing.T) {
+	etc := GenerateETC(rand.New(rand.NewSource(1)), 30, 4, "hi", "lo")
+
+	sa := traceOf(t, "sa", etc)
+	//the temperature falls from 10000 below 1 by 0.3% per step
+	if len(sa.Samples) < 3000 || sa.Samples[0].Temperature != 10000 || sa.Samples[len(sa.Samples)-1].Temperature < 1 {
+		t.Fatalf("sa reported %d samples, the first %+v", len(sa.Samples), sa.Samples[0])
+	}
+	for _, s := range sa.Samples {
+		if s.Diversity < 0 || s.Diversity > 1 || s.Inertia != 0 || s.Best > s.Current {
+			t.Fatalf("sa sample %+v", s)
+		}
+	}
+	checkConvergence(t, "sa", sa.Samples)
+
+	swarm := traceOf(t, "pso", etc)
+	if len(swarm.Samples) != psoIterations || swarm.Samples[0].Inertia != psoInertia || swarm.Samples[0].Diversity <= 0 {
+		t.Fatalf("pso reported %d samples, the first %+v", len(swarm.Samples), swarm.Samples[0])
+	}
+	checkConvergence(t, "pso", swarm.Samples)
+
+	islands := new(Trace)
+	PSO(WithObserver(context.Background(), islands), etc, PSOOptions{Iterations: 12, Population: 5, Islands: 3, Migration: 4}, Budget{}, rand.New(rand.NewSource(1)))
+	perIsland := map[int]int{}
+	for _, s := range islands.Samples {
+		perIsland[s.Island]++
+	}
+	if !reflect.DeepEqual(perIsland, map[int]int{0: 12, 1: 12, 2: 12}) {
+		t.Fatalf("samples per island %v", perIsland)
+	}
+	checkConvergence(t, "pso-islands", islands.Samples)
+
+	if minmin := traceOf(t, "min-min", etc); len(minmin.Samples) != 0 {
+		t.Fatalf("min-min reported %d samples", len(minmin.Samples))
+	}
+}
+
+func TestWriteTrace(t *testing.T) {
+	trace := &Trace{[]Sample{
+		{Iteration: 0, Evaluations: 1, Seconds: 0.5, Best: 10, Current: 12, Temperature: 100, Diversity: 0.25},
+		{Iteration: 1, Island: 2, Evaluations: 2, Seconds: 1, Best: 9, Current: 9, Inertia: 0.7},
+	}}
+	var out bytes.Buffer
+	if err := trace.WriteCSV(&out); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(&out).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := [][]string{traceHeader, {"0", "0", "1", "0.5", "10", "12", "100", "0", "0.25"}, {"1", "2", "2", "1", "9", "9", "0", "0.7", "0"}}
+	if !reflect.DeepEqual(rows, expected) {
+		t.Errorf("CSV rows %v", rows)
+	}
+
+	out.Reset()
+	if err := trace.WriteJSON(&out); err != nil {
+		t.Fatal(err)
+	}
+	var samples []Sample
+	if err := json.Unmarshal(out.Bytes(), &samples); err != nil || !reflect.DeepEqual(samples, trace.Samples) {
+		t.Errorf("JSON round trip gave %+v, %v", samples, err)
+	}
+	out.Reset()
+	new(Trace).WriteJSON(&out)
+	if out.String() != "[]\n" {
+		t.Errorf("empty trace is %q", out.String())
+	}
+}
@@ -0,0 +1,470 @@
+// Package solver is an agent that competes for the jobs of the taskmatching chaincode on
+// behalf of one of its solvers, so nobody has to call calculateTaskMatching by hand.
+//
+// When a job is created the agent reads its runtimes, runs its schedulers for at most a
+// time budget, cut short by the deadline of the job or its commit phase, and hands in
+// the best assignment they found: with submitSolution, or with commitSolution and, once
+// the commit phase is over, revealSolution. It hears of new jobs from jobCreated events
+// and by polling its solver record on the ledger.
+//
+// The ledger decides what is left to do: before every transaction the agent reads the
+// status of its solver, so duplicate events, a transaction that went through although
+// the client saw an error, or a restart never lead to a second submission. Failed
+// transactions are retried with exponential backoff. An assignment is kept with the hash
+// of the runtimes it was found for, when tasks are added to the job it is solved again.
+package solver
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	mathrand "math/rand"
+	"os"
+	"time"
+
+	"github.com/chaincode/scheduling"
+)
+
+// Contract is the part of a gateway contract the agent needs, *gateway.Contract of
+// fabric-sdk-go has these methods.
+type Contract interface {
+	EvaluateTransaction(name string, args ...string) ([]byte, error)
+	SubmitTransaction(name string, args ...string) ([]byte, error)
+}
+
+// Config configures an agent. Only Solver has to be set.
+type Config struct {
+	Solver       string        //the solver the agent submits for, p1, p2 or p3
+	Schedulers   []string      //registered schedulers to run, all of them if empty
+	Budget       time.Duration //time the schedulers get for a job, at most until its deadline, 10 seconds if 0
+	PollInterval time.Duration //how often the ledger is checked for jobs, 10 seconds if 0
+	Retries      int           //how often a failed transaction is tried again
+	Backoff      time.Duration //wait before the first retry, doubled for every further one, 1 second if 0
+	MaxBackoff   time.Duration //longest wait between retries, 1 minute if 0
+	Seed         int64         //seed of the schedulers
+	StateFile    string        //keeps committed assignments across restarts, in memory only if empty
+
+	Now func() time.Time //clock the deadlines of the jobs are compared with, time.Now if nil
+	Log *log.Logger      //where the agent reports what it does, the standard logger if nil
+}
+
+// job and solverRecord are the fields of the ledger records the agent reads.
+type job struct {
+	Runtimes       string `json:"runtimes"`
+	State          string `json:"state"`
+	Created        int64  `json:"created"`
+	Deadline       int64  `json:"deadline"`
+	CommitDeadline int64  `json:"commitDeadline"`
+	Collection     string `json:"collection"`
+	RuntimesHash   string `json:"runtimesHash"` //changes when tasks are added to the job
+}
+
+type solverRecord struct {
+	Status string `json:"status"`
+	Job    string `json:"job"`
+	Owner  string `json:"owner"` //MSP id of the org of the solver, part of the commitment
+}
+
+// attempt is what the agent handed in, or is trying to, for a job.
+type attempt struct {
+	Assignment   string    `json:"assignment"` //JSON, exactly as submitted or committed
+	Algorithm    string    `json:"algorithm"`
+	Makespan     int       `json:"makespan"`
+	RuntimesHash string    `json:"runtimesHash"`       //of the job when the attempt was made
+	Salt         string    `json:"salt,omitempty"`     //only for jobs with a commit phase
+	RevealAt     int64     `json:"revealAt,omitempty"` //commit deadline of the job
+	Failures     int       `json:"failures"`
+	Next         time.Time `json:"next"` //no transaction before this time
+	Finished     bool      `json:"finished"`
+}
+
+// Agent competes for jobs on behalf of one solver. Its methods must not be called
+// concurrently.
+type Agent struct {
+	contract Contract
+	config   Config
+	attempts map[string]*attempt //by job id
+}
+
+// New returns an agent for the solver of config that uses contract.
+func New(contract Contract, config Config) (*Agent, error) {
+	if config.Solver == "" {
+		return nil, fmt.Errorf("the agent needs a solver")
+	}
+	if len(config.Schedulers) == 0 {
+		config.Schedulers = scheduling.Names()
+	}
+	for _, name := range config.Schedulers {
+		if _, err := scheduling.Lookup(name); err != nil {
+			return nil, err
+		}
+	}
+	if config.Budget <= 0 {
+		config.Budget = 10 * time.Second
+	}
+	if config.PollInterval <= 0 {
+		config.PollInterval = 10 * time.Second
+	}
+	if config.Backoff <= 0 {
+		config.Backoff = time.Second
+	}
+	if config.MaxBackoff <= 0 {
+		config.MaxBackoff = time.Minute
+	}
+	if config.Now == nil {
+		config.Now = time.Now
+	}
+	if config.Log == nil {
+		config.Log = log.New(os.Stderr, "", log.LstdFlags)
+	}
+
+	a := &Agent{contract, config, make(map[string]*attempt)}
+	if config.StateFile != "" {
+		data, err := ioutil.ReadFile(config.StateFile)
+		if err == nil {
+			err = json.Unmarshal(data, &a.attempts)
+		}
+		if err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to read the state file: %s", err)
+		}
+	}
+	return a, nil
+}
+
+// Run polls the ledger and works on the jobs sent on jobs, e.g. from jobCreated events,
+// until ctx is done. jobs may be nil to only poll.
+func (a *Agent) Run(ctx context.Context, jobs <-chan string) error {
+	ticker := time.NewTicker(a.config.PollInterval)
+	defer ticker.Stop()
+	poll := true
+	for {
+		if poll {
+			a.report(a.Poll(ctx))
+		}
+		wake := time.NewTimer(a.untilNextAttempt())
+		select {
+		case <-ctx.Done():
+			wake.Stop()
+			return ctx.Err()
+		case jobID, ok := <-jobs:
+			if ok {
+				a.report(a.Work(ctx, jobID))
+			} else {
+				jobs = nil
+			}
+			poll = false
+		case <-ticker.C:
+			poll = true
+		case <-wake.C:
+			poll = true
+		}
+		wake.Stop()
+	}
+}
+
+// Poll works on the job the solver currently takes part in, if any.
+func (a *Agent) Poll(ctx context.Context) error {
+	rec, err := a.solverRecord()
+	if err != nil || rec.Job == "" {
+		return err
+	}
+	return a.work(ctx, rec.Job, rec)
+}
+
+// Work hands in an assignment for the job, or reveals it, if the solver still has to and
+// any wait after a failed transaction is over. Calling it again is harmless.
+func (a *Agent) Work(ctx context.Context, jobID string) error {
+	rec, err := a.solverRecord()
+	if err != nil {
+		return err
+	}
+	return a.work(ctx, jobID, rec)
+}
+
+func (a *Agent) work(ctx context.Context, jobID string, rec *solverRecord) error {
+	//the solver only ever works on one job, older attempts are of no use anymore
+	for id := range a.attempts {
+		if id != rec.Job {
+			delete(a.attempts, id)
+		}
+	}
+	if rec.Job != jobID {
+		return nil
+	}
+
+	at := a.attempts[jobID]
+	if rec.Status != "waiting" && rec.Status != "committed" {
+		if at != nil && !at.Finished {
+			a.config.Log.Printf("%s handed in a makespan of %d (%s) for job %s", a.config.Solver, at.Makespan, at.Algorithm, jobID)
+			at.Finished = true
+			return a.save()
+		}
+		return nil
+	}
+	now := a.config.Now()
+	if at != nil && now.Before(at.Next) {
+		return nil
+	}
+
+	j, err := a.job(jobID)
+	if err != nil {
+		return err
+	}
+	//an assignment for the job before tasks were added to it is of no use, solve it again
+	if at != nil && rec.Status == "waiting" && at.RuntimesHash != j.RuntimesHash {
+		delete(a.attempts, jobID)
+		at = nil
+	}
+	if at != nil && at.Finished {
+		return nil
+	}
+	if j.State != "OPEN" && j.State != "SOLVING" {
+		return nil
+	}
+	if now.Unix() > j.Deadline {
+		return a.giveUp(jobID, j, "its deadline has passed")
+	}
+	commitPhase := j.CommitDeadline != j.Created
+
+	if rec.Status == "committed" {
+		if at == nil || at.Salt == "" {
+			return a.giveUp(jobID, j, "the committed assignment is lost")
+		}
+		if now.Unix() < j.CommitDeadline {
+			return nil
+		}
+		_, err := a.contract.SubmitTransaction("revealSolution", a.config.Solver, jobID, at.Assignment, at.Salt, at.Algorithm)
+		return a.settle(jobID, at, err)
+	}
+
+	if commitPhase && now.Unix() >= j.CommitDeadline {
+		return a.giveUp(jobID, j, "its commit phase is over")
+	}
+	if at == nil {
+		//the assignment has to be on the ledger before the commit phase or the job ends
+		deadline := j.Deadline
+		if commitPhase {
+			deadline = j.CommitDeadline
+		}
+		budget := a.config.Budget
+		if left := time.Unix(deadline, 0).Sub(now); left < budget {
+			budget = left
+		}
+		if budget <= 0 {
+			return a.giveUp(jobID, j, "there is no time left to solve it")
+		}
+		if at, err = a.solve(ctx, jobID, j, budget); err != nil {
+			return err
+		}
+		//a search cut short by a shutdown is not handed in, the next run solves again
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		at.RuntimesHash = j.RuntimesHash
+		if commitPhase {
+			if at.Salt, err = newSalt(); err != nil {
+				return err
+			}
+			at.RevealAt = j.CommitDeadline
+		}
+		a.attempts[jobID] = at
+		//the salt has to be kept before the commitment is on the ledger
+		if err := a.save(); err != nil {
+			return err
+		}
+	}
+
+	if commitPhase {
+		sum := sha256.Sum256([]byte(a.config.Solver + "|" + jobID + "|" + rec.Owner + "|" + at.Salt + "|" + at.Assignment))
+		_, err = a.contract.SubmitTransaction("commitSolution", a.config.Solver, jobID, hex.EncodeToString(sum[:]))
+	} else {
+		_, err = a.contract.SubmitTransaction("submitSolution", a.config.Solver, jobID, at.Assignment, at.Algorithm)
+	}
+	return a.settle(jobID, at, err)
+}
+
+// settle records the outcome of a transaction for the attempt. The transaction may have
+// gone through although err is set, the next call of work reads the ledger before
+// trying again.
+func (a *Agent) settle(jobID string, at *attempt, err error) error {
+	if err == nil {
+		at.Failures = 0
+		at.Next = time.Time{}
+		return a.save()
+	}
+	at.Failures++
+	if at.Failures > a.config.Retries {
+		at.Finished = true
+		a.save()
+		return fmt.Errorf("giving up on job %s after %d attempts: %s", jobID, at.Failures, err)
+	}
+	wait := a.config.Backoff << uint(at.Failures-1)
+	if wait > a.config.MaxBackoff || wait <= 0 {
+		wait = a.config.MaxBackoff
+	}
+	at.Next = a.config.Now().Add(wait)
+	if err := a.save(); err != nil {
+		return err
+	}
+	return fmt.Errorf("job %s, retrying in %s: %s", jobID, wait, err)
+}
+
+func (a *Agent) giveUp(jobID string, j *job, reason string) error {
+	at := a.attempts[jobID]
+	if at == nil {
+		at = new(attempt)
+		a.attempts[jobID] = at
+	}
+	at.RuntimesHash = j.RuntimesHash
+	at.Finished = true
+	a.config.Log.Printf("%s gives up on job %s, %s", a.config.Solver, jobID, reason)
+	return a.save()
+}
+
+// untilNextAttempt is how long Run may sleep before a retry or a reveal is due.
+func (a *Agent) untilNextAttempt() time.Duration {
+	next := a.config.PollInterval
+	now := a.config.Now()
+	for _, at := range a.attempts {
+		if at.Finished {
+			continue
+		}
+		due := at.Next
+		if at.Salt != "" && at.Failures == 0 {
+			due = time.Unix(at.RevealAt, 0)
+		}
+		if wait := due.Sub(now); wait < next {
+			next = wait
+		}
+	}
+	if next < 0 {
+		return 0
+	}
+	return next
+}
+
+// solve runs the schedulers on the runtimes of the job for at most budget, or until ctx
+// is done, and returns the best assignment they found.
+func (a *Agent) solve(ctx context.Context, jobID string, j *job, budget time.Duration) (*attempt, error) {
+	runtimes := j.Runtimes
+	if j.Collection != "" {
+		payload, err := a.contract.EvaluateTransaction("readJobRuntimes", jobID)
+		if err != nil {
+			return nil, err
+		}
+		runtimes = string(payload)
+	}
+	var matrix [][]int
+	if err := json.Unmarshal([]byte(runtimes), &matrix); err != nil || len(matrix) == 0 {
+		return nil, fmt.Errorf("job %s has no runtimes to solve", jobID)
+	}
+	etc := make([][]float64, len(matrix))
+	for i := range matrix {
+		etc[i] = make([]float64, len(matrix[i]))
+		for j := range matrix[i] {
+			etc[i][j] = float64(matrix[i][j])
+		}
+	}
+
+	type outcome struct {
+		name string
+		sol  []int
+	}
+	results := make(chan outcome, len(a.config.Schedulers))
+	for i, name := range a.config.Schedulers {
+		f, _ := scheduling.Lookup(name)
+		rng := mathrand.New(mathrand.NewSource(a.config.Seed + int64(i)))
+		go func(name string, f scheduling.Func, rng *mathrand.Rand) {
+			results <- outcome{name, f(ctx, etc, scheduling.Budget{Time: budget}, rng)}
+		}(name, f, rng)
+	}
+
+	var best *attempt
+	for range a.config.Schedulers {
+		o := <-results
+		if !validAssignment(matrix, o.sol) {
+			a.config.Log.Printf("%s returned an invalid assignment for job %s", o.name, jobID)
+			continue
+		}
+		makespan := scheduling.Makespan(matrix, o.sol)
+		if best == nil || makespan < best.Makespan {
+			assignment, _ := json.Marshal(o.sol)
+			best = &attempt{Assignment: string(assignment), Algorithm: o.name, Makespan: makespan}
+		}
+	}
+	if best == nil {
+		return nil, fmt.Errorf("no scheduler found a valid assignment for job %s", jobID)
+	}
+	return best, nil
+}
+
+func validAssignment(matrix [][]int, sol []int) bool {
+	if len(sol) != len(matrix) {
+		return false
+	}
+	for i, resource := range sol {
+		if resource < 0 || resource >= len(matrix[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (a *Agent) solverRecord() (*solverRecord, error) {
+	payload, err := a.contract.EvaluateTransaction("readTaskMatching", "solver", a.config.Solver)
+	if err != nil {
+		return nil, err
+	}
+	rec := new(solverRecord)
+	if err := json.Unmarshal(payload, rec); err != nil {
+		return nil, fmt.Errorf("failed to read solver %s: %s", a.config.Solver, err)
+	}
+	return rec, nil
+}
+
+func (a *Agent) job(jobID string) (*job, error) {
+	payload, err := a.contract.EvaluateTransaction("readTaskMatching", jobID)
+	if err != nil {
+		return nil, err
+	}
+	j := new(job)
+	if err := json.Unmarshal(payload, j); err != nil {
+		return nil, fmt.Errorf("failed to read job %s: %s", jobID, err)
+	}
+	return j, nil
+}
+
+// save writes the attempts to the state file, if there is one.
+func (a *Agent) save() error {
+	if a.config.StateFile == "" {
+		return nil
+	}
+	data, err := json.Marshal(a.attempts)
+	if err != nil {
+		return err
+	}
+	tmp := a.config.StateFile + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, a.config.StateFile)
+}
+
+func (a *Agent) report(err error) {
+	if err != nil {
+		a.config.Log.Printf("%s: %s", a.config.Solver, err)
+	}
+}
+
+func newSalt() (string, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(salt), nil
+}
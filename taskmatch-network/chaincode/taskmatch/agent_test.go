@@ -0,0 +1,257 @@
+package taskmatch
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/chaincode/memledger"
+	"github.com/chaincode/solver"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+/*** The solver daemon of package solver against the chaincode on the in-memory ledger ***/
+
+// ledgerContract sends the transactions of an agent to the ledger as an org. Submissions
+// can be made to fail, before or after they reach the ledger.
+type ledgerContract struct {
+	ledger      *memledger.Ledger
+	mspID       string
+	submits     int //submitted transactions, failed ones included
+	failBefore  int //fail this many submissions without sending them
+	failAfter   int //report this many submissions as failed after they went through
+	lastSubmits []string
+}
+
+func (c *ledgerContract) EvaluateTransaction(name string, args ...string) ([]byte, error) {
+	res := c.ledger.Query(c.mspID, append([]string{name}, args...)...)
+	if res.Status != shim.OK {
+		return nil, errors.New(res.Message)
+	}
+	return res.Payload, nil
+}
+
+func (c *ledgerContract) SubmitTransaction(name string, args ...string) ([]byte, error) {
+	c.submits++
+	c.lastSubmits = append(c.lastSubmits, name)
+	if c.failBefore > 0 {
+		c.failBefore--
+		return nil, errors.New("connection refused")
+	}
+	res := c.ledger.Invoke(c.mspID, append([]string{name}, args...)...)
+	if res.Status != shim.OK {
+		return nil, errors.New(res.Message)
+	}
+	if c.failAfter > 0 {
+		c.failAfter--
+		return nil, errors.New("timed out waiting for the commit")
+	}
+	return res.Payload, nil
+}
+
+func newTestAgent(t *testing.T, contract *ledgerContract, peer string, stateFile string) *solver.Agent {
+	t.Helper()
+	agent, err := solver.New(contract, solver.Config{
+		Solver:     peer,
+		Schedulers: []string{"min-min", "max-min"},
+		Budget:     5 * time.Second,
+		Retries:    2,
+		Backoff:    10 * time.Second,
+		StateFile:  stateFile,
+		Now:        contract.ledger.Now,
+		Log:        log.New(ioutil.Discard, "", 0),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return agent
+}
+
+func TestAgentSubmitsOnce(t *testing.T) {
+	ledger := newTestLedger(t)
+	contract := &ledgerContract{ledger: ledger, mspID: "Org1MSP"}
+	agent := newTestAgent(t, contract, "p1", "")
+
+	//nothing to do before a job is created
+	if err := agent.Poll(context.Background()); err != nil || contract.submits != 0 {
+		t.Fatalf("poll without a job: %v, %d submissions", err, contract.submits)
+	}
+
+	mustInvoke(t, ledger, "Org1MSP", "createTaskMatching", "work", testRuntimes)
+	for i := 0; i < 3; i++ { //the event arrives twice and the ledger is polled
+		if err := agent.Work(context.Background(), "work"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := agent.Poll(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if contract.submits != 1 {
+		t.Fatalf("%d submissions %v, expected one", contract.submits, contract.lastSubmits)
+	}
+
+	//max-min beats min-min on these runtimes
+	var peer Peer
+	readRecord(t, ledger, &peer, "solver", "p1")
+	if peer.Status != "done" || peer.Runtime != 7 || peer.Algorithm != "max-min" {
+		t.Fatalf("p1 after the agent submitted: %+v", peer)
+	}
+}
+
+func TestAgentRetries(t *testing.T) {
+	ledger := newTestLedger(t)
+	mustInvoke(t, ledger, "Org1MSP", "createTaskMatching", "work", testRuntimes)
+
+	//the first submission doesn't reach the ledger, the agent waits before trying again
+	contract := &ledgerContract{ledger: ledger, mspID: "Org1MSP", failBefore: 1}
+	agent := newTestAgent(t, contract, "p1", "")
+	if err := agent.Work(context.Background(), "work"); err == nil {
+		t.Fatal("the failed submission was not reported")
+	}
+	agent.Work(context.Background(), "work")
+	if contract.submits != 1 {
+		t.Fatalf("retried before the backoff, %d submissions", contract.submits)
+	}
+	ledger.Advance(10 * time.Second)
+	if err := agent.Work(context.Background(), "work"); err != nil || contract.submits != 2 {
+		t.Fatalf("retry after the backoff: %v, %d submissions", err, contract.submits)
+	}
+
+	//the submission of p2 goes through but the client sees an error, it must not be resent
+	contract = &ledgerContract{ledger: ledger, mspID: "Org2MSP", failAfter: 1}
+	agent = newTestAgent(t, contract, "p2", "")
+	agent.Work(context.Background(), "work")
+	ledger.Advance(time.Minute)
+	if err := agent.Work(context.Background(), "work"); err != nil || contract.submits != 1 {
+		t.Fatalf("after a lost response: %v, %d submissions", err, contract.submits)
+	}
+
+	//p3 never gets through and gives up after the retries
+	contract = &ledgerContract{ledger: ledger, mspID: "Org3MSP", failBefore: 10}
+	agent = newTestAgent(t, contract, "p3", "")
+	var err error
+	for i := 0; i < 5; i++ {
+		err = agent.Work(context.Background(), "work")
+		ledger.Advance(time.Minute)
+	}
+	if contract.submits != 3 || err != nil {
+		t.Fatalf("%d submissions, expected the first and 2 retries, last error %v", contract.submits, err)
+	}
+}
+
+func TestAgentTasksAdded(t *testing.T) {
+	ledger := newTestLedger(t)
+	mustInvoke(t, ledger, "Org1MSP", "createTaskMatching", "work", testRuntimes)
+
+	//the assignment for three tasks doesn't get through before a task is added
+	contract := &ledgerContract{ledger: ledger, mspID: "Org1MSP", failBefore: 1}
+	agent := newTestAgent(t, contract, "p1", "")
+	if err := agent.Work(context.Background(), "work"); err == nil {
+		t.Fatal("the failed submission was not reported")
+	}
+	mustInvoke(t, ledger, "Org1MSP", "addTasks", "work", "[[1,1,1]]")
+	ledger.Advance(10 * time.Second)
+	if err := agent.Poll(context.Background()); err != nil || contract.submits != 2 {
+		t.Fatalf("after the tasks were added: %v, %d submissions", err, contract.submits)
+	}
+
+	var peer Peer
+	readRecord(t, ledger, &peer, "solver", "p1")
+	if peer.Status != "done" || len(peer.Solution) != 4 {
+		t.Fatalf("p1 handed in %v for the grown job", peer.Solution)
+	}
+}
+
+func TestAgentCancelled(t *testing.T) {
+	ledger := newTestLedger(t)
+	mustInvoke(t, ledger, "Org1MSP", "createTaskMatching", "work", testRuntimes)
+	contract := &ledgerContract{ledger: ledger, mspID: "Org1MSP"}
+	agent := newTestAgent(t, contract, "p1", "")
+
+	//an assignment found while shutting down is not handed in
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := agent.Work(ctx, "work"); err != context.Canceled || contract.submits != 0 {
+		t.Fatalf("cancelled work: %v, %d submissions", err, contract.submits)
+	}
+	if err := agent.Work(context.Background(), "work"); err != nil || contract.submits != 1 {
+		t.Fatalf("work after the cancellation: %v, %d submissions", err, contract.submits)
+	}
+}
+
+func TestAgentCommitReveal(t *testing.T) {
+	ledger := newTestLedger(t)
+	mustInvoke(t, ledger, "Org1MSP", "createTaskMatching", "work", testRuntimes, "60", "30")
+	stateFile := filepath.Join(t.TempDir(), "p1.json")
+
+	contract := &ledgerContract{ledger: ledger, mspID: "Org1MSP"}
+	agent := newTestAgent(t, contract, "p1", stateFile)
+	if err := agent.Poll(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	var peer Peer
+	readRecord(t, ledger, &peer, "solver", "p1")
+	if peer.Status != "committed" {
+		t.Fatalf("p1 is %s after the agent ran in the commit phase", peer.Status)
+	}
+
+	//a restarted agent still knows the salt and reveals once the commit phase is over
+	agent = newTestAgent(t, contract, "p1", stateFile)
+	agent.Poll(context.Background())
+	if contract.submits != 1 {
+		t.Fatalf("%v before the commit deadline", contract.lastSubmits)
+	}
+	ledger.Advance(30 * time.Second)
+	if err := agent.Poll(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	readRecord(t, ledger, &peer, "solver", "p1")
+	if peer.Status != "done" || peer.Runtime != 7 || peer.Algorithm != "max-min" {
+		t.Fatalf("p1 after the reveal: %+v", peer)
+	}
+}
+
+func TestAgentRun(t *testing.T) {
+	ledger := newTestLedger(t)
+	events := ledger.Subscribe()
+	defer ledger.Unsubscribe(events)
+
+	//the agents hear of the job from its event and close it between them
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stopped := make(chan error, len(peerArray))
+	var feeds []chan string
+	for _, peer := range peerArray {
+		jobs := make(chan string, 1)
+		feeds = append(feeds, jobs)
+		agent := newTestAgent(t, &ledgerContract{ledger: ledger, mspID: solverOrgs[peer]}, peer, "")
+		go func() { stopped <- agent.Run(ctx, jobs) }()
+	}
+
+	mustInvoke(t, ledger, "Org1MSP", "createTaskMatching", "work", testRuntimes)
+	for event := range events {
+		if event.Name == jobCreatedEvent {
+			for _, jobs := range feeds {
+				jobs <- "work"
+			}
+		} else if event.Name == jobFinishedEvent {
+			break
+		}
+	}
+	cancel()
+	for range peerArray {
+		if err := <-stopped; err != context.Canceled {
+			t.Fatal(err)
+		}
+	}
+
+	var job TaskMatching
+	readRecord(t, ledger, &job, "work")
+	if job.State != jobClosed || job.BestRuntime != 7 {
+		t.Fatalf("job %s with best makespan %d, expected CLOSED with 7", job.State, job.BestRuntime)
+	}
+}
@@ -0,0 +1,63 @@
+package taskmatch
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/chaincode/scheduling"
+)
+
+func TestAssign(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for n := 0; n < 200; n++ {
+		matrix := make([][]int, 1+r.Intn(12))
+		original := make([][]int, len(matrix))
+		resources := 1 + r.Intn(6)
+		for i := range matrix {
+			matrix[i] = make([]int, resources)
+			for j := range matrix[i] {
+				matrix[i][j] = r.Intn(5000)
+			}
+			original[i] = append([]int(nil), matrix[i]...)
+		}
+
+		//p1 and p2 hand in valid schedules with their real makespan, p3 sits out
+		for _, peer := range []string{"p1", "p2"} {
+			sol, runtime := Assign(matrix, peer)
+			if checkAssignment(original, sol) != nil || runtime != scheduling.Makespan(original, sol) {
+				t.Fatalf("%s solved %v with %v, makespan %d", peer, original, sol, runtime)
+			}
+		}
+		if _, runtime := Assign(matrix, "p3"); runtime != -1 {
+			t.Fatalf("p3 claimed makespan %d", runtime)
+		}
+		if !reflect.DeepEqual(matrix, original) {
+			t.Fatalf("Assign changed the runtimes to %v", matrix)
+		}
+	}
+}
+
+func TestParseMatrix(t *testing.T) {
+	tests := []struct {
+		input string
+		valid bool
+	}{
+		{"[[1,2,3],[4,5,6],[7,8,9]]", true},
+		{"[[0]]", true},
+		{"", false},
+		{"[]", false},
+		{"[[]]", false},
+		{"[[1,2],[3]]", false},
+		{"[[1,-2]]", false},
+		{"[[1.5]]", false},
+		{"[[1,2]", false},
+		{"[[3000000000]]", false},
+	}
+	for _, tt := range tests {
+		_, err := parseMatrix(tt.input)
+		if (err == nil) != tt.valid {
+			t.Errorf("parseMatrix(%q) returned %v", tt.input, err)
+		}
+	}
+}
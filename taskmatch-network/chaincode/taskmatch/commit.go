@@ -0,0 +1,196 @@
+package taskmatch
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/chaincode/scheduling"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	pb "github.com/hyperledger/fabric/protos/peer"
+)
+
+/**************************************************
+ **          Commit-Reveal Submissions          **
+**************************************************/
+
+// A job created with a commit timeout is solved in two phases. Until the commit deadline
+// solvers only hand in commitSolution(peer, job, hash) where
+//
+//	hash = hex(sha256(peer|job|msp|salt|assignment))
+//
+// with msp the MSP id of the org the solver belongs to, and assignment the JSON array of
+// resource indices, exactly as it will be revealed. Binding the hash to the solver, the
+// job and the org keeps a commitment from being replayed for another solver or job.
+// After the commit deadline, and before the job deadline, they call
+// revealSolution(peer, job, assignment, salt), optionally followed by the algorithm the
+// assignment was found with. Nothing readable is on the ledger while
+// commitments are still accepted, so a late solver can't copy and tweak another's result.
+
+// ============================================================
+// commitSolution - record the hash of a solver's assignment
+// ============================================================
+func (t *SimpleChaincode) commitSolution(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	//  0     1      2
+	//peer  job   hash
+	if len(args) != 3 {
+		return shim.Error("Incorrect number of arguments. Expecting peer, job id and hash")
+	}
+	peerID, jobID, hash := args[0], args[1], args[2]
+
+	if _, err := hex.DecodeString(hash); err != nil || len(hash) != sha256.Size*2 {
+		return shim.Error("The hash must be a hex encoded sha256 digest")
+	}
+
+	job, err := getJob(stub, jobID)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	now, err := txTime(stub)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	if job.State != jobOpen && job.State != jobSolving {
+		return shim.Error("Job " + jobID + " is " + job.State)
+	}
+	if now >= job.CommitDeadline {
+		return shim.Error("The commit deadline for job " + jobID + " has passed")
+	}
+
+	peer, err := getPeer(stub, peerID)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	org, err := solverOrg(stub, peerID, peer)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	if peer.Job != jobID {
+		return shim.Error("Solver " + peerID + " is not taking part in job " + jobID)
+	}
+	if peer.Status != "waiting" {
+		return shim.Error("Solver " + peerID + " has already committed for job " + jobID)
+	}
+
+	peer.Status = "committed"
+	peer.Commitment = hash
+	peer.Org = org
+	if err := putPeer(stub, peerID, peer); err != nil {
+		return shim.Error(err.Error())
+	}
+
+	//the first commitment moves the job on to SOLVING
+	if job.State == jobOpen {
+		job.State = jobSolving
+		if err := putJob(stub, jobID, job); err != nil {
+			return shim.Error(err.Error())
+		}
+	}
+	if err := setSolverEvent(stub, solutionSubmittedEvent, jobID, job, peerID); err != nil {
+		return shim.Error(err.Error())
+	}
+
+	return shim.Success(nil)
+}
+
+// ============================================================
+// revealSolution - open a commitment. Only a revealed assignment that matches its
+// commitment is scored and can take part in setBestSol.
+// ============================================================
+func (t *SimpleChaincode) revealSolution(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	//  0     1        2         3         4
+	//peer  job   assignment   salt   [algorithm]
+	if len(args) < 4 || len(args) > 5 {
+		return shim.Error("Incorrect number of arguments. Expecting peer, job id, assignment, salt and an optional algorithm")
+	}
+	peerID, jobID, assignment, salt := args[0], args[1], args[2], args[3]
+
+	job, err := getJob(stub, jobID)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	if err := checkSubmissionWindow(stub, jobID, job); err != nil {
+		return shim.Error(err.Error())
+	}
+
+	peer, err := getPeer(stub, peerID)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	org, err := solverOrg(stub, peerID, peer)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	if peer.Job != jobID || peer.Status != "committed" {
+		return shim.Error("Solver " + peerID + " has no commitment for job " + jobID)
+	}
+	if commitmentHash(peerID, jobID, org, salt, assignment) != peer.Commitment {
+		return shim.Error("The revealed solution does not match the commitment of " + peerID)
+	}
+
+	//the makespan is recomputed here, solvers don't get to claim their own
+	runtimes, err := jobRuntimes(stub, jobID, job)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	matrix := strToMatrix(runtimes)
+
+	//a solution that matches its commitment but isn't valid can't be revealed again,
+	//it counts as an invalid submission in the solver's statistics
+	var sol []int
+	err = json.Unmarshal([]byte(assignment), &sol)
+	if err == nil {
+		err = checkAssignment(matrix, sol)
+	} else {
+		err = fmt.Errorf("the assignment must be a JSON array of resource indices")
+	}
+	if err != nil {
+		peer.Status = "invalid"
+		if err := putPeer(stub, peerID, peer); err != nil {
+			return shim.Error(err.Error())
+		}
+		if err := setSolverEvent(stub, solutionSubmittedEvent, jobID, job, peerID); err != nil {
+			return shim.Error(err.Error())
+		}
+		return shim.Success([]byte("Invalid solution: " + err.Error()))
+	}
+
+	peer.Status = "done"
+	peer.Solution = sol
+	peer.Runtime = scheduling.Makespan(matrix, sol)
+	if len(args) == 5 {
+		peer.Algorithm = args[4]
+	}
+	if err := putPeer(stub, peerID, peer); err != nil {
+		return shim.Error(err.Error())
+	}
+	if err := setSolverEvent(stub, solutionSubmittedEvent, jobID, job, peerID); err != nil {
+		return shim.Error(err.Error())
+	}
+
+	return shim.Success(nil)
+}
+
+// commitmentHash is the hash a solver of an org commits to for an assignment and salt.
+func commitmentHash(peerID, jobID, mspID, salt, assignment string) string {
+	sum := sha256.Sum256([]byte(peerID + "|" + jobID + "|" + mspID + "|" + salt + "|" + assignment))
+	return hex.EncodeToString(sum[:])
+}
+
+// checkAssignment makes sure every task of the matrix is given a valid resource.
+func checkAssignment(matrix [][]int, sol []int) error {
+	if len(matrix) == 0 {
+		return fmt.Errorf("the job has no tasks")
+	}
+	if len(sol) != len(matrix) {
+		return fmt.Errorf("expected an assignment for %d tasks, got %d", len(matrix), len(sol))
+	}
+	for i := 0; i < len(sol); i++ {
+		if sol[i] < 0 || sol[i] >= len(matrix[i]) {
+			return fmt.Errorf("task %d is assigned to resource %d which does not exist", i, sol[i])
+		}
+	}
+	return nil
+}
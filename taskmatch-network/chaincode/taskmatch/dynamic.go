@@ -0,0 +1,424 @@
+package taskmatch
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/chaincode/scheduling"
+	"github.com/hyperledger/fabric/core/chaincode/lib/cid"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	pb "github.com/hyperledger/fabric/protos/peer"
+)
+
+/**************************************************
+ **               Dynamic Jobs                  **
+**************************************************/
+
+// A job doesn't have to be a static batch: its creator can append tasks with addTasks
+// while it is open, and put it in dynamic mode with setDynamicMode to have the chaincode
+// map the tasks onto the resources as they arrive. The mapping is kept under
+// mapping~<job id>: the resource of every task mapped so far and the time every resource
+// is busy until. Runtimes are taken to be seconds, so a resource that has been idle is
+// ready at the time of the transaction that maps the next task onto it.
+//
+// The policies are
+//
+//	mct        every new task right away, to the resource it completes on first
+//	olb        every new task right away, to the resource that is ready first
+//	min-min    the tasks that arrived since the last batch, with min-min, once the
+//	           interval has passed
+//	sufferage  the same with sufferage
+//
+// A batch is mapped by addTasks once the interval since the last one has passed, or by
+// anyone calling mapTasks. Tasks that are mapped stay where they are.
+const (
+	mctPolicy       = "mct"
+	olbPolicy       = "olb"
+	minMinPolicy    = "min-min"
+	sufferagePolicy = "sufferage"
+)
+
+// TaskMapping is the mapping of the tasks of a dynamic job.
+type TaskMapping struct {
+	Job        string `json:"job"`
+	Policy     string `json:"policy"`     //one of the policies above
+	Interval   int64  `json:"interval"`   //seconds between batches with min-min and sufferage, 0 otherwise
+	Assignment []int  `json:"assignment"` //resource of every task mapped so far, in the order of the runtimes
+	Ready      []int  `json:"ready"`      //unix time every resource is busy until with the tasks mapped
+	Pending    int    `json:"pending"`    //tasks added that are waiting for the next batch
+	LastBatch  int64  `json:"lastBatch"`  //unix time the last batch was mapped
+}
+
+// immediate tells if the policy maps every task when it arrives.
+func (m *TaskMapping) immediate() bool {
+	return m.Policy == mctPolicy || m.Policy == olbPolicy
+}
+
+// mappingEvent is the payload of the tasksMapped event.
+type mappingEvent struct {
+	Job       string `json:"job"`
+	Policy    string `json:"policy"`
+	Tasks     []int  `json:"tasks"`     //the tasks mapped by the transaction
+	Resources []int  `json:"resources"` //the resource of each of them
+	Ready     []int  `json:"ready"`
+}
+
+func mappingKey(stub shim.ChaincodeStubInterface, jobID string) (string, error) {
+	return stub.CreateCompositeKey(mappingObjectType, []string{jobID})
+}
+
+// getMapping returns the mapping of a job, nil if it isn't dynamic.
+func getMapping(stub shim.ChaincodeStubInterface, jobID string) (*TaskMapping, error) {
+	key, err := mappingKey(stub, jobID)
+	if err != nil {
+		return nil, err
+	}
+	mappingAsBytes, err := stub.GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the mapping of job %s: %s", jobID, err)
+	} else if mappingAsBytes == nil {
+		return nil, nil
+	}
+
+	mapping := &TaskMapping{}
+	if err := json.Unmarshal(mappingAsBytes, mapping); err != nil {
+		return nil, err
+	}
+	return mapping, nil
+}
+
+func putMapping(stub shim.ChaincodeStubInterface, mapping *TaskMapping) error {
+	key, err := mappingKey(stub, mapping.Job)
+	if err != nil {
+		return err
+	}
+	mappingAsBytes, err := json.Marshal(mapping)
+	if err != nil {
+		return err
+	}
+	return stub.PutState(key, mappingAsBytes)
+}
+
+// checkCreator makes sure the caller belongs to the org that created the job.
+func checkCreator(stub shim.ChaincodeStubInterface, jobID string, job *TaskMatching, action string) error {
+	if job.Creator == "" {
+		return nil
+	}
+	mspID, err := cid.GetMSPID(stub)
+	if err != nil {
+		return fmt.Errorf("failed to get the caller's MSP: %s", err)
+	} else if mspID != job.Creator {
+		return fmt.Errorf("Only %s can %s job %s", job.Creator, action, jobID)
+	}
+	return nil
+}
+
+// mapPending maps the tasks of matrix that aren't mapped yet with the policy of the
+// mapping, at time now, and returns the event describing what it did.
+func mapPending(mapping *TaskMapping, matrix [][]int, now int64) *mappingEvent {
+	first := len(mapping.Assignment)
+	event := &mappingEvent{Job: mapping.Job, Policy: mapping.Policy, Tasks: []int{}, Resources: []int{}}
+	if first < len(matrix) {
+		//resources that have run out of work are ready now
+		ready := make([]int, len(mapping.Ready))
+		for j, r := range mapping.Ready {
+			ready[j] = r
+			if int64(r) < now {
+				ready[j] = int(now)
+			}
+		}
+
+		batch := matrix[first:]
+		var sol []int
+		switch mapping.Policy {
+		case mctPolicy, olbPolicy:
+			for _, row := range batch {
+				j := scheduling.MCT(row, ready)
+				if mapping.Policy == olbPolicy {
+					j = scheduling.OLB(ready)
+				}
+				ready[j] += row[j]
+				sol = append(sol, j)
+			}
+		case minMinPolicy:
+			sol = scheduling.MinMinFrom(batch, ready)
+		case sufferagePolicy:
+			sol = scheduling.SufferageFrom(batch, ready)
+		}
+
+		for n, j := range sol {
+			event.Tasks = append(event.Tasks, first+n)
+			event.Resources = append(event.Resources, j)
+		}
+		mapping.Assignment = append(mapping.Assignment, sol...)
+		mapping.Ready = ready
+		if !mapping.immediate() {
+			mapping.LastBatch = now
+		}
+	}
+	mapping.Pending = 0
+	event.Ready = mapping.Ready
+	return event
+}
+
+// batchDue tells if the next batch of a mapping may be mapped at time now.
+func (m *TaskMapping) batchDue(now int64) bool {
+	return now >= m.LastBatch+m.Interval
+}
+
+func setMappingEvent(stub shim.ChaincodeStubInterface, event *mappingEvent) error {
+	eventAsBytes, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return stub.SetEvent(tasksMappedEvent, eventAsBytes)
+}
+
+// ============================================================
+// setDynamicMode - map the tasks of a job on the ledger as they arrive. Only the creator
+// of the job can call it. The tasks the job already has are mapped right away; a batch
+// policy maps the next batch an interval later. Calling it again changes the policy of
+// the tasks still to come.
+// ============================================================
+func (t *SimpleChaincode) setDynamicMode(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	//  0      1          2
+	//job   policy   [interval]
+	if len(args) < 2 || len(args) > 3 {
+		return shim.Error("Incorrect number of arguments. Expecting job id, policy and, for min-min and sufferage, the interval")
+	}
+	jobID, policy := args[0], strings.ToLower(args[1])
+
+	var interval int64
+	switch policy {
+	case mctPolicy, olbPolicy:
+		if len(args) == 3 && args[2] != "" {
+			return shim.Error(policy + " maps every task when it arrives and takes no interval")
+		}
+	case minMinPolicy, sufferagePolicy:
+		var err error
+		if len(args) == 3 {
+			interval, err = strconv.ParseInt(args[2], 10, 64)
+		}
+		if len(args) != 3 || err != nil || interval <= 0 {
+			return shim.Error(policy + " maps the tasks in batches, the interval must be a positive number of seconds")
+		}
+	default:
+		return shim.Error("Unknown policy " + args[1] + ", expected mct, olb, min-min or sufferage")
+	}
+
+	job, err := getJob(stub, jobID)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	if job.State != jobOpen && job.State != jobSolving {
+		return shim.Error("Job " + jobID + " is already " + job.State)
+	}
+	if err := checkCreator(stub, jobID, job, "change the mode of"); err != nil {
+		return shim.Error(err.Error())
+	}
+	runtimes, err := jobRuntimes(stub, jobID, job)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	matrix := strToMatrix(runtimes)
+
+	now, err := txTime(stub)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	mapping, err := getMapping(stub, jobID)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	if mapping == nil {
+		mapping = &TaskMapping{jobID, policy, interval, []int{}, make([]int, len(matrix[0])), 0, 0}
+	}
+	mapping.Policy, mapping.Interval = policy, interval
+	if !mapping.immediate() {
+		mapping.LastBatch = now
+	}
+
+	event := mapPending(mapping, matrix, now)
+	if err := putMapping(stub, mapping); err != nil {
+		return shim.Error(err.Error())
+	}
+	if err := setMappingEvent(stub, event); err != nil {
+		return shim.Error(err.Error())
+	}
+	return shim.Success(nil)
+}
+
+// ============================================================
+// addTasks - append tasks to a job that is still open. Only the creator of the job can
+// add tasks, and only before its deadline and before any solver handed in or committed,
+// submissions are for the tasks the job had. The rows have the runtimes of the new tasks
+// on the resources of the job; for a private job they are passed in the runtimes
+// transient field instead, and the argument has to be empty. The solvers get the full
+// time for the grown job again, so the deadlines start over. A dynamic job maps the new
+// tasks right away, or in the next batch.
+// ============================================================
+func (t *SimpleChaincode) addTasks(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	//  0       1
+	//job   runtimes
+	if len(args) != 2 {
+		return shim.Error("Incorrect number of arguments. Expecting job id and the runtimes of the new tasks")
+	}
+	jobID, rows := args[0], strings.ToLower(args[1])
+
+	job, err := getJob(stub, jobID)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	if job.State != jobOpen && job.State != jobSolving {
+		return shim.Error("Job " + jobID + " is already " + job.State)
+	}
+	if err := checkCreator(stub, jobID, job, "add tasks to"); err != nil {
+		return shim.Error(err.Error())
+	}
+	if job.State == jobSolving {
+		return shim.Error("Job " + jobID + " already has submissions, tasks can only be added before the first one")
+	}
+	now, err := txTime(stub)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	if now > job.Deadline {
+		return shim.Error("Job " + jobID + " passed its deadline " + strconv.FormatInt(job.Deadline, 10))
+	}
+
+	if job.Collection != "" {
+		if rows != "" {
+			return shim.Error("The runtimes of a private job must not be passed as an argument")
+		}
+		privRows, err := privateRuntimes(stub, job.Collection)
+		if err != nil {
+			return shim.Error(err.Error())
+		}
+		rows = strings.ToLower(privRows)
+	}
+	added, err := parseMatrix(rows)
+	if err != nil {
+		return shim.Error("Invalid runtimes: " + err.Error())
+	}
+	runtimes, err := jobRuntimes(stub, jobID, job)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	matrix := strToMatrix(runtimes)
+	if len(added[0]) != len(matrix[0]) {
+		return shim.Error(fmt.Sprintf("The new tasks have %d runtimes, job %s has %d resources", len(added[0]), jobID, len(matrix[0])))
+	}
+	matrix = append(matrix, added...)
+
+	matrixAsBytes, err := json.Marshal(matrix)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	runtimes = string(matrixAsBytes)
+	job.RuntimesHash = runtimesHash(runtimes)
+	if job.Collection != "" {
+		key, err := jobKey(stub, jobID)
+		if err != nil {
+			return shim.Error(err.Error())
+		}
+		if err := stub.PutPrivateData(job.Collection, key, matrixAsBytes); err != nil {
+			return shim.Error(err.Error())
+		}
+	} else {
+		job.Runtimes = runtimes
+	}
+
+	//the commit phase and the submissions get their full time again from now on
+	commitTimeout, timeout := job.CommitDeadline-job.Created, job.Deadline-job.CommitDeadline
+	job.Deadline = now + timeout
+	if commitTimeout > 0 {
+		job.CommitDeadline = now + commitTimeout
+		job.Deadline += commitTimeout
+	}
+	if err := putJob(stub, jobID, job); err != nil {
+		return shim.Error(err.Error())
+	}
+
+	mapping, err := getMapping(stub, jobID)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	if mapping == nil {
+		if err := setJobEvent(stub, tasksAddedEvent, jobID, job); err != nil {
+			return shim.Error(err.Error())
+		}
+		return shim.Success(nil)
+	}
+
+	if !mapping.immediate() && !mapping.batchDue(now) {
+		mapping.Pending = len(matrix) - len(mapping.Assignment)
+		if err := putMapping(stub, mapping); err != nil {
+			return shim.Error(err.Error())
+		}
+		if err := setJobEvent(stub, tasksAddedEvent, jobID, job); err != nil {
+			return shim.Error(err.Error())
+		}
+		return shim.Success(nil)
+	}
+
+	event := mapPending(mapping, matrix, now)
+	if err := putMapping(stub, mapping); err != nil {
+		return shim.Error(err.Error())
+	}
+	if err := setMappingEvent(stub, event); err != nil {
+		return shim.Error(err.Error())
+	}
+	return shim.Success(nil)
+}
+
+// ============================================================
+// mapTasks - map the batch of tasks a dynamic job has been waiting with, once the
+// interval since the last batch has passed. Anyone can call it, like closeJob.
+// ============================================================
+func (t *SimpleChaincode) mapTasks(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	if len(args) != 1 {
+		return shim.Error("Incorrect number of arguments. Expecting the job id")
+	}
+	jobID := args[0]
+
+	job, err := getJob(stub, jobID)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	if job.State != jobOpen && job.State != jobSolving {
+		return shim.Error("Job " + jobID + " is already " + job.State)
+	}
+	mapping, err := getMapping(stub, jobID)
+	if err != nil {
+		return shim.Error(err.Error())
+	} else if mapping == nil {
+		return shim.Error("Job " + jobID + " is not in dynamic mode")
+	}
+
+	runtimes, err := jobRuntimes(stub, jobID, job)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	matrix := strToMatrix(runtimes)
+	if len(mapping.Assignment) == len(matrix) {
+		return shim.Error("Job " + jobID + " has no tasks waiting to be mapped")
+	}
+	now, err := txTime(stub)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	if !mapping.batchDue(now) {
+		return shim.Error("The next batch of job " + jobID + " is due at " + strconv.FormatInt(mapping.LastBatch+mapping.Interval, 10))
+	}
+
+	event := mapPending(mapping, matrix, now)
+	if err := putMapping(stub, mapping); err != nil {
+		return shim.Error(err.Error())
+	}
+	if err := setMappingEvent(stub, event); err != nil {
+		return shim.Error(err.Error())
+	}
+	return shim.Success(nil)
+}
@@ -0,0 +1,52 @@
+package taskmatch
+
+import (
+	"encoding/json"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+/**************************************************
+ **              Chaincode Events               **
+**************************************************/
+
+// Clients can follow the jobs through chaincode events instead of polling the ledger.
+// Fabric keeps one event per transaction, the last one set, so every transaction sets at
+// most one of these.
+const (
+	jobCreatedEvent        = "jobCreated"        //createTaskMatching
+	solutionSubmittedEvent = "solutionSubmitted" //a solver handed in or committed to a solution
+	jobFinishedEvent       = "jobFinished"       //the job was closed, expired or cancelled
+	solutionImprovedEvent  = "solutionImproved"  //improveSolution replaced the best solution
+	tasksAddedEvent        = "tasksAdded"        //addTasks appended tasks without mapping them
+	tasksMappedEvent       = "tasksMapped"       //tasks of a dynamic job were mapped, see dynamic.go
+)
+
+// jobEvent is the payload of the job events.
+type jobEvent struct {
+	Job            string `json:"job"`
+	State          string `json:"state"`
+	Creator        string `json:"creator"`
+	Deadline       int64  `json:"deadline"`
+	CommitDeadline int64  `json:"commitDeadline"`
+	Collection     string `json:"collection"`
+	Bounty         int64  `json:"bounty"`
+	BestSolution   int    `json:"bestSolution"`
+	BestRuntime    int    `json:"bestRuntime"`
+	Solver         string `json:"solver,omitempty"` //the solver that handed in, for solutionSubmitted
+}
+
+// setJobEvent sets the event name of the transaction, describing the job as it is now.
+func setJobEvent(stub shim.ChaincodeStubInterface, name string, jobID string, job *TaskMatching) error {
+	return setSolverEvent(stub, name, jobID, job, "")
+}
+
+// setSolverEvent is setJobEvent for an event caused by a solver.
+func setSolverEvent(stub shim.ChaincodeStubInterface, name string, jobID string, job *TaskMatching, peerID string) error {
+	event := jobEvent{jobID, job.State, job.Creator, job.Deadline, job.CommitDeadline, job.Collection, job.Bounty, job.BestSolution, job.BestRuntime, peerID}
+	eventAsBytes, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return stub.SetEvent(name, eventAsBytes)
+}
@@ -0,0 +1,105 @@
+package taskmatch
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/chaincode/memledger"
+	"github.com/chaincode/scheduling"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+func FuzzStrToMatrix(f *testing.F) {
+	for _, seed := range []string{"[[1,2,3],[4,5,6],[7,8,9]]", "[[0]]", "[]", "", "[[1,2],[3]]", "[[1.5]]", "null", "[[-1]]"} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, input string) {
+		matrix := strToMatrix(input)
+
+		//whatever parses survives a round trip
+		if matrix != nil {
+			encoded, err := json.Marshal(matrix)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if again := strToMatrix(string(encoded)); !reflect.DeepEqual(again, matrix) {
+				t.Fatalf("%s parsed as %v, then as %v", input, matrix, again)
+			}
+		}
+
+		//a matrix createTaskMatching accepts can be solved by every solver
+		parsed, err := parseMatrix(input)
+		if err != nil {
+			return
+		}
+		if !reflect.DeepEqual(parsed, matrix) {
+			t.Fatalf("parseMatrix and strToMatrix disagree on %s", input)
+		}
+		for _, peer := range []string{"p1", "p2"} {
+			sol, runtime := Assign(parsed, peer)
+			if checkAssignment(parsed, sol) != nil || runtime != scheduling.Makespan(parsed, sol) {
+				t.Fatalf("%s solved %s with %v, %d", peer, input, sol, runtime)
+			}
+		}
+	})
+}
+
+func FuzzCreateTaskMatching(f *testing.F) {
+	f.Add("work", testRuntimes, "", "", "", "")
+	f.Add("work", testRuntimes, "60", "30", "", "10")
+	f.Add("work", "", "", "", "etcOrg1Org2", "")
+	f.Add("", "[[1]]", "-1", "x", "nope", "0")
+	f.Add("solution", "[[1],[2,3]]", "1e3", "", "", "99999999999999999999")
+	f.Fuzz(func(t *testing.T, id, runtimes, timeout, commitTimeout, collection, bounty string) {
+		ledger := memledger.New(new(SimpleChaincode))
+		ledger.Invoke("Org1MSP", "Initialize")
+		ledger.Invoke("Org1MSP", "mint", "Org1MSP", "100")
+
+		transient := map[string][]byte{runtimesTransientKey: []byte(runtimes)}
+		if collection != "" {
+			runtimes = ""
+		}
+		res := ledger.InvokeWithTransient("Org1MSP", transient, "createTaskMatching", id, runtimes, timeout, commitTimeout, collection, bounty)
+		if res.Status != shim.OK {
+			return
+		}
+
+		//once a job is accepted the rest of the protocol must not fail on its arguments
+		for _, peer := range peerArray {
+			ledger.Invoke(solverOrgs[peer], "calculateTaskMatching", peer, id)
+			ledger.Invoke(solverOrgs[peer], "commitSolution", peer, id, commitmentHash(peer, id, solverOrgs[peer], "salt", "[0]"))
+		}
+		ledger.Invoke("Org1MSP", "closeJob", id)
+		ledger.Invoke("Org1MSP", "improveSolution", id, "[0,0,0]")
+		ledger.Query("Org1MSP", "readTaskMatching", id)
+		ledger.Query("Org1MSP", "getHistory", id)
+		ledger.Query("Org1MSP", "listSolutions", id)
+	})
+}
+
+func FuzzRevealSolution(f *testing.F) {
+	f.Add("[0,1,2]", "salt")
+	f.Add("[0,0,5]", "")
+	f.Add("[-1,0,0]", "x")
+	f.Add("[0,1]", "x")
+	f.Add("{}", "x")
+	f.Fuzz(func(t *testing.T, assignment, salt string) {
+		ledger := memledger.New(new(SimpleChaincode))
+		ledger.Invoke("Org1MSP", "Initialize")
+		ledger.Invoke("Org1MSP", "createTaskMatching", "work", testRuntimes, "60", "30")
+		ledger.Invoke("Org1MSP", "commitSolution", "p1", "work", commitmentHash("p1", "work", "Org1MSP", salt, assignment))
+		ledger.Advance(31e9)
+
+		res := ledger.Invoke("Org1MSP", "revealSolution", "p1", "work", assignment, salt)
+		if res.Status != shim.OK {
+			t.Fatalf("revealing %q failed: %s", assignment, res.Message)
+		}
+		var peer Peer
+		readRecord(t, ledger, &peer, "solver", "p1")
+		matrix := strToMatrix(testRuntimes)
+		if peer.Status == "done" && (checkAssignment(matrix, peer.Solution) != nil || peer.Runtime != scheduling.Makespan(matrix, peer.Solution)) {
+			t.Fatalf("accepted %q as %v with makespan %d", assignment, peer.Solution, peer.Runtime)
+		}
+	})
+}
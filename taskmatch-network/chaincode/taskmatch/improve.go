@@ -0,0 +1,145 @@
+package taskmatch
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/chaincode/scheduling"
+	"github.com/hyperledger/fabric/core/chaincode/lib/cid"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	pb "github.com/hyperledger/fabric/protos/peer"
+)
+
+/**************************************************
+ **           Improvements After Close          **
+**************************************************/
+
+// defaultImprovementMargin is the percent an improvement has to beat the best makespan
+// by until the minter calls setImprovementRule.
+const defaultImprovementMargin = 1
+
+// improvementAlgorithm is the algorithm recorded for solutions saved by improveSolution.
+const improvementAlgorithm = "improvement"
+
+// ============================================================
+// improveSolution - anyone can hand in a better assignment for a closed job at any time.
+// If its makespan, recomputed here, beats the best one by the improvement margin it is
+// saved as a new solution that becomes the best one of the job. The old best solution
+// stays on the ledger, the improver's org owns the new one, gets the improvement pool of
+// the job if it is the first improvement and is credited in its statistics.
+// ============================================================
+func (t *SimpleChaincode) improveSolution(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	//  0        1
+	//job   assignment
+	if len(args) != 2 {
+		return shim.Error("Incorrect number of arguments. Expecting job id and assignment")
+	}
+	jobID := args[0]
+
+	job, err := getJob(stub, jobID)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	if job.State != jobClosed || job.BestSolution == 0 {
+		return shim.Error("Job " + jobID + " has no best solution to improve on")
+	}
+
+	var sol []int
+	if err := json.Unmarshal([]byte(args[1]), &sol); err != nil {
+		return shim.Error("The assignment must be a JSON array of resource indices")
+	}
+	runtimes, err := jobRuntimes(stub, jobID, job)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	matrix := strToMatrix(runtimes)
+	if err := checkAssignment(matrix, sol); err != nil {
+		return shim.Error(err.Error())
+	}
+
+	config, err := getTokenConfig(stub)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	runtime := scheduling.Makespan(matrix, sol)
+	if runtime*100 > job.BestRuntime*(100-config.ImprovementMargin) || runtime >= job.BestRuntime {
+		return shim.Error("A makespan of " + strconv.Itoa(runtime) + " does not beat " + strconv.Itoa(job.BestRuntime) +
+			" by " + strconv.Itoa(config.ImprovementMargin) + "%")
+	}
+
+	improver, err := cid.GetMSPID(stub)
+	if err != nil {
+		return shim.Error("Failed to get the improver's MSP: " + err.Error())
+	}
+
+	counter, err := nextSolutionNumber(stub)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	solKey, err := solutionKey(stub, jobID, counter)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	TMSol := TaskMatchingSol{strconv.Itoa(counter), runtime, sol, improver, improvementAlgorithm, job.Runtimes, solutionObjectType, jobID, job.RuntimesHash, job.BestSolution}
+	TMSolAsJSON, err := json.Marshal(TMSol)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	if err := stub.PutState(solKey, TMSolAsJSON); err != nil {
+		return shim.Error(err.Error())
+	}
+
+	if err := payImprovement(stub, job, improver); err != nil {
+		return shim.Error(err.Error())
+	}
+	if err := countImprovement(stub, improver); err != nil {
+		return shim.Error(err.Error())
+	}
+
+	job.BestSolution = counter
+	job.BestRuntime = runtime
+	if err := putJob(stub, jobID, job); err != nil {
+		return shim.Error(err.Error())
+	}
+	if err := setJobEvent(stub, solutionImprovedEvent, jobID, job); err != nil {
+		return shim.Error(err.Error())
+	}
+
+	return shim.Success([]byte(strconv.Itoa(counter)))
+}
+
+// ============================================================
+// setImprovementRule - the margin in percent an improvement has to beat the best makespan
+// by, and the tokens the creator of every new job has to hold for the org of its first
+// improvement. Only the minter may call it.
+// ============================================================
+func (t *SimpleChaincode) setImprovementRule(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	//  0         1
+	//margin   reward
+	if len(args) != 2 {
+		return shim.Error("Incorrect number of arguments. Expecting margin and reward")
+	}
+
+	if err := checkMinter(stub); err != nil {
+		return shim.Error(err.Error())
+	}
+	margin, err := strconv.Atoi(args[0])
+	if err != nil || margin < 0 || margin >= 100 {
+		return shim.Error("The margin must be a percentage between 0 and 99")
+	}
+	reward, err := strconv.ParseInt(args[1], 10, 64)
+	if err != nil || reward < 0 {
+		return shim.Error("The reward must be a non-negative numeric string")
+	}
+
+	config, err := getTokenConfig(stub)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	config.ImprovementMargin = margin
+	config.ImprovementReward = reward
+	if err := putTokenConfig(stub, config); err != nil {
+		return shim.Error(err.Error())
+	}
+	return shim.Success(nil)
+}
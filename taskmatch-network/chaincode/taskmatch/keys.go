@@ -0,0 +1,96 @@
+package taskmatch
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+// Every record lives in its own namespace of composite keys, so a job can't collide
+// with a solver, a solution or the counter whatever it is called:
+//
+//	job~<job id>
+//	solver~<peer id>
+//	solution~<job id>~<solution number>
+//	counter~solution
+//	balance~<msp id>
+//	config~token
+//	stats~<peer id>
+//	mapping~<job id>
+const (
+	jobObjectType      = "job"
+	solverObjectType   = "solver"
+	solutionObjectType = "solution"
+	counterObjectType  = "counter"
+	balanceObjectType  = "balance"
+	configObjectType   = "config"
+	statsObjectType    = "stats"
+	mappingObjectType  = "mapping"
+)
+
+// reservedIDs can't be used as job ids, they name solvers and system records and would
+// make the output of readTaskMatching and getHistory ambiguous.
+var reservedIDs = []string{"count", jobObjectType, solverObjectType, solutionObjectType, counterObjectType, balanceObjectType, configObjectType, statsObjectType, mappingObjectType}
+
+func jobKey(stub shim.ChaincodeStubInterface, jobID string) (string, error) {
+	return stub.CreateCompositeKey(jobObjectType, []string{jobID})
+}
+
+func solverKey(stub shim.ChaincodeStubInterface, peerID string) (string, error) {
+	return stub.CreateCompositeKey(solverObjectType, []string{peerID})
+}
+
+// solutionKey zero pads the solution number so that the solutions of a job come back
+// in the order they were saved when iterating over the namespace.
+func solutionKey(stub shim.ChaincodeStubInterface, jobID string, solNum int) (string, error) {
+	return stub.CreateCompositeKey(solutionObjectType, []string{jobID, fmt.Sprintf("%08d", solNum)})
+}
+
+func counterKey(stub shim.ChaincodeStubInterface) (string, error) {
+	return stub.CreateCompositeKey(counterObjectType, []string{solutionObjectType})
+}
+
+// recordKey builds the key of any record from its object type and attributes as they
+// are passed to readTaskMatching and getHistory, e.g. "solver" "p1" or "solution" "work" "1".
+func recordKey(stub shim.ChaincodeStubInterface, objectType string, attributes []string) (string, error) {
+	switch objectType {
+	case jobObjectType, solverObjectType, balanceObjectType, statsObjectType, mappingObjectType:
+		if len(attributes) != 1 {
+			return "", fmt.Errorf("a %s is identified by its id", objectType)
+		}
+		return stub.CreateCompositeKey(objectType, attributes)
+	case solutionObjectType:
+		if len(attributes) != 2 {
+			return "", fmt.Errorf("a solution is identified by its job id and number")
+		}
+		solNum, err := strconv.Atoi(attributes[1])
+		if err != nil {
+			return "", fmt.Errorf("the solution number must be a numeric string")
+		}
+		return solutionKey(stub, attributes[0], solNum)
+	case counterObjectType:
+		return counterKey(stub)
+	case configObjectType:
+		return tokenConfigKey(stub)
+	}
+	return "", fmt.Errorf("unknown record type %s", objectType)
+}
+
+// checkJobID rejects ids that are reserved or can't be part of a composite key.
+func checkJobID(jobID string) error {
+	if jobID == "" {
+		return fmt.Errorf("the job id can't be empty")
+	}
+	for _, id := range append(reservedIDs, peerArray...) {
+		if jobID == id {
+			return fmt.Errorf("%s is a reserved id", jobID)
+		}
+	}
+	if !utf8.ValidString(jobID) || strings.ContainsAny(jobID, "\x00"+string(utf8.MaxRune)) {
+		return fmt.Errorf("the job id contains invalid characters")
+	}
+	return nil
+}
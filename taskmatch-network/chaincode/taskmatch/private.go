@@ -0,0 +1,125 @@
+package taskmatch
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/hyperledger/fabric/core/chaincode/lib/cid"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	pb "github.com/hyperledger/fabric/protos/peer"
+)
+
+/**************************************************
+ **            Private ETC Matrices             **
+**************************************************/
+
+// A job created with a collection keeps its runtimes in that private data collection
+// under the job key, and only their sha256 hash in the public job record. The matrix
+// is passed in the "runtimes" transient field so that it doesn't end up in the block.
+//
+// etcCollections mirrors taskmatching/collections_config.json: which orgs can read each
+// collection.
+var etcCollections = map[string][]string{
+	"etcOrg1Org2":     {"Org1MSP", "Org2MSP"},
+	"etcOrg1Org3":     {"Org1MSP", "Org3MSP"},
+	"etcOrg2Org3":     {"Org2MSP", "Org3MSP"},
+	"etcOrg1Org2Org3": {"Org1MSP", "Org2MSP", "Org3MSP"},
+}
+
+// Collections returns the private data collections of the chaincode with the orgs that
+// are members of each, to set them up on an in-memory ledger.
+func Collections() map[string][]string {
+	collections := make(map[string][]string, len(etcCollections))
+	for name, members := range etcCollections {
+		collections[name] = append([]string(nil), members...)
+	}
+	return collections
+}
+
+// runtimesTransientKey is the transient field createTaskMatching reads private runtimes from.
+const runtimesTransientKey = "runtimes"
+
+// runtimesHash is the hash of a job's runtimes kept in public state.
+func runtimesHash(runtimes string) string {
+	sum := sha256.Sum256([]byte(runtimes))
+	return hex.EncodeToString(sum[:])
+}
+
+// privateRuntimes reads the runtimes of a new private job from the transient map and
+// makes sure the submitter's org is a member of the collection they go into.
+func privateRuntimes(stub shim.ChaincodeStubInterface, collection string) (string, error) {
+	members, ok := etcCollections[collection]
+	if !ok {
+		return "", fmt.Errorf("unknown collection %s", collection)
+	}
+
+	mspID, err := cid.GetMSPID(stub)
+	if err != nil {
+		return "", fmt.Errorf("failed to get the submitter's MSP: %s", err)
+	}
+	member := false
+	for _, m := range members {
+		if m == mspID {
+			member = true
+		}
+	}
+	if !member {
+		return "", fmt.Errorf("%s is not a member of collection %s", mspID, collection)
+	}
+
+	transient, err := stub.GetTransient()
+	if err != nil {
+		return "", fmt.Errorf("failed to get the transient map: %s", err)
+	}
+	runtimes, ok := transient[runtimesTransientKey]
+	if !ok || len(runtimes) == 0 {
+		return "", fmt.Errorf("the runtimes of a private job must be passed in the %q transient field", runtimesTransientKey)
+	}
+	return string(runtimes), nil
+}
+
+// jobRuntimes returns the runtimes of a job, reading them from its collection if it is
+// private, and checks them against the hash in the job record. Peers outside of the
+// collection get an error.
+func jobRuntimes(stub shim.ChaincodeStubInterface, jobID string, job *TaskMatching) (string, error) {
+	runtimes := job.Runtimes
+	if job.Collection != "" {
+		key, err := jobKey(stub, jobID)
+		if err != nil {
+			return "", err
+		}
+		runtimesAsBytes, err := stub.GetPrivateData(job.Collection, key)
+		if err != nil {
+			return "", fmt.Errorf("failed to get the runtimes of job %s from %s: %s", jobID, job.Collection, err)
+		} else if runtimesAsBytes == nil {
+			return "", fmt.Errorf("the runtimes of job %s are not available in %s on this peer", jobID, job.Collection)
+		}
+		runtimes = string(runtimesAsBytes)
+	}
+
+	if job.RuntimesHash != "" && runtimesHash(runtimes) != job.RuntimesHash {
+		return "", fmt.Errorf("the runtimes of job %s do not match their hash", jobID)
+	}
+	return runtimes, nil
+}
+
+// ============================================================
+// readJobRuntimes - the runtimes of a job, also when they are private. Has to be sent
+// to a peer of an org in the job's collection.
+// ============================================================
+func (t *SimpleChaincode) readJobRuntimes(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	if len(args) != 1 {
+		return shim.Error("Incorrect number of arguments. Expecting the job id")
+	}
+
+	job, err := getJob(stub, args[0])
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	runtimes, err := jobRuntimes(stub, args[0], job)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	return shim.Success([]byte(runtimes))
+}
@@ -0,0 +1,626 @@
+package taskmatch
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chaincode/memledger"
+	"github.com/chaincode/scheduling"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	pb "github.com/hyperledger/fabric/protos/peer"
+)
+
+/*** End to end runs of the taskmatching protocol on the in-memory ledger. Every ***/
+/*** peer pN belongs to OrgNMSP, like in the network started by startNetwork.sh.  ***/
+
+const testRuntimes = "[[1,2,3],[4,5,6],[7,8,9]]"
+
+// newTestLedger returns a ledger with Initialize already called by Org1MSP, which makes
+// it the token minter.
+func newTestLedger(t *testing.T) *memledger.Ledger {
+	t.Helper()
+	ledger := memledger.New(new(SimpleChaincode))
+	for name, members := range etcCollections {
+		ledger.AddCollection(name, members...)
+	}
+	mustInvoke(t, ledger, "Org1MSP", "Initialize")
+	return ledger
+}
+
+func mustInvoke(t *testing.T, ledger *memledger.Ledger, mspID string, args ...string) []byte {
+	t.Helper()
+	res := ledger.Invoke(mspID, args...)
+	if res.Status != shim.OK {
+		t.Fatalf("%s %v failed: %s", mspID, args, res.Message)
+	}
+	return res.Payload
+}
+
+func mustFail(t *testing.T, ledger *memledger.Ledger, mspID string, contains string, args ...string) {
+	t.Helper()
+	res := ledger.Invoke(mspID, args...)
+	if res.Status == shim.OK {
+		t.Fatalf("%s %v succeeded, expected an error containing %q", mspID, args, contains)
+	}
+	if !strings.Contains(res.Message, contains) {
+		t.Fatalf("%s %v failed with %q, expected it to contain %q", mspID, args, res.Message, contains)
+	}
+}
+
+func readRecord(t *testing.T, ledger *memledger.Ledger, record interface{}, args ...string) {
+	t.Helper()
+	res := ledger.Query("Org1MSP", append([]string{"readTaskMatching"}, args...)...)
+	checkPayload(t, res, record)
+}
+
+func checkPayload(t *testing.T, res pb.Response, record interface{}) {
+	t.Helper()
+	if res.Status != shim.OK {
+		t.Fatalf("query failed: %s", res.Message)
+	}
+	if err := json.Unmarshal(res.Payload, record); err != nil {
+		t.Fatalf("bad payload %s: %s", res.Payload, err)
+	}
+}
+
+func balance(t *testing.T, ledger *memledger.Ledger, mspID string) int64 {
+	t.Helper()
+	var b Balance
+	checkPayload(t, ledger.Query(mspID, "balanceOf"), &b)
+	return b.Amount
+}
+
+// solveAll has every solver submit for a job with calculateTaskMatching.
+func solveAll(t *testing.T, ledger *memledger.Ledger, jobID string) {
+	t.Helper()
+	for _, peer := range peerArray {
+		mustInvoke(t, ledger, solverOrgs[peer], "calculateTaskMatching", peer, jobID)
+	}
+}
+
+func TestProtocolLastSubmissionClosesJob(t *testing.T) {
+	ledger := newTestLedger(t)
+	mustInvoke(t, ledger, "Org1MSP", "createTaskMatching", "work", testRuntimes)
+
+	var job TaskMatching
+	readRecord(t, ledger, &job, "work")
+	if job.State != jobOpen || job.Creator != "Org1MSP" {
+		t.Fatalf("new job is %s created by %s", job.State, job.Creator)
+	}
+	if job.Deadline != ledger.Now().Unix()+defaultJobTimeout {
+		t.Fatalf("deadline %d, expected %d", job.Deadline, ledger.Now().Unix()+defaultJobTimeout)
+	}
+
+	mustInvoke(t, ledger, "Org1MSP", "calculateTaskMatching", "p1")
+	readRecord(t, ledger, &job, "work")
+	if job.State != jobSolving {
+		t.Fatalf("job is %s after the first submission", job.State)
+	}
+	mustFail(t, ledger, "Org1MSP", "already submitted", "calculateTaskMatching", "p1")
+
+	ledger.Advance(time.Second)
+	mustInvoke(t, ledger, "Org2MSP", "calculateTaskMatching", "p2")
+	ledger.Advance(time.Second)
+	mustInvoke(t, ledger, "Org3MSP", "calculateTaskMatching", "p3")
+
+	//the last submission sees its own write and picks the best solution right away
+	readRecord(t, ledger, &job, "work")
+	if job.State != jobClosed || job.BestSolution != 1 {
+		t.Fatalf("job is %s with best solution %d after every solver submitted", job.State, job.BestSolution)
+	}
+
+	var p1, p2 Peer
+	readRecord(t, ledger, &p1, "solver", "p1")
+	readRecord(t, ledger, &p2, "solver", "p2")
+	best := p1.Runtime
+	if p2.Runtime < best {
+		best = p2.Runtime
+	}
+
+	var sol TaskMatchingSol
+	readRecord(t, ledger, &sol, "solution", "work", "1")
+	matrix := strToMatrix(testRuntimes)
+	if sol.Runtime != best || job.BestRuntime != best {
+		t.Fatalf("saved makespan %d, job %d, expected the best of %d and %d", sol.Runtime, job.BestRuntime, p1.Runtime, p2.Runtime)
+	}
+	if checkAssignment(matrix, sol.Solution) != nil || scheduling.Makespan(matrix, sol.Solution) != sol.Runtime {
+		t.Fatalf("saved solution %v does not have makespan %d", sol.Solution, sol.Runtime)
+	}
+	if sol.Job != "work" || sol.DocType != solutionObjectType {
+		t.Fatalf("solution saved for job %q as %q", sol.Job, sol.DocType)
+	}
+
+	mustFail(t, ledger, "Org3MSP", "CLOSED", "calculateTaskMatching", "p3")
+}
+
+func TestProtocolDeadlines(t *testing.T) {
+	ledger := newTestLedger(t)
+	mustInvoke(t, ledger, "Org1MSP", "createTaskMatching", "work", testRuntimes, "60")
+
+	mustFail(t, ledger, "Org1MSP", "still accepting submissions", "closeJob", "work")
+	mustFail(t, ledger, "Org1MSP", "still open", "createTaskMatching", "work2", testRuntimes)
+
+	ledger.Advance(30 * time.Second)
+	mustInvoke(t, ledger, "Org2MSP", "calculateTaskMatching", "p2")
+
+	//p1 and p3 miss the deadline, p2 wins on its own
+	ledger.Advance(31 * time.Second)
+	mustFail(t, ledger, "Org1MSP", "deadline", "calculateTaskMatching", "p1")
+	mustInvoke(t, ledger, "Org3MSP", "closeJob", "work")
+
+	var job TaskMatching
+	readRecord(t, ledger, &job, "work")
+	if job.State != jobClosed {
+		t.Fatalf("job is %s after closing it", job.State)
+	}
+	var sol TaskMatchingSol
+	readRecord(t, ledger, &sol, "solution", "work", "1")
+	if sol.Owner != "Peer 2" {
+		t.Fatalf("solution owned by %s, expected Peer 2", sol.Owner)
+	}
+
+	var stats SolverStats
+	checkPayload(t, ledger.Query("Org1MSP", "getSolverStats", "p1"), &stats)
+	if stats.MissedDeadlines != 1 {
+		t.Fatalf("p1 missed %d deadlines, expected 1", stats.MissedDeadlines)
+	}
+
+	//a job nobody works on expires
+	mustInvoke(t, ledger, "Org1MSP", "createTaskMatching", "work2", testRuntimes, "10")
+	ledger.Advance(11 * time.Second)
+	mustInvoke(t, ledger, "Org1MSP", "closeJob", "work2")
+	readRecord(t, ledger, &job, "work2")
+	if job.State != jobExpired {
+		t.Fatalf("unsolved job is %s after its deadline", job.State)
+	}
+}
+
+func TestProtocolCommitReveal(t *testing.T) {
+	ledger := newTestLedger(t)
+	mustInvoke(t, ledger, "Org1MSP", "createTaskMatching", "work", testRuntimes, "60", "30")
+
+	//a solver can't skip the commitment by having the peer compute its solution, neither
+	//during the commit phase nor after it
+	mustFail(t, ledger, "Org3MSP", "commit phase", "calculateTaskMatching", "p3", "work")
+
+	assignments := map[string]string{"p1": "[0,1,2]", "p2": "[2,2,2]", "p3": "[0,0,5]"}
+	for _, peer := range peerArray {
+		hash := commitmentHash(peer, "work", solverOrgs[peer], "salt-"+peer, assignments[peer])
+		mustInvoke(t, ledger, solverOrgs[peer], "commitSolution", peer, "work", hash)
+	}
+	mustFail(t, ledger, "Org1MSP", "commit phase", "revealSolution", "p1", "work", assignments["p1"], "salt-p1")
+
+	ledger.Advance(31 * time.Second)
+	mustFail(t, ledger, "Org1MSP", "commit deadline", "commitSolution", "p1", "work", commitmentHash("p1", "work", "Org1MSP", "x", "[0,0,0]"))
+	mustFail(t, ledger, "Org2MSP", "does not match", "revealSolution", "p2", "work", "[0,0,0]", "salt-p2")
+	mustFail(t, ledger, "Org3MSP", "commit phase", "calculateTaskMatching", "p3", "work")
+
+	mustInvoke(t, ledger, "Org1MSP", "revealSolution", "p1", "work", assignments["p1"], "salt-p1")
+	mustInvoke(t, ledger, "Org2MSP", "revealSolution", "p2", "work", assignments["p2"], "salt-p2")
+	payload := mustInvoke(t, ledger, "Org3MSP", "revealSolution", "p3", "work", assignments["p3"], "salt-p3")
+	if !strings.HasPrefix(string(payload), "Invalid solution") {
+		t.Fatalf("out of range assignment revealed with %q", payload)
+	}
+
+	//p1 has makespan 9 against 18 for p2, p3 doesn't count
+	var sol TaskMatchingSol
+	readRecord(t, ledger, &sol, "solution", "work", "1")
+	if sol.Owner != "Peer 1" || sol.Runtime != 9 {
+		t.Fatalf("solution of %s with makespan %d, expected Peer 1 with 9", sol.Owner, sol.Runtime)
+	}
+}
+
+func TestProtocolBounty(t *testing.T) {
+	ledger := newTestLedger(t)
+	mustInvoke(t, ledger, "Org1MSP", "mint", "Org1MSP", "100")
+	mustFail(t, ledger, "Org2MSP", "only Org1MSP", "mint", "Org2MSP", "100")
+	mustFail(t, ledger, "Org1MSP", "insufficient balance", "createTaskMatching", "work", testRuntimes, "", "", "", "500")
+
+	mustInvoke(t, ledger, "Org1MSP", "createTaskMatching", "work", testRuntimes, "", "", "", "40")
+	if b := balance(t, ledger, "Org1MSP"); b != 60 {
+		t.Fatalf("creator has %d after escrowing the bounty, expected 60", b)
+	}
+	solveAll(t, ledger, "work")
+
+	var sol TaskMatchingSol
+	readRecord(t, ledger, &sol, "solution", "work", "1")
+	winner := solverOrgs["p"+strings.TrimPrefix(sol.Owner, "Peer ")]
+	total := int64(0)
+	for _, org := range solverOrgs {
+		total += balance(t, ledger, org)
+	}
+	if b := balance(t, ledger, winner); total != 100 || b < 40 {
+		t.Fatalf("winner %s has %d of %d tokens after the payout", winner, b, total)
+	}
+
+	//sending tokens to yourself doesn't create any
+	before := balance(t, ledger, "Org1MSP")
+	mustInvoke(t, ledger, "Org1MSP", "transfer", "Org1MSP", strconv.FormatInt(before, 10))
+	if b := balance(t, ledger, "Org1MSP"); b != before {
+		t.Fatalf("Org1MSP has %d after sending its %d tokens to itself", b, before)
+	}
+}
+
+func TestProtocolCancel(t *testing.T) {
+	ledger := newTestLedger(t)
+	mustInvoke(t, ledger, "Org1MSP", "mint", "Org2MSP", "50")
+	mustInvoke(t, ledger, "Org2MSP", "createTaskMatching", "work", testRuntimes, "", "", "", "50")
+
+	mustFail(t, ledger, "Org1MSP", "Only Org2MSP", "cancelJob", "work")
+	mustInvoke(t, ledger, "Org2MSP", "cancelJob", "work")
+	if b := balance(t, ledger, "Org2MSP"); b != 50 {
+		t.Fatalf("creator has %d after cancelling, expected the bounty of 50 back", b)
+	}
+	mustFail(t, ledger, "Org1MSP", "CANCELLED", "calculateTaskMatching", "p1", "work")
+
+	//the solvers are free for the next job, which can't be cancelled once they hand in
+	mustInvoke(t, ledger, "Org1MSP", "createTaskMatching", "work2", testRuntimes)
+	mustInvoke(t, ledger, "Org1MSP", "calculateTaskMatching", "p1", "work2")
+	mustFail(t, ledger, "Org1MSP", "already has submissions", "cancelJob", "work2")
+	mustInvoke(t, ledger, "Org2MSP", "calculateTaskMatching", "p2", "work2")
+	mustInvoke(t, ledger, "Org3MSP", "calculateTaskMatching", "p3", "work2")
+}
+
+func TestProtocolPrivateRuntimes(t *testing.T) {
+	ledger := newTestLedger(t)
+	transient := map[string][]byte{runtimesTransientKey: []byte(testRuntimes)}
+
+	res := ledger.InvokeWithTransient("Org3MSP", transient, "createTaskMatching", "work", "", "", "", "etcOrg1Org2")
+	if res.Status == shim.OK {
+		t.Fatal("Org3MSP created a job in a collection it isn't a member of")
+	}
+	res = ledger.InvokeWithTransient("Org1MSP", transient, "createTaskMatching", "work", "", "", "", "etcOrg1Org2")
+	if res.Status != shim.OK {
+		t.Fatalf("creating a private job failed: %s", res.Message)
+	}
+
+	var job TaskMatching
+	readRecord(t, ledger, &job, "work")
+	if job.Runtimes != "" || job.RuntimesHash != runtimesHash(testRuntimes) {
+		t.Fatalf("private runtimes leaked into the job record: %q", job.Runtimes)
+	}
+	if res := ledger.Query("Org3MSP", "readJobRuntimes", "work"); res.Status == shim.OK {
+		t.Fatal("Org3MSP read the runtimes of a private job")
+	}
+	if res := ledger.Query("Org2MSP", "readJobRuntimes", "work"); res.Status != shim.OK || string(res.Payload) != testRuntimes {
+		t.Fatalf("Org2MSP read %q from the private runtimes: %s", res.Payload, res.Message)
+	}
+}
+
+func TestProtocolRollback(t *testing.T) {
+	ledger := newTestLedger(t)
+	mustInvoke(t, ledger, "Org1MSP", "createTaskMatching", "work", testRuntimes)
+
+	//a failing transaction leaves nothing behind
+	mustFail(t, ledger, "Org1MSP", "already exists", "createTaskMatching", "work", testRuntimes)
+	mustFail(t, ledger, "Org1MSP", "Received unknown function", "noSuchFunction")
+
+	var history []historyEntry
+	checkPayload(t, ledger.Query("Org1MSP", "getHistory", "work"), &history)
+	if len(history) != 1 {
+		t.Fatalf("job has %d versions, expected 1", len(history))
+	}
+
+	solveAll(t, ledger, "work")
+	checkPayload(t, ledger.Query("Org1MSP", "getHistory", "work"), &history)
+	if len(history) != 3 {
+		t.Fatalf("job has %d versions after solving, expected created, solving and closed", len(history))
+	}
+}
+
+func TestProtocolSolverOrgs(t *testing.T) {
+	ledger := newTestLedger(t)
+	mustInvoke(t, ledger, "Org1MSP", "mint", "Org1MSP", "30")
+	mustInvoke(t, ledger, "Org1MSP", "createTaskMatching", "work", testRuntimes, "", "", "", "30")
+
+	//nobody gets to submit for the solvers of another org and collect their rewards
+	mustFail(t, ledger, "Org2MSP", "only Org1MSP", "calculateTaskMatching", "p1")
+	mustFail(t, ledger, "Org3MSP", "only Org2MSP", "submitSolution", "p2", "work", "[0,1,2]")
+	solveAll(t, ledger, "work")
+
+	var sol TaskMatchingSol
+	readRecord(t, ledger, &sol, "solution", "work", "1")
+	winner := solverOrgs["p"+strings.TrimPrefix(sol.Owner, "Peer ")]
+	if b := balance(t, ledger, winner); b != 30 {
+		t.Fatalf("%s won with %s and has %d, expected the bounty of 30", winner, sol.Owner, b)
+	}
+
+	mustInvoke(t, ledger, "Org1MSP", "createTaskMatching", "work2", testRuntimes, "60", "30")
+	mustFail(t, ledger, "Org2MSP", "only Org3MSP", "commitSolution", "p3", "work2", commitmentHash("p3", "work2", "Org3MSP", "salt", "[0,1,2]"))
+}
+
+func TestProtocolSubmitSolution(t *testing.T) {
+	ledger := newTestLedger(t)
+	mustInvoke(t, ledger, "Org1MSP", "createTaskMatching", "work", testRuntimes)
+
+	mustInvoke(t, ledger, "Org1MSP", "submitSolution", "p1", "work", "[0,1,2]", "pso")
+	mustFail(t, ledger, "Org1MSP", "already submitted", "submitSolution", "p1", "work", "[0,0,0]")
+
+	var peer Peer
+	readRecord(t, ledger, &peer, "solver", "p1")
+	if peer.Status != "done" || peer.Runtime != 9 || peer.Algorithm != "pso" || peer.Org != "Org1MSP" {
+		t.Fatalf("p1 after its submission: %+v", peer)
+	}
+
+	//an invalid assignment is recorded like a revealed one, the solver is done
+	if payload := mustInvoke(t, ledger, "Org2MSP", "submitSolution", "p2", "work", "[0,1,3]"); !strings.HasPrefix(string(payload), "Invalid solution: ") ||
+		!strings.Contains(string(payload), "resource 3") {
+		t.Fatalf("invalid assignment answered with %q", payload)
+	}
+	readRecord(t, ledger, &peer, "solver", "p2")
+	if peer.Status != "invalid" || peer.Org != "Org2MSP" {
+		t.Fatalf("p2 after an invalid submission: %+v", peer)
+	}
+	mustFail(t, ledger, "Org2MSP", "already submitted", "submitSolution", "p2", "work", "[2,2,2]")
+
+	//the last submission closes the job, the algorithm of p1 is recorded with its solution
+	mustInvoke(t, ledger, "Org3MSP", "calculateTaskMatching", "p3", "work")
+	var sol TaskMatchingSol
+	readRecord(t, ledger, &sol, "solution", "work", "1")
+	if sol.Owner != "Peer 1" || sol.Algorithm != "pso" {
+		t.Fatalf("solution of %s found with %s, expected Peer 1 with pso", sol.Owner, sol.Algorithm)
+	}
+
+	//the stub of p3 hands in nothing, that isn't an invalid submission
+	var stats SolverStats
+	checkPayload(t, ledger.Query("Org1MSP", "getSolverStats", "p2"), &stats)
+	if stats.Invalid != 1 || stats.MissedDeadlines != 0 {
+		t.Fatalf("p2 has %d invalid submissions and %d missed deadlines, expected 1 and 0", stats.Invalid, stats.MissedDeadlines)
+	}
+	checkPayload(t, ledger.Query("Org1MSP", "getSolverStats", "p3"), &stats)
+	if stats.Invalid != 0 || stats.MissedDeadlines != 1 {
+		t.Fatalf("p3 has %d invalid submissions and %d missed deadlines, expected 0 and 1", stats.Invalid, stats.MissedDeadlines)
+	}
+
+	mustInvoke(t, ledger, "Org1MSP", "createTaskMatching", "work2", testRuntimes, "60", "30")
+	mustFail(t, ledger, "Org1MSP", "commit phase", "submitSolution", "p1", "work2", "[0,1,2]")
+}
+
+// queryJobs runs a query over the saved solutions and returns the jobs they were saved
+// for, in the order of the page, and the bookmark of the next page.
+func queryJobs(t *testing.T, ledger *memledger.Ledger, args ...string) ([]string, string) {
+	t.Helper()
+	var page solutionPage
+	checkPayload(t, ledger.Query("Org1MSP", args...), &page)
+	jobs := []string{}
+	for _, entry := range page.Solutions {
+		jobs = append(jobs, entry.Job)
+	}
+	return jobs, page.Bookmark
+}
+
+func TestProtocolSolutionQueries(t *testing.T) {
+	ledger := newTestLedger(t)
+
+	//a is won by Peer 3 with max-min and 7, b by Peer 1 with max-min and 3, c by Peer 1
+	//with sa and 12
+	submissions := []struct {
+		job, runtimes string
+		assignments   [3]string
+		algorithms    [3]string
+	}{
+		{"a", testRuntimes, [3]string{"[0,0,0]", "[0,1,2]", "[2,1,0]"}, [3]string{"sa", "sa", "max-min"}},
+		{"b", "[[1,2],[3,4]]", [3]string{"[1,0]", "[0,0]", "[1,1]"}, [3]string{"max-min", "min-min", "sa"}},
+		{"c", testRuntimes, [3]string{"[0,0,0]", "[2,2,2]", "[1,1,1]"}, [3]string{"sa", "max-min", "sa"}},
+	}
+	for _, s := range submissions {
+		mustInvoke(t, ledger, "Org1MSP", "createTaskMatching", s.job, s.runtimes)
+		for i, peer := range peerArray {
+			mustInvoke(t, ledger, solverOrgs[peer], "submitSolution", peer, s.job, s.assignments[i], s.algorithms[i])
+		}
+	}
+
+	if jobs, _ := queryJobs(t, ledger, "querySolutionsByAlgorithm", "max-min"); !reflect.DeepEqual(jobs, []string{"b", "a"}) {
+		t.Errorf("solutions won with max-min are of %v, expected b and a", jobs)
+	}
+	if jobs, _ := queryJobs(t, ledger, "querySolutionsByAlgorithm", "min-min"); len(jobs) != 0 {
+		t.Errorf("solutions won with min-min are of %v, expected none", jobs)
+	}
+	if jobs, _ := queryJobs(t, ledger, "querySolutionsByOwner", "Peer 1"); !reflect.DeepEqual(jobs, []string{"b", "c"}) {
+		t.Errorf("solutions of Peer 1 are of %v, expected b and c", jobs)
+	}
+	if jobs, _ := queryJobs(t, ledger, "querySolutionsByOwner", "Peer 1", "10"); !reflect.DeepEqual(jobs, []string{"b"}) {
+		t.Errorf("solutions of Peer 1 below 10 are of %v, expected b", jobs)
+	}
+
+	jobs, bookmark := queryJobs(t, ledger, "queryTopJobsByMakespan", "2")
+	if !reflect.DeepEqual(jobs, []string{"c", "a"}) {
+		t.Fatalf("top 2 makespans are of %v, expected c and a", jobs)
+	}
+	if jobs, _ = queryJobs(t, ledger, "queryTopJobsByMakespan", "2", bookmark); !reflect.DeepEqual(jobs, []string{"b"}) {
+		t.Errorf("next page of makespans is of %v, expected b", jobs)
+	}
+
+	//the improvement of c to 7 replaces its solution with 12 in the ranking
+	number := string(mustInvoke(t, ledger, "Org4MSP", "improveSolution", "c", "[2,1,0]"))
+	var page solutionPage
+	checkPayload(t, ledger.Query("Org1MSP", "queryTopJobsByMakespan"), &page)
+	got := []string{}
+	for _, entry := range page.Solutions {
+		got = append(got, entry.Job+"/"+strconv.Itoa(entry.Number)+"/"+strconv.Itoa(entry.Record.Runtime))
+	}
+	if expected := []string{"a/1/7", "c/" + number + "/7", "b/2/3"}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("makespans after the improvement are %v, expected %v", got, expected)
+	}
+}
+
+func TestProtocolImprovement(t *testing.T) {
+	ledger := newTestLedger(t)
+	mustInvoke(t, ledger, "Org1MSP", "setImprovementRule", "1", "10")
+
+	//every new job holds the improvement reward
+	mustInvoke(t, ledger, "Org1MSP", "mint", "Org1MSP", "5")
+	mustFail(t, ledger, "Org1MSP", "improvement reward", "createTaskMatching", "work", testRuntimes)
+	mustInvoke(t, ledger, "Org1MSP", "mint", "Org1MSP", "10")
+	mustInvoke(t, ledger, "Org1MSP", "createTaskMatching", "work", testRuntimes)
+	mustInvoke(t, ledger, "Org1MSP", "submitSolution", "p1", "work", "[0,0,0]")
+	mustInvoke(t, ledger, "Org2MSP", "submitSolution", "p2", "work", "[2,2,2]")
+	mustInvoke(t, ledger, "Org3MSP", "submitSolution", "p3", "work", "[1,1,1]")
+
+	mustFail(t, ledger, "Org2MSP", "does not beat", "improveSolution", "work", "[1,1,1]")
+
+	//orgs without a solver can improve too, spending the creator's balance doesn't take the reward
+	mustInvoke(t, ledger, "Org1MSP", "transfer", "Org2MSP", "5")
+	payload := mustInvoke(t, ledger, "Org4MSP", "improveSolution", "work", "[2,1,0]")
+	var sol TaskMatchingSol
+	readRecord(t, ledger, &sol, "solution", "work", string(payload))
+	if sol.Owner != "Org4MSP" || sol.Runtime != 7 || sol.ImprovedFrom != 1 {
+		t.Fatalf("improvement owned by %s with makespan %d improving on %d", sol.Owner, sol.Runtime, sol.ImprovedFrom)
+	}
+	var job TaskMatching
+	readRecord(t, ledger, &job, "work")
+	if b1, b4 := balance(t, ledger, "Org1MSP"), balance(t, ledger, "Org4MSP"); b1 != 0 || b4 != 10 || job.ImprovementPool != 0 {
+		t.Fatalf("balances %d and %d and a pool of %d after the improvement, expected 0, 10 and 0", b1, b4, job.ImprovementPool)
+	}
+	var stats SolverStats
+	checkPayload(t, ledger.Query("Org1MSP", "getSolverStats", "Org4MSP"), &stats)
+	if stats.Improvements != 1 {
+		t.Fatalf("Org4MSP has %d improvements", stats.Improvements)
+	}
+	if res := ledger.Query("Org1MSP", "getSolverStats", "Org5MSP"); res.Status == shim.OK {
+		t.Fatalf("statistics of an org that never improved: %s", res.Payload)
+	}
+
+	//a cancelled job gives the pool back
+	mustInvoke(t, ledger, "Org1MSP", "mint", "Org1MSP", "10")
+	mustInvoke(t, ledger, "Org1MSP", "createTaskMatching", "work2", testRuntimes)
+	mustInvoke(t, ledger, "Org1MSP", "cancelJob", "work2")
+	if b1 := balance(t, ledger, "Org1MSP"); b1 != 10 {
+		t.Fatalf("balance %d after cancelling, expected 10", b1)
+	}
+}
+
+func TestProtocolJobEvents(t *testing.T) {
+	ledger := newTestLedger(t)
+	mustInvoke(t, ledger, "Org1MSP", "createTaskMatching", "work", testRuntimes)
+	solveAll(t, ledger, "work")
+	mustInvoke(t, ledger, "Org1MSP", "createTaskMatching", "work2", testRuntimes)
+	mustInvoke(t, ledger, "Org1MSP", "cancelJob", "work2")
+
+	var got []string
+	for _, event := range ledger.Events() {
+		var payload jobEvent
+		if err := json.Unmarshal(event.Payload, &payload); err != nil {
+			t.Fatalf("%s payload %s: %s", event.Name, event.Payload, err)
+		}
+		got = append(got, event.Name+" "+payload.Job+" "+payload.State)
+	}
+	expected := "jobCreated work OPEN,solutionSubmitted work SOLVING,solutionSubmitted work SOLVING,jobFinished work CLOSED," +
+		"jobCreated work2 OPEN,jobFinished work2 CANCELLED"
+	if strings.Join(got, ",") != expected {
+		t.Fatalf("events %v, expected %s", got, expected)
+	}
+}
+
+func TestProtocolDynamic(t *testing.T) {
+	ledger := newTestLedger(t)
+	start := int(ledger.Now().Unix())
+	mustInvoke(t, ledger, "Org1MSP", "createTaskMatching", "work", testRuntimes)
+
+	mustFail(t, ledger, "Org2MSP", "Only Org1MSP", "addTasks", "work", "[[1,1,1]]")
+	mustFail(t, ledger, "Org1MSP", "3 resources", "addTasks", "work", "[[1,1]]")
+	mustFail(t, ledger, "Org1MSP", "interval", "setDynamicMode", "work", "min-min")
+	mustFail(t, ledger, "Org1MSP", "Unknown policy", "setDynamicMode", "work", "fifo")
+	mustFail(t, ledger, "Org1MSP", "not in dynamic mode", "mapTasks", "work")
+
+	//the tasks the job has are mapped right away, task 1 ties on resources 0 and 1
+	mustInvoke(t, ledger, "Org1MSP", "setDynamicMode", "work", "mct")
+	var mapping TaskMapping
+	readRecord(t, ledger, &mapping, "mapping", "work")
+	if !reflect.DeepEqual(mapping.Assignment, []int{0, 0, 1}) || !reflect.DeepEqual(mapping.Ready, []int{start + 5, start + 8, start}) {
+		t.Fatalf("mct mapped %v with ready times %v", mapping.Assignment, mapping.Ready)
+	}
+
+	//resources that are idle by then are ready when the task arrives
+	ledger.Advance(10 * time.Second)
+	mustInvoke(t, ledger, "Org1MSP", "addTasks", "work", "[[2,1,1]]")
+	readRecord(t, ledger, &mapping, "mapping", "work")
+	if !reflect.DeepEqual(mapping.Assignment, []int{0, 0, 1, 1}) || !reflect.DeepEqual(mapping.Ready, []int{start + 10, start + 11, start + 10}) {
+		t.Fatalf("mct mapped %v with ready times %v", mapping.Assignment, mapping.Ready)
+	}
+	//the solvers get the full time for the grown job
+	var job TaskMatching
+	readRecord(t, ledger, &job, "work")
+	if job.State != jobOpen || job.Runtimes != "[[1,2,3],[4,5,6],[7,8,9],[2,1,1]]" || job.RuntimesHash != runtimesHash(job.Runtimes) ||
+		job.Deadline != int64(start+10+defaultJobTimeout) || job.CommitDeadline != job.Created {
+		t.Fatalf("job is %s with runtimes %s and deadline %d after adding a task", job.State, job.Runtimes, job.Deadline)
+	}
+
+	//a batch waits for the interval
+	mustInvoke(t, ledger, "Org1MSP", "setDynamicMode", "work", "sufferage", "60")
+	ledger.Advance(10 * time.Second)
+	mustInvoke(t, ledger, "Org1MSP", "addTasks", "work", "[[3,3,3],[1,9,9]]")
+	readRecord(t, ledger, &mapping, "mapping", "work")
+	if len(mapping.Assignment) != 4 || mapping.Pending != 2 {
+		t.Fatalf("%d tasks mapped and %d pending before the batch is due", len(mapping.Assignment), mapping.Pending)
+	}
+	mustFail(t, ledger, "Org2MSP", "is due at", "mapTasks", "work")
+	ledger.Advance(50 * time.Second)
+	mustInvoke(t, ledger, "Org2MSP", "mapTasks", "work")
+	readRecord(t, ledger, &mapping, "mapping", "work")
+	now := start + 70
+	if !reflect.DeepEqual(mapping.Assignment, []int{0, 0, 1, 1, 1, 0}) || !reflect.DeepEqual(mapping.Ready, []int{now + 1, now + 3, now}) || mapping.Pending != 0 {
+		t.Fatalf("sufferage mapped %v with ready times %v", mapping.Assignment, mapping.Ready)
+	}
+	mustFail(t, ledger, "Org2MSP", "no tasks waiting", "mapTasks", "work")
+
+	events := ledger.Events()
+	var event mappingEvent
+	last := events[len(events)-1]
+	if err := json.Unmarshal(last.Payload, &event); err != nil || last.Name != tasksMappedEvent ||
+		!reflect.DeepEqual(event.Tasks, []int{4, 5}) || !reflect.DeepEqual(event.Resources, []int{1, 0}) {
+		t.Fatalf("last event %s %s", last.Name, last.Payload)
+	}
+
+	//the solvers work on the grown job, their submissions are for all of its tasks
+	mustInvoke(t, ledger, "Org1MSP", "calculateTaskMatching", "p1")
+	mustFail(t, ledger, "Org1MSP", "already has submissions", "addTasks", "work", "[[1,1,1]]")
+	mustInvoke(t, ledger, "Org2MSP", "calculateTaskMatching", "p2")
+	mustInvoke(t, ledger, "Org3MSP", "calculateTaskMatching", "p3")
+	var sol TaskMatchingSol
+	readRecord(t, ledger, &sol, "solution", "work", "1")
+	if len(sol.Solution) != 6 {
+		t.Fatalf("solution for %d tasks, the job has 6", len(sol.Solution))
+	}
+	mustFail(t, ledger, "Org1MSP", "CLOSED", "addTasks", "work", "[[1,1,1]]")
+
+	//no tasks once the deadline passed, closing it refunds the bounty
+	mustInvoke(t, ledger, "Org1MSP", "createTaskMatching", "late", testRuntimes, "30")
+	ledger.Advance(31 * time.Second)
+	mustFail(t, ledger, "Org1MSP", "passed its deadline", "addTasks", "late", "[[1,1,1]]")
+
+	//a commit phase starts over too
+	mustInvoke(t, ledger, "Org2MSP", "closeJob", "late")
+	mustInvoke(t, ledger, "Org1MSP", "createTaskMatching", "phased", testRuntimes, "30", "20")
+	ledger.Advance(10 * time.Second)
+	mustInvoke(t, ledger, "Org1MSP", "addTasks", "phased", "[[1,1,1]]")
+	readRecord(t, ledger, &job, "phased")
+	if now := ledger.Now().Unix(); job.CommitDeadline != now+20 || job.Deadline != now+50 {
+		t.Fatalf("commit deadline %d and deadline %d after adding a task at %d", job.CommitDeadline, job.Deadline, now)
+	}
+}
+
+func TestProtocolDynamicPrivate(t *testing.T) {
+	ledger := newTestLedger(t)
+	res := ledger.InvokeWithTransient("Org1MSP", map[string][]byte{runtimesTransientKey: []byte(testRuntimes)},
+		"createTaskMatching", "work", "", "", "", "etcOrg1Org2")
+	if res.Status != shim.OK {
+		t.Fatalf("creating a private job failed: %s", res.Message)
+	}
+	mustFail(t, ledger, "Org1MSP", "must not be passed", "addTasks", "work", "[[1,1,1]]")
+	res = ledger.InvokeWithTransient("Org1MSP", map[string][]byte{runtimesTransientKey: []byte("[[1,1,1]]")}, "addTasks", "work", "")
+	if res.Status != shim.OK {
+		t.Fatalf("adding private tasks failed: %s", res.Message)
+	}
+
+	var job TaskMatching
+	readRecord(t, ledger, &job, "work")
+	res = ledger.Query("Org2MSP", "readJobRuntimes", "work")
+	if job.Runtimes != "" || string(res.Payload) != "[[1,2,3],[4,5,6],[7,8,9],[1,1,1]]" {
+		t.Fatalf("runtimes %q in public state, %q in the collection: %s", job.Runtimes, res.Payload, res.Message)
+	}
+}
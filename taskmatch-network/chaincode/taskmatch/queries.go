@@ -0,0 +1,339 @@
+package taskmatch
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	pb "github.com/hyperledger/fabric/protos/peer"
+)
+
+// defaultPageSize is how many records a listing returns when no page size is given.
+const defaultPageSize = 10
+
+// historyEntry is one version of a key as returned by getHistory.
+type historyEntry struct {
+	TxID      string          `json:"txId"`
+	Timestamp string          `json:"timestamp"`
+	IsDelete  bool            `json:"isDelete"`
+	Value     json.RawMessage `json:"value"`
+}
+
+// solutionEntry is a TaskMatchingSol together with the job and number it is stored under.
+type solutionEntry struct {
+	Job    string          `json:"job"`
+	Number int             `json:"number"`
+	Record TaskMatchingSol `json:"record"`
+}
+
+// solutionPage is the result of listSolutions. Bookmark is passed back to get the
+// following page.
+type solutionPage struct {
+	Solutions []solutionEntry `json:"solutions"`
+	Bookmark  string          `json:"bookmark"`
+}
+
+// ============================================================
+// getHistory - every version of a record (job, solver or solution) with the transaction
+// that wrote it, oldest first. The record is named like in readTaskMatching.
+// ============================================================
+func (t *SimpleChaincode) getHistory(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	if len(args) < 1 {
+		return shim.Error("Incorrect number of arguments. Expecting the record to get the history of")
+	}
+
+	if len(args) == 1 {
+		args = []string{jobObjectType, args[0]}
+	}
+	key, err := recordKey(stub, args[0], args[1:])
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	resultsIterator, err := stub.GetHistoryForKey(key)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	defer resultsIterator.Close()
+
+	history := []historyEntry{}
+	for resultsIterator.HasNext() {
+		modification, err := resultsIterator.Next()
+		if err != nil {
+			return shim.Error(err.Error())
+		}
+
+		entry := historyEntry{TxID: modification.TxId, IsDelete: modification.IsDelete}
+		if modification.Timestamp != nil {
+			entry.Timestamp = time.Unix(modification.Timestamp.Seconds, int64(modification.Timestamp.Nanos)).UTC().Format(time.RFC3339Nano)
+		}
+		if !modification.IsDelete {
+			entry.Value = json.RawMessage(modification.Value)
+		}
+		history = append(history, entry)
+	}
+
+	historyAsBytes, err := json.Marshal(history)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	return shim.Success(historyAsBytes)
+}
+
+// ============================================================
+// listSolutions - page through the saved solutions of one job, or of all jobs when no
+// job id is given, in the order they were saved
+// ============================================================
+func (t *SimpleChaincode) listSolutions(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	//   0            1            2
+	//[job id]   [page size]   [bookmark]
+	if len(args) > 3 {
+		return shim.Error("Incorrect number of arguments. Expecting an optional job id, page size and bookmark")
+	}
+
+	var attributes []string
+	if len(args) >= 1 && args[0] != "" {
+		attributes = []string{args[0]}
+	}
+
+	pageSize := defaultPageSize
+	var err error
+	if len(args) >= 2 {
+		pageSize, err = strconv.Atoi(args[1])
+		if err != nil || pageSize < 1 {
+			return shim.Error("2nd argument must be a positive numeric string")
+		}
+	}
+
+	bookmark := ""
+	if len(args) == 3 {
+		bookmark = args[2]
+	}
+
+	resultsIterator, metadata, err := stub.GetStateByPartialCompositeKeyWithPagination(solutionObjectType, attributes, int32(pageSize), bookmark)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	defer resultsIterator.Close()
+
+	page, err := solutionPageFromIterator(stub, resultsIterator, metadata)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	pageAsBytes, err := json.Marshal(page)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	return shim.Success(pageAsBytes)
+}
+
+// ============================================================
+// Rich queries - these need CouchDB as the state database, the indexes they use are in
+// META-INF/statedb/couchdb/indexes
+// ============================================================
+
+// querySolutionsByAlgorithm - solutions won by an algorithm, e.g. "max-min", best first
+func (t *SimpleChaincode) querySolutionsByAlgorithm(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	//    0            1            2
+	//algorithm  [page size]   [bookmark]
+	if len(args) < 1 || len(args) > 3 {
+		return shim.Error("Incorrect number of arguments. Expecting algorithm and optionally page size and bookmark")
+	}
+
+	query := map[string]interface{}{
+		"selector":  map[string]interface{}{"docType": solutionObjectType, "alg": args[0]},
+		"sort":      []map[string]string{{"docType": "asc"}, {"alg": "asc"}, {"runtime": "asc"}},
+		"use_index": []string{"_design/indexAlgorithmDoc", "indexAlgorithm"},
+	}
+	return querySolutions(stub, query, args[1:])
+}
+
+// querySolutionsByOwner - solutions won by a peer, e.g. "Peer 1", or improved by an org,
+// e.g. "Org4MSP", optionally only those
+// with a makespan below a bound, best first
+func (t *SimpleChaincode) querySolutionsByOwner(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	//  0         1              2            3
+	//owner  [max runtime]  [page size]  [bookmark]
+	if len(args) < 1 || len(args) > 4 {
+		return shim.Error("Incorrect number of arguments. Expecting owner and optionally max runtime, page size and bookmark")
+	}
+
+	selector := map[string]interface{}{"docType": solutionObjectType, "owner": args[0]}
+	if len(args) >= 2 && args[1] != "" {
+		maxRuntime, err := strconv.Atoi(args[1])
+		if err != nil {
+			return shim.Error("2nd argument must be a numeric string")
+		}
+		selector["runtime"] = map[string]int{"$lt": maxRuntime}
+	}
+
+	var pageArgs []string
+	if len(args) > 2 {
+		pageArgs = args[2:]
+	}
+
+	query := map[string]interface{}{
+		"selector":  selector,
+		"sort":      []map[string]string{{"docType": "asc"}, {"owner": "asc"}, {"runtime": "asc"}},
+		"use_index": []string{"_design/indexOwnerDoc", "indexOwner"},
+	}
+	return querySolutions(stub, query, pageArgs)
+}
+
+// queryTopJobsByMakespan - the best solutions of the closed jobs, the job with the longest
+// makespan first, the page size is the N of the top N. Solutions replaced by an
+// improvement are left out, the query runs over the jobs and their bestRuntime.
+func (t *SimpleChaincode) queryTopJobsByMakespan(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	//    0            1
+	//[page size]  [bookmark]
+	if len(args) > 2 {
+		return shim.Error("Incorrect number of arguments. Expecting an optional page size and bookmark")
+	}
+	pageSize, bookmark, err := parsePage(args)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	//only jobs with a best solution have a bestRuntime
+	query := map[string]interface{}{
+		"selector":  map[string]interface{}{"bestRuntime": map[string]int{"$gt": 0}},
+		"sort":      []map[string]string{{"bestRuntime": "desc"}},
+		"use_index": []string{"_design/indexBestRuntimeDoc", "indexBestRuntime"},
+	}
+	queryString, err := json.Marshal(query)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	resultsIterator, metadata, err := stub.GetQueryResultWithPagination(string(queryString), int32(pageSize), bookmark)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	defer resultsIterator.Close()
+
+	page := &solutionPage{Solutions: []solutionEntry{}}
+	if metadata != nil {
+		page.Bookmark = metadata.Bookmark
+	}
+	for resultsIterator.HasNext() {
+		kv, err := resultsIterator.Next()
+		if err != nil {
+			return shim.Error(err.Error())
+		}
+		_, keyParts, err := stub.SplitCompositeKey(kv.Key)
+		if err != nil {
+			return shim.Error(err.Error())
+		}
+		if len(keyParts) != 1 {
+			return shim.Error(kv.Key + " is not a job key")
+		}
+		job := TaskMatching{}
+		if err := json.Unmarshal(kv.Value, &job); err != nil {
+			return shim.Error(err.Error())
+		}
+
+		entry := solutionEntry{Job: keyParts[0], Number: job.BestSolution}
+		solKey, err := solutionKey(stub, entry.Job, entry.Number)
+		if err != nil {
+			return shim.Error(err.Error())
+		}
+		solAsBytes, err := stub.GetState(solKey)
+		if err != nil {
+			return shim.Error(err.Error())
+		} else if solAsBytes == nil {
+			return shim.Error("Best solution " + strconv.Itoa(entry.Number) + " of job " + entry.Job + " does not exist")
+		}
+		if err := json.Unmarshal(solAsBytes, &entry.Record); err != nil {
+			return shim.Error(err.Error())
+		}
+		page.Solutions = append(page.Solutions, entry)
+	}
+
+	pageAsBytes, err := json.Marshal(page)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	return shim.Success(pageAsBytes)
+}
+
+// querySolutions runs a CouchDB query over solutions, args are the optional page size
+// and bookmark
+func querySolutions(stub shim.ChaincodeStubInterface, query map[string]interface{}, args []string) pb.Response {
+	pageSize, bookmark, err := parsePage(args)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	//marshalling the query keeps user input from changing its structure
+	queryString, err := json.Marshal(query)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	resultsIterator, metadata, err := stub.GetQueryResultWithPagination(string(queryString), int32(pageSize), bookmark)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	defer resultsIterator.Close()
+
+	page, err := solutionPageFromIterator(stub, resultsIterator, metadata)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	pageAsBytes, err := json.Marshal(page)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	return shim.Success(pageAsBytes)
+}
+
+// parsePage reads the optional page size and bookmark of a paginated query.
+func parsePage(args []string) (int, string, error) {
+	pageSize := defaultPageSize
+	bookmark := ""
+	if len(args) >= 1 && args[0] != "" {
+		var err error
+		pageSize, err = strconv.Atoi(args[0])
+		if err != nil || pageSize < 1 {
+			return 0, "", fmt.Errorf("the page size must be a positive numeric string")
+		}
+	}
+	if len(args) >= 2 {
+		bookmark = args[1]
+	}
+	return pageSize, bookmark, nil
+}
+
+// solutionPageFromIterator reads the solutions of a paginated query into a solutionPage.
+func solutionPageFromIterator(stub shim.ChaincodeStubInterface, resultsIterator shim.StateQueryIteratorInterface, metadata *pb.QueryResponseMetadata) (*solutionPage, error) {
+	page := &solutionPage{Solutions: []solutionEntry{}}
+	if metadata != nil {
+		page.Bookmark = metadata.Bookmark
+	}
+
+	for resultsIterator.HasNext() {
+		kv, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		_, keyParts, err := stub.SplitCompositeKey(kv.Key)
+		if err != nil {
+			return nil, err
+		}
+		if len(keyParts) != 2 {
+			return nil, fmt.Errorf("%s is not a solution key", kv.Key)
+		}
+		entry := solutionEntry{Job: keyParts[0]}
+		entry.Number, _ = strconv.Atoi(keyParts[1])
+		if err := json.Unmarshal(kv.Value, &entry.Record); err != nil {
+			return nil, err
+		}
+		page.Solutions = append(page.Solutions, entry)
+	}
+	return page, nil
+}
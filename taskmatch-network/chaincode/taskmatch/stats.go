@@ -0,0 +1,166 @@
+package taskmatch
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	pb "github.com/hyperledger/fabric/protos/peer"
+)
+
+/**************************************************
+ **              Solver Statistics              **
+**************************************************/
+
+// SolverStats is kept per solver under stats~<peer id> and updated whenever a job the
+// solver was taking part in is closed or expires. Cancelled jobs don't count. Improvements
+// are handed in by orgs, they are counted under stats~<MSP id>.
+type SolverStats struct {
+	Solver          string  `json:"solver"`
+	Jobs            int     `json:"jobs"`            //jobs taken part in
+	Wins            int     `json:"wins"`            //jobs where it had the best solution
+	Solved          int     `json:"solved"`          //jobs where it handed in a valid solution
+	TotalGap        float64 `json:"totalGap"`        //sum of the gaps of its valid solutions to the best one
+	AvgGap          float64 `json:"avgGap"`          //average gap in percent of the best makespan
+	MissedDeadlines int     `json:"missedDeadlines"` //jobs it never submitted (or revealed) a solution for
+	Invalid         int     `json:"invalid"`         //submissions that were rejected
+	Improvements    int     `json:"improvements"`    //accepted improvements of closed jobs
+}
+
+func statsKey(stub shim.ChaincodeStubInterface, peerID string) (string, error) {
+	return stub.CreateCompositeKey(statsObjectType, []string{peerID})
+}
+
+func getSolverStats(stub shim.ChaincodeStubInterface, peerID string) (*SolverStats, error) {
+	key, err := statsKey(stub, peerID)
+	if err != nil {
+		return nil, err
+	}
+	statsAsBytes, err := stub.GetState(key)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &SolverStats{Solver: peerID}
+	if statsAsBytes != nil {
+		if err := json.Unmarshal(statsAsBytes, stats); err != nil {
+			return nil, err
+		}
+	}
+	return stats, nil
+}
+
+func putSolverStats(stub shim.ChaincodeStubInterface, stats *SolverStats) error {
+	key, err := statsKey(stub, stats.Solver)
+	if err != nil {
+		return err
+	}
+	statsAsBytes, err := json.Marshal(stats)
+	if err != nil {
+		return err
+	}
+	return stub.PutState(key, statsAsBytes)
+}
+
+// updateSolverStats records the outcome of a finished job for every solver taking part
+// in it. valid are the solvers with a valid solution, winner is the name of the one with
+// the best makespan best. An expired job has no valid solutions and no winner. A solver
+// that handed in no solution at all, like calculateTaskMatching does for p3 with a
+// makespan of -1, missed the deadline.
+func updateSolverStats(stub shim.ChaincodeStubInterface, jobID string, valid []*Peer, winner string, best int) error {
+	runtimes := map[string]int{}
+	for _, p := range valid {
+		runtimes[p.Name] = p.Runtime
+	}
+
+	for i := 0; i < len(peerArray); i++ {
+		tmpPeer, err := getPeer(stub, peerArray[i])
+		if err != nil {
+			return err
+		}
+		if tmpPeer.Job != jobID {
+			continue
+		}
+
+		stats, err := getSolverStats(stub, peerArray[i])
+		if err != nil {
+			return err
+		}
+		stats.Jobs++
+
+		if runtime, ok := runtimes[tmpPeer.Name]; ok {
+			stats.Solved++
+			if tmpPeer.Name == winner {
+				stats.Wins++
+			}
+			if best > 0 {
+				stats.TotalGap += float64(runtime-best) / float64(best) * 100
+			}
+			stats.AvgGap = stats.TotalGap / float64(stats.Solved)
+		} else if (tmpPeer.Status == "done" && tmpPeer.Runtime >= 0) || tmpPeer.Status == "invalid" {
+			stats.Invalid++
+		} else {
+			stats.MissedDeadlines++
+		}
+
+		if err := putSolverStats(stub, stats); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// countImprovement credits an org with an accepted improvement.
+func countImprovement(stub shim.ChaincodeStubInterface, mspID string) error {
+	stats, err := getSolverStats(stub, mspID)
+	if err != nil {
+		return err
+	}
+	stats.Improvements++
+	return putSolverStats(stub, stats)
+}
+
+// ============================================================
+// getSolverStats - the statistics of one solver or of an org that improved solutions, or
+// of all solvers
+// ============================================================
+func (t *SimpleChaincode) getSolverStats(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	if len(args) > 1 {
+		return shim.Error("Incorrect number of arguments. Expecting an optional solver id or MSP id")
+	}
+
+	solvers := peerArray
+	if len(args) == 1 {
+		if _, err := getPeer(stub, args[0]); err != nil {
+			//orgs only have statistics once they improved a solution
+			key, keyErr := statsKey(stub, args[0])
+			if keyErr != nil {
+				return shim.Error(keyErr.Error())
+			}
+			statsAsBytes, stateErr := stub.GetState(key)
+			if stateErr != nil || statsAsBytes == nil {
+				return shim.Error(err.Error())
+			}
+		}
+		solvers = args
+	}
+
+	all := []*SolverStats{}
+	for _, peerID := range solvers {
+		stats, err := getSolverStats(stub, peerID)
+		if err != nil {
+			return shim.Error(err.Error())
+		}
+		all = append(all, stats)
+	}
+
+	var result interface{} = all
+	if len(args) == 1 {
+		result = all[0]
+	}
+	resultAsBytes, err := json.Marshal(result)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("failed to marshal solver statistics: %s", err))
+	}
+	return shim.Success(resultAsBytes)
+}
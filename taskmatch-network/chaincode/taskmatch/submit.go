@@ -0,0 +1,102 @@
+package taskmatch
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/chaincode/scheduling"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	pb "github.com/hyperledger/fabric/protos/peer"
+)
+
+/**************************************************
+ **          Solutions From Off The Chain        **
+**************************************************/
+
+// ============================================================
+// submitSolution - hand in an assignment a solver computed itself, e.g. with a solver
+// daemon, instead of having calculateTaskMatching run a heuristic on the peer. The
+// makespan is recomputed here. An assignment that isn't valid is recorded like in
+// revealSolution, the solver can't submit again for the job. Jobs with a commit phase
+// take commitSolution and revealSolution instead.
+// ============================================================
+func (t *SimpleChaincode) submitSolution(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	//  0     1        2             3
+	//peer  job   assignment   [algorithm]
+	if len(args) < 3 || len(args) > 4 {
+		return shim.Error("Incorrect number of arguments. Expecting peer, job id, assignment and an optional algorithm")
+	}
+	peerID, jobID, assignment := args[0], args[1], args[2]
+	algorithm := ""
+	if len(args) == 4 {
+		algorithm = args[3]
+	}
+
+	job, err := getJob(stub, jobID)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	if job.CommitDeadline != job.Created {
+		return shim.Error("Job " + jobID + " has a commit phase, use commitSolution and revealSolution")
+	}
+	if err := checkSubmissionWindow(stub, jobID, job); err != nil {
+		return shim.Error(err.Error())
+	}
+
+	peer, err := getPeer(stub, peerID)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	org, err := solverOrg(stub, peerID, peer)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	if peer.Job != jobID {
+		return shim.Error("Solver " + peerID + " is not taking part in job " + jobID)
+	}
+	if peer.Status != "waiting" {
+		return shim.Error("Solver " + peerID + " has already submitted for job " + jobID)
+	}
+
+	runtimes, err := jobRuntimes(stub, jobID, job)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	matrix := strToMatrix(runtimes)
+	var sol []int
+	err = json.Unmarshal([]byte(assignment), &sol)
+	if err == nil {
+		err = checkAssignment(matrix, sol)
+	} else {
+		err = fmt.Errorf("the assignment must be a JSON array of resource indices")
+	}
+
+	//an invalid assignment counts as an invalid submission in the solver's statistics
+	peer.Org = org
+	peer.Algorithm = algorithm
+	result := []byte(nil)
+	if err != nil {
+		peer.Status = "invalid"
+		result = []byte("Invalid solution: " + err.Error())
+	} else {
+		peer.Status = "done"
+		peer.Solution = sol
+		peer.Runtime = scheduling.Makespan(matrix, sol)
+	}
+	if err := putPeer(stub, peerID, peer); err != nil {
+		return shim.Error(err.Error())
+	}
+
+	//the first submission moves the job on to SOLVING
+	if job.State == jobOpen {
+		job.State = jobSolving
+		if err := putJob(stub, jobID, job); err != nil {
+			return shim.Error(err.Error())
+		}
+	}
+	if err := setSolverEvent(stub, solutionSubmittedEvent, jobID, job, peerID); err != nil {
+		return shim.Error(err.Error())
+	}
+
+	return shim.Success(result)
+}
@@ -0,0 +1,985 @@
+// Package taskmatch is the taskmatching chaincode. The chaincode installed on the peers,
+// github.com/chaincode/taskmatching, only starts it; keeping it in a package of its own
+// lets other programs run it on the in-memory ledger of memledger.
+package taskmatch
+
+import (
+	//"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/chaincode/scheduling"
+	"github.com/hyperledger/fabric/core/chaincode/lib/cid"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	pb "github.com/hyperledger/fabric/protos/peer"
+)
+
+// SimpleChaincode example simple Chaincode implementation
+type SimpleChaincode struct {
+}
+
+type TaskMatching struct {
+	identifier string `json:"id"`       //docType is used to distinguish the various types of objects in state database
+	Runtimes   string `json:"runtimes"` //the fieldtags are needed to keep case from bouncing around
+	State      string `json:"state"`    //where the job is in its lifecycle, see the job states below
+	Created    int64  `json:"created"`  //unix time of the transaction that created the job
+	Deadline   int64  `json:"deadline"` //unix time after which no more submissions are accepted
+
+	CommitDeadline int64 `json:"commitDeadline"` //unix time after which no more commitments are accepted
+
+	Collection   string `json:"collection"`   //private data collection holding the runtimes, "" if they are public
+	RuntimesHash string `json:"runtimesHash"` //sha256 of the runtimes, the only trace of private runtimes in public state
+
+	Creator     string `json:"creator"`     //MSP id of the org that created the job
+	Bounty      int64  `json:"bounty"`      //tokens held in escrow until the job is closed, see token.go
+	RewardRule  string `json:"rewardRule"`  //how the bounty is paid out
+	WinnerShare int    `json:"winnerShare"` //percent of the bounty the winner gets with the split rule
+
+	ImprovementPool int64 `json:"improvementPool"` //tokens held for the first accepted improvement, see improveSolution
+
+	BestSolution int `json:"bestSolution"` //number of the current best solution once the job is closed
+	BestRuntime  int `json:"bestRuntime"`  //its makespan, improveSolution has to beat it
+}
+
+type Peer struct {
+	identifier string `json:"id"`
+	Status     string `json:"status"`
+	Solution   []int  `json:"sol"`
+	Runtime    int    `json:"runtime"`
+	Name       string `json:"name"`
+	Job        string `json:"job"` //the job the status and solution belong to
+
+	Commitment string `json:"commitment"` //hash committed to in the commit phase, see commitSolution
+	Org        string `json:"org"`        //MSP id of the org that submitted for the job, it receives the rewards
+	Algorithm  string `json:"alg"`        //algorithm a solution computed off the chain was found with, see submitSolution
+
+	Owner string `json:"owner"` //MSP id of the org the solver belongs to, the only one that can submit for it
+}
+
+type TaskMatchingSol struct {
+	identifier string `json:"id"`
+	Runtime    int    `json:"runtime"`
+	Solution   []int  `json:"sol"`
+	Owner      string `json:"owner"`
+	Algorithm  string `json:"alg"`
+	Runtimes   string `json:"runtimes"`
+	DocType    string `json:"docType"` //always "solution", lets CouchDB queries tell solutions apart
+	Job        string `json:"job"`
+
+	RuntimesHash string `json:"runtimesHash"` //sha256 of the runtimes the makespan was checked against
+	ImprovedFrom int    `json:"improvedFrom"` //number of the solution this one improved on, 0 if it won the job
+}
+
+type Count struct {
+	identifier string `json:"id"`
+	Counter    int    `json:"count"`
+}
+
+// Job states. A job is OPEN when it is created and moves to SOLVING once the first
+// solver submits. It ends up CLOSED when a best solution has been picked, EXPIRED when
+// the deadline passed without any submission, or CANCELLED by a call to cancelJob.
+const (
+	jobOpen      = "OPEN"
+	jobSolving   = "SOLVING"
+	jobClosed    = "CLOSED"
+	jobExpired   = "EXPIRED"
+	jobCancelled = "CANCELLED"
+)
+
+// defaultJobTimeout is how many seconds solvers get to submit when createTaskMatching
+// is not given a timeout.
+const defaultJobTimeout = 300
+
+// defaultJob is the job calculateTaskMatching works on when no job id is given.
+const defaultJob = "work"
+
+var peerArray = []string{"p1", "p2", "p3"}
+
+// solverOrgs binds every solver to the org running it, like in the network started by
+// startNetwork.sh. Initialize writes it into the solver records; submissions for a solver
+// from any other org are rejected, so nobody can collect the rewards of another's slot.
+var solverOrgs = map[string]string{"p1": "Org1MSP", "p2": "Org2MSP", "p3": "Org3MSP"}
+
+// Init initializes chaincode
+// ===========================
+func (t *SimpleChaincode) Init(stub shim.ChaincodeStubInterface) pb.Response {
+
+	return shim.Success(nil)
+}
+
+// Invoke - Our entry point for Invocations
+// ========================================
+func (t *SimpleChaincode) Invoke(stub shim.ChaincodeStubInterface) pb.Response {
+	stub = newTxStub(stub) //reads must see this transaction's own writes, see txstub.go
+	function, args := stub.GetFunctionAndParameters()
+	fmt.Println("invoke is running " + function)
+
+	// Handle different functions
+	if function == "createTaskMatching" { //create a new taskmatching
+		return t.createTaskMatching(stub, args)
+	} else if function == "readTaskMatching" { //reads a taskmatching
+		return t.readTaskMatching(stub, args)
+	} else if function == "readJobRuntimes" { //reads the runtimes of a job, also private ones
+		return t.readJobRuntimes(stub, args)
+	} else if function == "getHistory" { //every version of a key
+		return t.getHistory(stub, args)
+	} else if function == "listSolutions" { //page through the saved solutions
+		return t.listSolutions(stub, args)
+	} else if function == "querySolutionsByAlgorithm" { //solutions won by an algorithm
+		return t.querySolutionsByAlgorithm(stub, args)
+	} else if function == "querySolutionsByOwner" { //solutions won by a peer
+		return t.querySolutionsByOwner(stub, args)
+	} else if function == "queryTopJobsByMakespan" { //solutions with the longest makespan first
+		return t.queryTopJobsByMakespan(stub, args)
+	} else if function == "Initialize" { //initialize the network
+		return t.Initialize(stub)
+	} else if function == "calculateTaskMatching" { //calculate a taskmatching
+		res := t.calculateTaskMatching(stub, args)
+		if res.Status != shim.OK {
+			return res
+		}
+
+		jobID := jobArg(args, 1)
+		if t.allPeersDone(stub, jobID) {
+			return t.finishJob(stub, jobID)
+		} else {
+			return shim.Success(nil)
+		}
+	} else if function == "submitSolution" { //hand in a solution computed off the chain
+		res := t.submitSolution(stub, args)
+		if res.Status != shim.OK {
+			return res
+		}
+
+		//keep the payload like revealSolution does
+		jobID := args[1]
+		if t.allPeersDone(stub, jobID) {
+			if finished := t.finishJob(stub, jobID); finished.Status != shim.OK {
+				return finished
+			}
+		}
+		return res
+	} else if function == "commitSolution" { //commit to the hash of a solution
+		return t.commitSolution(stub, args)
+	} else if function == "revealSolution" { //reveal a committed solution
+		res := t.revealSolution(stub, args)
+		if res.Status != shim.OK {
+			return res
+		}
+
+		//keep the payload of the reveal, it tells the solver if its solution was invalid
+		jobID := args[1]
+		if t.allPeersDone(stub, jobID) {
+			if finished := t.finishJob(stub, jobID); finished.Status != shim.OK {
+				return finished
+			}
+		}
+		return res
+	} else if function == "mint" { //create tokens, minter only
+		return t.mint(stub, args)
+	} else if function == "transfer" { //send tokens to another org
+		return t.transfer(stub, args)
+	} else if function == "balanceOf" { //token balance of an org
+		return t.balanceOf(stub, args)
+	} else if function == "setRewardRule" { //how bounties are paid out, minter only
+		return t.setRewardRule(stub, args)
+	} else if function == "getSolverStats" { //how reliable the solvers are
+		return t.getSolverStats(stub, args)
+	} else if function == "improveSolution" { //replace the best solution of a closed job with a better one
+		return t.improveSolution(stub, args)
+	} else if function == "setImprovementRule" { //margin and reward for improvements, minter only
+		return t.setImprovementRule(stub, args)
+	} else if function == "closeJob" { //close a job once its deadline has passed
+		return t.closeJob(stub, args)
+	} else if function == "cancelJob" { //cancel a job that is still open
+		return t.cancelJob(stub, args)
+	} else if function == "addTasks" { //append tasks to a job that is still open
+		return t.addTasks(stub, args)
+	} else if function == "setDynamicMode" { //map the tasks of a job as they arrive
+		return t.setDynamicMode(stub, args)
+	} else if function == "mapTasks" { //map the next batch of a dynamic job
+		return t.mapTasks(stub, args)
+	}
+	fmt.Println("invoke did not find func: " + function) //error
+	return shim.Error("Received unknown function invocation")
+}
+
+func (t *SimpleChaincode) Initialize(stub shim.ChaincodeStubInterface) pb.Response {
+	var err error
+
+	p1 := &Peer{"p1", "waiting", make([]int, 0), -1, "Peer 1", "", "", "", "", solverOrgs["p1"]}
+
+	err = putPeer(stub, "p1", p1) //write the peer
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	p2 := &Peer{"p2", "waiting", make([]int, 0), -1, "Peer 2", "", "", "", "", solverOrgs["p2"]}
+
+	err = putPeer(stub, "p2", p2) //write the peer
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	p3 := &Peer{"p3", "waiting", make([]int, 0), -1, "Peer 3", "", "", "", "", solverOrgs["p3"]}
+
+	err = putPeer(stub, "p3", p3) //write the peer
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	err = initToken(stub)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	count := &Count{"count", 0}
+	countAsBytes, _ := json.Marshal(count)
+
+	countKey, err := counterKey(stub)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	stub.PutState(countKey, countAsBytes)
+
+	return shim.Success(nil)
+}
+
+func (t *SimpleChaincode) calculateTaskMatching(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	//  0       1
+	//peer   [job id]
+	if len(args) != 1 && len(args) != 2 {
+		return shim.Error("Incorrect number of arguments. Expecting peer and optionally the job id")
+	}
+	jobID := jobArg(args, 1)
+
+	//Get the Task Math matrix
+	tmpTM, err := getJob(stub, jobID)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	//a job with a commit phase only takes committed solutions, like submitSolution
+	if tmpTM.CommitDeadline != tmpTM.Created {
+		return shim.Error("Job " + jobID + " has a commit phase, use commitSolution and revealSolution")
+	}
+
+	//Only accept submissions for a live job before its deadline
+	if err := checkSubmissionWindow(stub, jobID, tmpTM); err != nil {
+		return shim.Error(err.Error())
+	}
+
+	//change Peer info
+	tmpPeer, err := getPeer(stub, args[0])
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	org, err := solverOrg(stub, args[0], tmpPeer)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	if tmpPeer.Job != jobID {
+		return shim.Error("Solver " + args[0] + " is not taking part in job " + jobID)
+	}
+	if tmpPeer.Status != "waiting" {
+		return shim.Error("Solver " + args[0] + " has already submitted for job " + jobID)
+	}
+
+	//Convert matrix string to float matrix
+	runtimes, err := jobRuntimes(stub, jobID, tmpTM)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	var matrix [][]int = strToMatrix(runtimes)
+
+	//pass matrix to solution calculator
+	var sol []int
+	var runtime int
+
+	sol, runtime = Assign(matrix, args[0])
+
+	// var runtime float64 = calcRuntime(matrix, sol)
+	// if args[0] == "p3" {
+	// 	runtime = calcRuntime(matrix, sol)
+	// }
+
+	tmpPeer.Status = "done"
+	tmpPeer.Solution = sol
+	tmpPeer.Runtime = runtime
+	tmpPeer.Org = org
+
+	if err := putPeer(stub, args[0], tmpPeer); err != nil {
+		return shim.Error(err.Error())
+	}
+
+	//the first submission moves the job on to SOLVING
+	if tmpTM.State == jobOpen {
+		tmpTM.State = jobSolving
+		if err := putJob(stub, jobID, tmpTM); err != nil {
+			return shim.Error(err.Error())
+		}
+	}
+	if err := setSolverEvent(stub, solutionSubmittedEvent, jobID, tmpTM, args[0]); err != nil {
+		return shim.Error(err.Error())
+	}
+
+	return shim.Success(nil)
+	//
+}
+
+// nextSolutionNumber bumps the solution counter and returns the number of the new solution.
+func nextSolutionNumber(stub shim.ChaincodeStubInterface) (int, error) {
+	countKey, err := counterKey(stub)
+	if err != nil {
+		return 0, err
+	}
+	countAsBytes, err := stub.GetState(countKey)
+	if err != nil {
+		return 0, err
+	}
+	tmpCount := Count{}
+
+	json.Unmarshal(countAsBytes, &tmpCount)
+
+	tmpCount.Counter += 1
+
+	countAsJSON, _ := json.Marshal(tmpCount)
+	if err := stub.PutState(countKey, countAsJSON); err != nil {
+		return 0, err
+	}
+	return tmpCount.Counter, nil
+}
+
+// jobArg returns args[i] as a job id, falling back to the default job when it is missing.
+func jobArg(args []string, i int) string {
+	if len(args) > i && args[i] != "" {
+		return args[i]
+	}
+	return defaultJob
+}
+
+// checkSubmissionWindow returns an error unless solutions for the job can be handed in
+// right now, that is after its commit phase and before its deadline.
+func checkSubmissionWindow(stub shim.ChaincodeStubInterface, jobID string, job *TaskMatching) error {
+	now, err := txTime(stub)
+	if err != nil {
+		return err
+	}
+	if job.State != jobOpen && job.State != jobSolving {
+		return fmt.Errorf("job %s is %s", jobID, job.State)
+	}
+	if now < job.CommitDeadline {
+		return fmt.Errorf("job %s is still in its commit phase until %d", jobID, job.CommitDeadline)
+	}
+	if now > job.Deadline {
+		return fmt.Errorf("the submission deadline for job %s has passed", jobID)
+	}
+	return nil
+}
+
+// txTime returns the transaction timestamp in unix seconds. Every endorser sees the same
+// value, so unlike the local clock it is safe to base deadlines on.
+func txTime(stub shim.ChaincodeStubInterface) (int64, error) {
+	ts, err := stub.GetTxTimestamp()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get transaction timestamp: %s", err)
+	}
+	return ts.Seconds, nil
+}
+
+func getJob(stub shim.ChaincodeStubInterface, jobID string) (*TaskMatching, error) {
+	key, err := jobKey(stub, jobID)
+	if err != nil {
+		return nil, err
+	}
+	jobAsBytes, err := stub.GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get TaskMatching: %s", err)
+	} else if jobAsBytes == nil {
+		return nil, fmt.Errorf("TaskMatching does not exist: %s", jobID)
+	}
+
+	job := &TaskMatching{}
+	if err := json.Unmarshal(jobAsBytes, job); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+func putJob(stub shim.ChaincodeStubInterface, jobID string, job *TaskMatching) error {
+	key, err := jobKey(stub, jobID)
+	if err != nil {
+		return err
+	}
+	jobAsBytes, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+	return stub.PutState(key, jobAsBytes)
+}
+
+func getPeer(stub shim.ChaincodeStubInterface, peerID string) (*Peer, error) {
+	key, err := solverKey(stub, peerID)
+	if err != nil {
+		return nil, err
+	}
+	peerAsBytes, err := stub.GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get solver: %s", err)
+	} else if peerAsBytes == nil {
+		return nil, fmt.Errorf("unknown solver: %s", peerID)
+	}
+
+	peer := &Peer{}
+	if err := json.Unmarshal(peerAsBytes, peer); err != nil {
+		return nil, err
+	}
+	return peer, nil
+}
+
+// solverOrg returns the MSP id of the caller if it is the org the solver belongs to.
+func solverOrg(stub shim.ChaincodeStubInterface, peerID string, peer *Peer) (string, error) {
+	org, err := cid.GetMSPID(stub)
+	if err != nil {
+		return "", fmt.Errorf("failed to get the solver's MSP: %s", err)
+	}
+	if peer.Owner == "" {
+		return "", fmt.Errorf("solver %s belongs to no org, call Initialize first", peerID)
+	} else if org != peer.Owner {
+		return "", fmt.Errorf("only %s can submit for solver %s", peer.Owner, peerID)
+	}
+	return org, nil
+}
+
+func putPeer(stub shim.ChaincodeStubInterface, peerID string, peer *Peer) error {
+	key, err := solverKey(stub, peerID)
+	if err != nil {
+		return err
+	}
+	peerAsBytes, err := json.Marshal(peer)
+	if err != nil {
+		return err
+	}
+	return stub.PutState(key, peerAsBytes)
+}
+
+// resetPeers puts every solver back to waiting for a new job, so that statuses and
+// solutions of an earlier job don't count towards the new one.
+func resetPeers(stub shim.ChaincodeStubInterface, jobID string) error {
+	for i := 0; i < len(peerArray); i++ {
+		tmpPeer, err := getPeer(stub, peerArray[i])
+		if err != nil {
+			return fmt.Errorf("%s, call Initialize first", err)
+		}
+
+		tmpPeer.Status = "waiting"
+		tmpPeer.Solution = make([]int, 0)
+		tmpPeer.Runtime = -1
+		tmpPeer.Job = jobID
+		tmpPeer.Commitment = ""
+		tmpPeer.Org = ""
+		tmpPeer.Algorithm = ""
+
+		if err := putPeer(stub, peerArray[i], tmpPeer); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// activeJob returns the id of a job the solvers are still working on, or "" if there is none.
+func activeJob(stub shim.ChaincodeStubInterface) (string, error) {
+	for i := 0; i < len(peerArray); i++ {
+		tmpPeer, err := getPeer(stub, peerArray[i])
+		if err != nil {
+			return "", err
+		}
+		if tmpPeer.Job == "" {
+			continue
+		}
+
+		job, err := getJob(stub, tmpPeer.Job)
+		if err != nil {
+			continue
+		}
+		if job.State == jobOpen || job.State == jobSolving {
+			return tmpPeer.Job, nil
+		}
+	}
+	return "", nil
+}
+
+func strToMatrix(input string) [][]int {
+	var parsed [][]int
+	json.Unmarshal([]byte(input), &parsed)
+	return parsed
+}
+
+// parseMatrix parses the runtimes of a new job. The solvers need at least one task and
+// one resource, the same number of resources for every task and no negative runtimes.
+func parseMatrix(input string) ([][]int, error) {
+	var parsed [][]int
+	if err := json.Unmarshal([]byte(input), &parsed); err != nil {
+		return nil, fmt.Errorf("the runtimes must be a JSON array of arrays of integers")
+	}
+	if len(parsed) == 0 || len(parsed[0]) == 0 {
+		return nil, fmt.Errorf("the runtimes need at least one task and one resource")
+	}
+	for i, row := range parsed {
+		if len(row) != len(parsed[0]) {
+			return nil, fmt.Errorf("task %d has %d runtimes, expected %d", i, len(row), len(parsed[0]))
+		}
+		for _, runtime := range row {
+			if runtime < 0 || runtime > math.MaxInt32 {
+				return nil, fmt.Errorf("runtime %d of task %d is out of range", runtime, i)
+			}
+		}
+	}
+	return parsed, nil
+}
+
+func Assign(matrix [][]int, peer string) ([]int, int) {
+	var sol []int
+	timeCost := -1
+	if peer == "p1" {
+		sol, timeCost = scheduling.MinMin(matrix)
+	} else if peer == "p2" {
+		sol, timeCost = scheduling.MaxMin(matrix)
+	} else if peer == "p3" {
+		// sol = simulatedAnnealing(matrix)
+		sol = make([]int, len(matrix))
+		timeCost = -1
+	}
+
+	return sol, timeCost
+}
+
+func (t *SimpleChaincode) allPeersDone(stub shim.ChaincodeStubInterface, jobID string) bool {
+	//loop over all of the peers
+	for i := 0; i < len(peerArray); i++ {
+		//check to see if any of the peers haven't finished
+
+		//query chaincode to get the result
+		tmpPeer, err := getPeer(stub, peerArray[i])
+		if err != nil {
+			return false
+		}
+
+		if tmpPeer.Job != jobID || (tmpPeer.Status != "done" && tmpPeer.Status != "invalid") {
+			return false
+		}
+	}
+
+	return true
+}
+
+//Method to set the best solution
+func (t *SimpleChaincode) setBestSol(stub shim.ChaincodeStubInterface, jobID string) pb.Response {
+	solPeer := Peer{}
+	found := false
+	// var min float64 = math.MaxFloat64
+	var min int = math.MaxInt32
+
+	//get the current matrix we were working on from the ledger
+	tmpTM, err := getJob(stub, jobID)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	//find which peer found the best solution and save their information,
+	//only peers that submitted a valid result for this job take part
+	validPeers, err := validSubmissions(stub, jobID, tmpTM)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	for _, tmpPeer := range validPeers {
+		if tmpPeer.Runtime < min {
+			min = tmpPeer.Runtime
+			solPeer = *tmpPeer
+			found = true
+		}
+	}
+
+	if !found {
+		return shim.Error("No valid submissions for job " + jobID)
+	}
+
+	//get the number of the new solution and the key it goes under
+	counter, err := nextSolutionNumber(stub)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	solNum := strconv.Itoa(counter)
+	solKey, err := solutionKey(stub, jobID, counter)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	var algName string
+
+	//find which algorithm was used to calculate the solution.
+	if solPeer.Name == "Peer 1" {
+		algName = "min-min"
+	} else if solPeer.Name == "Peer 2" {
+		algName = "max-min"
+	} else {
+		algName = "Simulated Annealing"
+	}
+	if solPeer.Algorithm != "" {
+		algName = solPeer.Algorithm
+	}
+
+	TMSol := TaskMatchingSol{solNum, solPeer.Runtime, solPeer.Solution, solPeer.Name, algName, tmpTM.Runtimes, solutionObjectType, jobID, tmpTM.RuntimesHash, 0}
+
+	//pay the bounty to the winner, and the other valid solvers if it is split
+	var others []string
+	for _, p := range validPeers {
+		if p.Name != solPeer.Name && p.Org != "" {
+			others = append(others, p.Org)
+		}
+	}
+	if err := payBounty(stub, tmpTM, solPeer.Org, others); err != nil {
+		return shim.Error(err.Error())
+	}
+
+	if err := updateSolverStats(stub, jobID, validPeers, solPeer.Name, solPeer.Runtime); err != nil {
+		return shim.Error(err.Error())
+	}
+
+	//add TM sol
+	TMSolAsJSON, _ := json.Marshal(TMSol)
+	stub.PutState(solKey, TMSolAsJSON)
+
+	//the job is finished
+	tmpTM.State = jobClosed
+	tmpTM.BestSolution = counter
+	tmpTM.BestRuntime = solPeer.Runtime
+	if err := putJob(stub, jobID, tmpTM); err != nil {
+		return shim.Error(err.Error())
+	}
+	if err := setJobEvent(stub, jobFinishedEvent, jobID, tmpTM); err != nil {
+		return shim.Error(err.Error())
+	}
+
+	return shim.Success(nil)
+}
+
+// ============================================================
+// closeJob - finish a job once its deadline has passed. The best submission received
+// so far wins; if nobody submitted the job expires instead.
+// ============================================================
+func (t *SimpleChaincode) closeJob(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	if len(args) != 1 {
+		return shim.Error("Incorrect number of arguments. Expecting the job id")
+	}
+	jobID := args[0]
+
+	job, err := getJob(stub, jobID)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	now, err := txTime(stub)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	if job.State == jobOpen && now > job.Deadline {
+		return t.expireJob(stub, jobID, job)
+	} else if job.State == jobSolving && (now > job.Deadline || t.allPeersDone(stub, jobID)) {
+		return t.finishJob(stub, jobID)
+	} else if job.State == jobOpen || job.State == jobSolving {
+		return shim.Error("Job " + jobID + " is still accepting submissions until " + strconv.FormatInt(job.Deadline, 10))
+	}
+
+	return shim.Error("Job " + jobID + " is already " + job.State)
+}
+
+// validSubmissions returns the solvers that submitted a solution for the job whose
+// makespan matches the runtimes the job was created with. For a private job the runtimes
+// are checked against the hash in public state first.
+func validSubmissions(stub shim.ChaincodeStubInterface, jobID string, job *TaskMatching) ([]*Peer, error) {
+	runtimes, err := jobRuntimes(stub, jobID, job)
+	if err != nil {
+		return nil, err
+	}
+	matrix := strToMatrix(runtimes)
+
+	var valid []*Peer
+	for i := 0; i < len(peerArray); i++ {
+		tmpPeer, err := getPeer(stub, peerArray[i])
+		if err != nil {
+			return nil, err
+		}
+
+		if tmpPeer.Job != jobID || tmpPeer.Status != "done" || tmpPeer.Runtime < 0 {
+			continue
+		}
+		if checkAssignment(matrix, tmpPeer.Solution) != nil || scheduling.Makespan(matrix, tmpPeer.Solution) != tmpPeer.Runtime {
+			fmt.Println("Ignoring the solution of " + tmpPeer.Name + ", its makespan does not match the runtimes")
+			continue
+		}
+		valid = append(valid, tmpPeer)
+	}
+	return valid, nil
+}
+
+// finishJob saves the best solution of a job, or expires it if none of the submissions
+// are valid.
+func (t *SimpleChaincode) finishJob(stub shim.ChaincodeStubInterface, jobID string) pb.Response {
+	job, err := getJob(stub, jobID)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	valid, err := validSubmissions(stub, jobID, job)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	if len(valid) > 0 {
+		return t.setBestSol(stub, jobID)
+	}
+	return t.expireJob(stub, jobID, job)
+}
+
+// expireJob ends a job without a solution and gives the bounty and the improvement pool
+// back.
+func (t *SimpleChaincode) expireJob(stub shim.ChaincodeStubInterface, jobID string, job *TaskMatching) pb.Response {
+	job.State = jobExpired
+	if err := refundBounty(stub, job); err != nil {
+		return shim.Error(err.Error())
+	}
+	if err := refundImprovementPool(stub, job); err != nil {
+		return shim.Error(err.Error())
+	}
+	if err := putJob(stub, jobID, job); err != nil {
+		return shim.Error(err.Error())
+	}
+	if err := updateSolverStats(stub, jobID, nil, "", 0); err != nil {
+		return shim.Error(err.Error())
+	}
+	if err := setJobEvent(stub, jobFinishedEvent, jobID, job); err != nil {
+		return shim.Error(err.Error())
+	}
+	return shim.Success(nil)
+}
+
+// ============================================================
+// cancelJob - stop a job no solver has handed in or committed to a solution for yet
+// ============================================================
+func (t *SimpleChaincode) cancelJob(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	if len(args) != 1 {
+		return shim.Error("Incorrect number of arguments. Expecting the job id")
+	}
+	jobID := args[0]
+
+	job, err := getJob(stub, jobID)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	if job.State != jobOpen && job.State != jobSolving {
+		return shim.Error("Job " + jobID + " is already " + job.State)
+	}
+	//once a solver handed in, its assignment is readable, the creator could take it and
+	//get the bounty back
+	if job.State == jobSolving {
+		return shim.Error("Job " + jobID + " already has submissions, it can only be closed")
+	}
+
+	//only the creator gets to cancel a job, the bounty and the improvement pool go back to them
+	if err := checkCreator(stub, jobID, job, "cancel"); err != nil {
+		return shim.Error(err.Error())
+	}
+
+	job.State = jobCancelled
+	if err := refundBounty(stub, job); err != nil {
+		return shim.Error(err.Error())
+	}
+	if err := refundImprovementPool(stub, job); err != nil {
+		return shim.Error(err.Error())
+	}
+	if err := putJob(stub, jobID, job); err != nil {
+		return shim.Error(err.Error())
+	}
+	if err := setJobEvent(stub, jobFinishedEvent, jobID, job); err != nil {
+		return shim.Error(err.Error())
+	}
+
+	return shim.Success(nil)
+}
+
+// ============================================================
+// createTaskMatching - create a taskmatching
+// ============================================================
+func (t *SimpleChaincode) createTaskMatching(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	var err error
+
+	// 0       1          2             3                4               5
+	//id   runtimes  [timeout]  [commit timeout]  [private collection]  [bounty]
+	if len(args) < 2 || len(args) > 6 {
+		return shim.Error("Incorrect number of arguments. Expecting 2 to 6")
+	}
+
+	fmt.Println("- creating TaskMatching")
+
+	identifier := args[0]
+	runtimes := strings.ToLower(args[1])
+
+	if err := checkJobID(identifier); err != nil {
+		return shim.Error(err.Error())
+	}
+
+	timeout := defaultJobTimeout
+	if len(args) >= 3 && args[2] != "" {
+		timeout, err = strconv.Atoi(args[2])
+	}
+	if err != nil || timeout <= 0 {
+		return shim.Error("3rd argument must be a numeric string")
+	}
+
+	//without a commit timeout there is no commit phase and solutions are taken right away
+	commitTimeout := 0
+	if len(args) >= 4 && args[3] != "" {
+		commitTimeout, err = strconv.Atoi(args[3])
+	}
+	if err != nil || commitTimeout < 0 {
+		return shim.Error("4th argument must be a numeric string")
+	}
+
+	//private runtimes come from the transient map, the runtimes argument has to be empty
+	collection := ""
+	if len(args) >= 5 && args[4] != "" {
+		collection = args[4]
+		if runtimes != "" {
+			return shim.Error("The runtimes of a private job must not be passed as an argument")
+		}
+		privRuntimes, err := privateRuntimes(stub, collection)
+		if err != nil {
+			return shim.Error(err.Error())
+		}
+		runtimes = strings.ToLower(privRuntimes)
+	}
+	if _, err := parseMatrix(runtimes); err != nil {
+		return shim.Error("Invalid runtimes: " + err.Error())
+	}
+
+	// ==== Check if TaskMatching already exists ====
+	key, err := jobKey(stub, identifier)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	TaskMatchingAsBytes, err := stub.GetState(key)
+	if err != nil {
+		return shim.Error("Failed to get TaskMatching: " + err.Error())
+	} else if TaskMatchingAsBytes != nil {
+		fmt.Println("This TaskMatching already exists: " + identifier)
+		return shim.Error("This TaskMatching already exists: " + identifier)
+	}
+
+	// ==== The solvers can only work on one job at a time ====
+	active, err := activeJob(stub)
+	if err != nil {
+		return shim.Error(err.Error())
+	} else if active != "" {
+		return shim.Error("Job " + active + " is still open, close or cancel it first")
+	}
+
+	now, err := txTime(stub)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	creator, err := cid.GetMSPID(stub)
+	if err != nil {
+		return shim.Error("Failed to get the creator's MSP: " + err.Error())
+	}
+
+	// ==== Create TaskMatching object and marshal to JSON ====
+	commitDeadline := now + int64(commitTimeout)
+	TaskMatching := &TaskMatching{identifier, runtimes, jobOpen, now, commitDeadline + int64(timeout), commitDeadline, collection, runtimesHash(runtimes), creator, 0, "", 0, 0, 0, 0}
+
+	// ==== Hold the bounty in escrow until the job is closed ====
+	if len(args) == 6 && args[5] != "" {
+		bounty, err := parseAmount(args[5])
+		if err != nil {
+			return shim.Error("6th argument: " + err.Error())
+		}
+		err = escrowBounty(stub, TaskMatching, bounty)
+		if err != nil {
+			return shim.Error(err.Error())
+		}
+	}
+	err = escrowImprovementPool(stub, TaskMatching)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	if collection != "" {
+		TaskMatching.Runtimes = ""
+		err = stub.PutPrivateData(collection, key, []byte(runtimes))
+		if err != nil {
+			return shim.Error(err.Error())
+		}
+	}
+	TaskMatchingJSONasBytes, err := json.Marshal(TaskMatching)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	// === Save taskmatching to state ===
+	err = stub.PutState(key, TaskMatchingJSONasBytes)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	// === Start every solver fresh on the new job ===
+	err = resetPeers(stub, identifier)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	// === Let the solvers know ===
+	err = setJobEvent(stub, jobCreatedEvent, identifier, TaskMatching)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	// ==== taskmathing saved. Return success ====
+	fmt.Println("- end init TaskMatching")
+	return shim.Success(nil)
+}
+
+// ================================================================================================================
+// readTaskMatching: This method can actually read anything that is saved onto the ledger not just taskmatchings.
+// A single argument is a job id, otherwise the record type comes first, e.g. "solver" "p1" or "solution" "work" "1"
+// ================================================================================================================
+func (t *SimpleChaincode) readTaskMatching(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	var identifier, jsonResp string
+	var err error
+
+	if len(args) < 1 {
+		return shim.Error("Incorrect number of arguments. Expecting name of the TaskMatching to query")
+	}
+
+	identifier = strings.Join(args, " ")
+	if len(args) == 1 {
+		args = []string{jobObjectType, args[0]}
+	}
+	key, err := recordKey(stub, args[0], args[1:])
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	TaskMatchingAsbytes, err := stub.GetState(key) //get the TaskMatching from chaincode state
+	if err != nil {
+		jsonResp = "{\"Error\":\"Failed to get state for " + identifier + "\"}"
+		return shim.Error(jsonResp)
+	} else if TaskMatchingAsbytes == nil {
+		jsonResp = "{\"Error\":\"TaskMatching does not exist: " + identifier + "\"}"
+		return shim.Error(jsonResp)
+	}
+
+	return shim.Success(TaskMatchingAsbytes)
+}
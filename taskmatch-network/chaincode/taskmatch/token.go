@@ -0,0 +1,391 @@
+package taskmatch
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hyperledger/fabric/core/chaincode/lib/cid"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	pb "github.com/hyperledger/fabric/protos/peer"
+)
+
+/**************************************************
+ **           Reward Token and Escrow           **
+**************************************************/
+
+// Balances of the reward token are kept per MSP id. A job creator can put a bounty on a
+// job when calling createTaskMatching, it is taken from their balance and held by the
+// job until setBestSol pays it out, or given back when the job expires or is cancelled.
+// The improvement reward is held by the job the same way, until improveSolution pays it
+// to the first accepted improvement.
+
+// Reward rules, set with setRewardRule and copied into every job when it is created.
+const (
+	rewardWinner = "winner" //the winning solver gets the whole bounty
+	rewardSplit  = "split"  //the winner gets WinnerShare percent, the other valid submissions share the rest
+)
+
+// TokenConfig is stored once, by the first call of Initialize.
+type TokenConfig struct {
+	Minter      string `json:"minter"`      //the only MSP allowed to mint tokens and change the reward rule
+	RewardRule  string `json:"rewardRule"`  //rewardWinner or rewardSplit
+	WinnerShare int    `json:"winnerShare"` //percent of the bounty that goes to the winner with rewardSplit
+
+	ImprovementMargin int   `json:"improvementMargin"` //percent an improvement has to beat the best makespan by
+	ImprovementReward int64 `json:"improvementReward"` //tokens every new job holds for the org of its first accepted improvement
+}
+
+type Balance struct {
+	Owner  string `json:"owner"`
+	Amount int64  `json:"amount"`
+}
+
+func balanceKey(stub shim.ChaincodeStubInterface, mspID string) (string, error) {
+	return stub.CreateCompositeKey(balanceObjectType, []string{mspID})
+}
+
+func tokenConfigKey(stub shim.ChaincodeStubInterface) (string, error) {
+	return stub.CreateCompositeKey(configObjectType, []string{"token"})
+}
+
+func getTokenConfig(stub shim.ChaincodeStubInterface) (*TokenConfig, error) {
+	key, err := tokenConfigKey(stub)
+	if err != nil {
+		return nil, err
+	}
+	configAsBytes, err := stub.GetState(key)
+	if err != nil {
+		return nil, err
+	} else if configAsBytes == nil {
+		return nil, fmt.Errorf("the token has not been set up, call Initialize first")
+	}
+
+	config := &TokenConfig{}
+	if err := json.Unmarshal(configAsBytes, config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+func putTokenConfig(stub shim.ChaincodeStubInterface, config *TokenConfig) error {
+	key, err := tokenConfigKey(stub)
+	if err != nil {
+		return err
+	}
+	configAsBytes, err := json.Marshal(config)
+	if err != nil {
+		return err
+	}
+	return stub.PutState(key, configAsBytes)
+}
+
+// initToken makes the caller of the first Initialize the minter. Later calls keep the
+// existing config so that Initialize can't be used to take over the token.
+func initToken(stub shim.ChaincodeStubInterface) error {
+	if _, err := getTokenConfig(stub); err == nil {
+		return nil
+	}
+
+	mspID, err := cid.GetMSPID(stub)
+	if err != nil {
+		return fmt.Errorf("failed to get the caller's MSP: %s", err)
+	}
+	return putTokenConfig(stub, &TokenConfig{mspID, rewardWinner, 100, defaultImprovementMargin, 0})
+}
+
+func getBalance(stub shim.ChaincodeStubInterface, mspID string) (int64, error) {
+	key, err := balanceKey(stub, mspID)
+	if err != nil {
+		return 0, err
+	}
+	balanceAsBytes, err := stub.GetState(key)
+	if err != nil {
+		return 0, err
+	} else if balanceAsBytes == nil {
+		return 0, nil
+	}
+
+	balance := Balance{}
+	if err := json.Unmarshal(balanceAsBytes, &balance); err != nil {
+		return 0, err
+	}
+	return balance.Amount, nil
+}
+
+// addBalance adds amount, which may be negative, to the balance of an MSP.
+func addBalance(stub shim.ChaincodeStubInterface, mspID string, amount int64) error {
+	balance, err := getBalance(stub, mspID)
+	if err != nil {
+		return err
+	}
+	if balance+amount < 0 {
+		return fmt.Errorf("insufficient balance: %s has %d, needs %d", mspID, balance, -amount)
+	}
+
+	key, err := balanceKey(stub, mspID)
+	if err != nil {
+		return err
+	}
+	balanceAsBytes, err := json.Marshal(Balance{mspID, balance + amount})
+	if err != nil {
+		return err
+	}
+	return stub.PutState(key, balanceAsBytes)
+}
+
+// parseAmount parses a positive token amount.
+func parseAmount(amount string) (int64, error) {
+	value, err := strconv.ParseInt(amount, 10, 64)
+	if err != nil || value <= 0 {
+		return 0, fmt.Errorf("the amount must be a positive numeric string")
+	}
+	return value, nil
+}
+
+// escrowBounty takes the bounty of a new job from the creator's balance and copies the
+// current reward rule into the job.
+func escrowBounty(stub shim.ChaincodeStubInterface, job *TaskMatching, bounty int64) error {
+	config, err := getTokenConfig(stub)
+	if err != nil {
+		return err
+	}
+	if err := addBalance(stub, job.Creator, -bounty); err != nil {
+		return err
+	}
+
+	job.Bounty = bounty
+	job.RewardRule = config.RewardRule
+	job.WinnerShare = config.WinnerShare
+	return nil
+}
+
+// refundBounty gives the bounty of an expired or cancelled job back to its creator.
+func refundBounty(stub shim.ChaincodeStubInterface, job *TaskMatching) error {
+	if job.Bounty == 0 {
+		return nil
+	}
+	if err := addBalance(stub, job.Creator, job.Bounty); err != nil {
+		return err
+	}
+	job.Bounty = 0
+	return nil
+}
+
+// payBounty pays the bounty of a job out by its reward rule. others are the orgs of the
+// other solvers that handed in a valid solution.
+func payBounty(stub shim.ChaincodeStubInterface, job *TaskMatching, winner string, others []string) error {
+	if job.Bounty == 0 {
+		return nil
+	}
+	if winner == "" {
+		return refundBounty(stub, job)
+	}
+
+	winnerAmount := job.Bounty
+	if job.RewardRule == rewardSplit && len(others) > 0 {
+		winnerAmount = job.Bounty * int64(job.WinnerShare) / 100
+		share := (job.Bounty - winnerAmount) / int64(len(others))
+		for _, org := range others {
+			if err := addBalance(stub, org, share); err != nil {
+				return err
+			}
+		}
+		//whatever can't be split evenly goes to the winner
+		winnerAmount = job.Bounty - share*int64(len(others))
+	}
+
+	if err := addBalance(stub, winner, winnerAmount); err != nil {
+		return err
+	}
+	job.Bounty = 0
+	return nil
+}
+
+// escrowImprovementPool takes the current improvement reward from the creator's balance
+// of a new job, it fails like escrowBounty if the creator can't pay it.
+func escrowImprovementPool(stub shim.ChaincodeStubInterface, job *TaskMatching) error {
+	config, err := getTokenConfig(stub)
+	if err != nil {
+		return err
+	}
+	if config.ImprovementReward == 0 {
+		return nil
+	}
+	if err := addBalance(stub, job.Creator, -config.ImprovementReward); err != nil {
+		return fmt.Errorf("the improvement reward can't be held: %s", err)
+	}
+	job.ImprovementPool = config.ImprovementReward
+	return nil
+}
+
+// refundImprovementPool gives the improvement pool of an expired or cancelled job back to
+// its creator.
+func refundImprovementPool(stub shim.ChaincodeStubInterface, job *TaskMatching) error {
+	if job.ImprovementPool == 0 {
+		return nil
+	}
+	if err := addBalance(stub, job.Creator, job.ImprovementPool); err != nil {
+		return err
+	}
+	job.ImprovementPool = 0
+	return nil
+}
+
+// payImprovement pays the improvement pool of a job to the org of an accepted improvement.
+// Only the first improvement finds tokens in it.
+func payImprovement(stub shim.ChaincodeStubInterface, job *TaskMatching, improver string) error {
+	if job.ImprovementPool == 0 {
+		return nil
+	}
+	if err := addBalance(stub, improver, job.ImprovementPool); err != nil {
+		return err
+	}
+	job.ImprovementPool = 0
+	return nil
+}
+
+// ============================================================
+// mint - create new tokens for an MSP, only the minter may call it
+// ============================================================
+func (t *SimpleChaincode) mint(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	//  0        1
+	//msp id   amount
+	if len(args) != 2 {
+		return shim.Error("Incorrect number of arguments. Expecting MSP id and amount")
+	}
+
+	if err := checkMinter(stub); err != nil {
+		return shim.Error(err.Error())
+	}
+	amount, err := parseAmount(args[1])
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	if err := addBalance(stub, args[0], amount); err != nil {
+		return shim.Error(err.Error())
+	}
+	return shim.Success(nil)
+}
+
+// ============================================================
+// transfer - move tokens from the caller's MSP to another MSP
+// ============================================================
+func (t *SimpleChaincode) transfer(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	//  0        1
+	//msp id   amount
+	if len(args) != 2 {
+		return shim.Error("Incorrect number of arguments. Expecting MSP id and amount")
+	}
+
+	from, err := cid.GetMSPID(stub)
+	if err != nil {
+		return shim.Error("Failed to get the caller's MSP: " + err.Error())
+	}
+	amount, err := parseAmount(args[1])
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	if err := addBalance(stub, from, -amount); err != nil {
+		return shim.Error(err.Error())
+	}
+	if err := addBalance(stub, args[0], amount); err != nil {
+		return shim.Error(err.Error())
+	}
+	return shim.Success(nil)
+}
+
+// ============================================================
+// balanceOf - the balance of an MSP, the caller's when none is given
+// ============================================================
+func (t *SimpleChaincode) balanceOf(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	if len(args) > 1 {
+		return shim.Error("Incorrect number of arguments. Expecting an optional MSP id")
+	}
+
+	var mspID string
+	var err error
+	if len(args) == 1 {
+		mspID = args[0]
+	} else {
+		mspID, err = cid.GetMSPID(stub)
+		if err != nil {
+			return shim.Error("Failed to get the caller's MSP: " + err.Error())
+		}
+	}
+
+	amount, err := getBalance(stub, mspID)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	balanceAsBytes, err := json.Marshal(Balance{mspID, amount})
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	return shim.Success(balanceAsBytes)
+}
+
+// ============================================================
+// setRewardRule - how bounties of new jobs are paid out: "winner", or "split" followed
+// by the percent the winner gets. Only the minter may call it.
+// ============================================================
+func (t *SimpleChaincode) setRewardRule(stub shim.ChaincodeStubInterface, args []string) pb.Response {
+	//  0          1
+	//rule   [winner share]
+	if len(args) < 1 || len(args) > 2 {
+		return shim.Error("Incorrect number of arguments. Expecting rule and optionally the winner's share")
+	}
+
+	if err := checkMinter(stub); err != nil {
+		return shim.Error(err.Error())
+	}
+	config, err := getTokenConfig(stub)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	switch strings.ToLower(args[0]) {
+	case rewardWinner:
+		if len(args) != 1 {
+			return shim.Error("The winner rule doesn't take a share")
+		}
+		config.RewardRule = rewardWinner
+		config.WinnerShare = 100
+	case rewardSplit:
+		if len(args) != 2 {
+			return shim.Error("The split rule needs the winner's share in percent")
+		}
+		share, err := strconv.Atoi(args[1])
+		if err != nil || share < 0 || share > 100 {
+			return shim.Error("The winner's share must be a percentage between 0 and 100")
+		}
+		config.RewardRule = rewardSplit
+		config.WinnerShare = share
+	default:
+		return shim.Error("Unknown reward rule " + args[0])
+	}
+
+	if err := putTokenConfig(stub, config); err != nil {
+		return shim.Error(err.Error())
+	}
+	return shim.Success(nil)
+}
+
+// checkMinter returns an error unless the caller belongs to the minter MSP.
+func checkMinter(stub shim.ChaincodeStubInterface) error {
+	config, err := getTokenConfig(stub)
+	if err != nil {
+		return err
+	}
+	mspID, err := cid.GetMSPID(stub)
+	if err != nil {
+		return fmt.Errorf("failed to get the caller's MSP: %s", err)
+	}
+	if mspID != config.Minter {
+		return fmt.Errorf("only %s may do this", config.Minter)
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package taskmatch
+
+import (
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+/*** On a peer GetState returns the state before the transaction, never what the ***/
+/*** transaction itself wrote. The last solver of a job must see its own status  ***/
+/*** to close the job, so Invoke wraps the stub to read its own writes.          ***/
+
+// txStub is a ChaincodeStubInterface whose GetState sees the writes and deletes made
+// earlier in the same transaction
+type txStub struct {
+	shim.ChaincodeStubInterface
+	writes map[string][]byte //nil for deleted keys
+}
+
+func newTxStub(stub shim.ChaincodeStubInterface) *txStub {
+	return &txStub{stub, map[string][]byte{}}
+}
+
+// GetState returns the value written in this transaction, else the committed one
+func (s *txStub) GetState(key string) ([]byte, error) {
+	if value, ok := s.writes[key]; ok {
+		return value, nil
+	}
+	return s.ChaincodeStubInterface.GetState(key)
+}
+
+// PutState writes the value and remembers it for later reads
+func (s *txStub) PutState(key string, value []byte) error {
+	err := s.ChaincodeStubInterface.PutState(key, value)
+	if err == nil {
+		s.writes[key] = value
+	}
+	return err
+}
+
+// DelState deletes the key and remembers it for later reads
+func (s *txStub) DelState(key string) error {
+	err := s.ChaincodeStubInterface.DelState(key)
+	if err == nil {
+		s.writes[key] = nil
+	}
+	return err
+}
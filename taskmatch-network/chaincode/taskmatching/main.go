@@ -0,0 +1,20 @@
+// Command taskmatching is the chaincode installed on the peers, see package
+// github.com/chaincode/taskmatch for what it does.
+package main
+
+import (
+	"fmt"
+
+	"github.com/chaincode/taskmatch"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+// ===================================================================================
+// Main
+// ===================================================================================
+func main() {
+	err := shim.Start(new(taskmatch.SimpleChaincode))
+	if err != nil {
+		fmt.Printf("Error starting Simple chaincode: %s", err)
+	}
+}
@@ -0,0 +1,184 @@
+package tuning
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+)
+
+/*** Iterated racing in the style of irace: configurations run instance after      ***/
+/*** instance and drop out once they are significantly worse than the best, and the ***/
+/*** survivors are the elites the configurations of the next race are drawn around. ***/
+
+const (
+	firstTest = 5    //instances every configuration of a race runs on before any drops out
+	alpha     = 0.05 //significance level of the elimination
+)
+
+// candidate is a configuration in a race with its costs on the instances so far.
+type candidate struct {
+	config Config
+	costs  []float64
+}
+
+func (c *candidate) mean(n int) float64 {
+	total := 0.0
+	for _, cost := range c.costs[:n] {
+		total += cost
+	}
+	return total / float64(n)
+}
+
+// Race runs iterations races of configs configurations over up to instances
+// instances. The first race draws its configurations from the whole space; the later
+// ones keep the elites of the race before, the best quarter of the survivors, and draw
+// the other configurations around them, closer with every iteration. Elites don't run
+// again on instances they ran on already.
+func (t *Tuner) Race(configs, instances, iterations int, rng *rand.Rand) (Result, error) {
+	if err := t.check(configs, instances); err != nil {
+		return Result{}, err
+	}
+	var elites []*candidate
+	for it := 0; it < iterations; it++ {
+		race := append([]*candidate(nil), elites...)
+		spread := 0.2 * (1 - float64(it)/float64(iterations))
+		for len(race) < configs {
+			c := &candidate{}
+			if len(elites) == 0 {
+				c.config = t.Space.Sample(rng)
+			} else {
+				//better elites are drawn around more often
+				c.config = t.Space.Around(elites[rankWeighted(len(elites), rng)].config, spread, rng)
+			}
+			race = append(race, c)
+		}
+		survivors, err := t.race(race, instances)
+		if err != nil {
+			return Result{}, err
+		}
+		keep := configs / 4
+		if keep < 1 {
+			keep = 1
+		}
+		if keep > len(survivors) {
+			keep = len(survivors)
+		}
+		elites = survivors[:keep]
+	}
+	best := elites[0]
+	n := len(best.costs)
+	return Result{best.config, best.mean(n), t.runs}, nil
+}
+
+// race runs the candidates instance after instance and returns the survivors, best
+// first by their mean cost on the instances they all ran on.
+func (t *Tuner) race(alive []*candidate, instances int) ([]*candidate, error) {
+	n := 0
+	for ; n < instances && len(alive) > 1; n++ {
+		for _, c := range alive {
+			if len(c.costs) > n {
+				continue
+			}
+			cost, err := t.cost(c.config, n, t.Budget)
+			if err != nil {
+				return nil, err
+			}
+			c.costs = append(c.costs, cost)
+		}
+		if n+1 >= firstTest {
+			alive = eliminate(alive, n+1)
+		}
+	}
+	if n == 0 { //a single candidate still needs a score
+		for len(alive[0].costs) < instances {
+			cost, err := t.cost(alive[0].config, len(alive[0].costs), t.Budget)
+			if err != nil {
+				return nil, err
+			}
+			alive[0].costs = append(alive[0].costs, cost)
+		}
+		n = instances
+	}
+	for _, c := range alive {
+		t.report(c.config, c.mean(n), n, t.Budget)
+	}
+	sort.SliceStable(alive, func(a, b int) bool { return alive[a].mean(n) < alive[b].mean(n) })
+	return alive, nil
+}
+
+// eliminate drops the candidates whose mean rank on the first n instances is
+// significantly worse than the best one's, by a Friedman test followed by comparisons
+// with the best with a Bonferroni correction.
+func eliminate(alive []*candidate, n int) []*candidate {
+	k := len(alive)
+	if k < 2 {
+		return alive
+	}
+	ranks := make([]float64, k)
+	for j := 0; j < n; j++ {
+		order := make([]int, k)
+		for i := range order {
+			order[i] = i
+		}
+		sort.SliceStable(order, func(a, b int) bool { return alive[order[a]].costs[j] < alive[order[b]].costs[j] })
+		//ties share the mean of their ranks
+		for lo := 0; lo < k; {
+			hi := lo + 1
+			for hi < k && alive[order[hi]].costs[j] == alive[order[lo]].costs[j] {
+				hi++
+			}
+			for _, i := range order[lo:hi] {
+				ranks[i] += float64(lo+hi+1) / 2
+			}
+			lo = hi
+		}
+	}
+
+	//Friedman statistic, chi-squared with k-1 degrees of freedom if all are alike
+	sum := 0.0
+	for _, r := range ranks {
+		sum += r * r
+	}
+	friedman := 12/(float64(n*k*(k+1)))*sum - 3*float64(n*(k+1))
+	if friedman <= chiSquaredQuantile(1-alpha, k-1) {
+		return alive
+	}
+
+	best := ranks[0]
+	for _, r := range ranks {
+		best = math.Min(best, r)
+	}
+	//a mean rank differs from the best one's by a normal with this deviation
+	deviation := math.Sqrt(float64(k*(k+1)) / (6 * float64(n)))
+	critical := normalQuantile(1-alpha/float64(k-1)) * deviation
+	var survivors []*candidate
+	for i, c := range alive {
+		if (ranks[i]-best)/float64(n) <= critical {
+			survivors = append(survivors, c)
+		}
+	}
+	return survivors
+}
+
+// rankWeighted draws an index below n, index i with a weight of n-i.
+func rankWeighted(n int, rng *rand.Rand) int {
+	x := rng.Intn(n * (n + 1) / 2)
+	for i := 0; ; i++ {
+		x -= n - i
+		if x < 0 {
+			return i
+		}
+	}
+}
+
+func normalQuantile(p float64) float64 {
+	return math.Sqrt2 * math.Erfinv(2*p-1)
+}
+
+// chiSquaredQuantile approximates the quantile of the chi-squared distribution with
+// df degrees of freedom by the transformation of Wilson and Hilferty.
+func chiSquaredQuantile(p float64, df int) float64 {
+	d := float64(df)
+	x := 1 - 2/(9*d) + normalQuantile(p)*math.Sqrt(2/(9*d))
+	return d * x * x * x
+}
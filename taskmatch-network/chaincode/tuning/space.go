@@ -0,0 +1,125 @@
+// Package tuning searches the parameters of the searches of package scheduling for a
+// class of instances: random search, successive halving and racing in the style of
+// irace. The best parameters found make a scheduling.Profile, which the tools load and
+// run as a scheduler of its own.
+package tuning
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+
+	"github.com/chaincode/scheduling"
+)
+
+// Param is a parameter that takes values in [Min, Max].
+type Param struct {
+	Name     string
+	Min, Max float64
+	Log      bool //sampled on a log scale, for parameters that span orders of magnitude
+}
+
+// Space are the parameters of a search.
+type Space []Param
+
+// Config gives every parameter of a space a value.
+type Config map[string]float64
+
+// SASpace are the parameters of sa: the starting temperature and the cooling rate.
+func SASpace() Space {
+	return Space{
+		{"temp", 100, 100000, true},
+		{"coolingRate", 0.0005, 0.05, true},
+	}
+}
+
+// PSOSpace are the parameters of pso and pso-parallel: the pull towards the particle's
+// and the swarm's best position, the inertia and its damping.
+func PSOSpace() Space {
+	return Space{
+		{"c1", 0.5, 2.5, false},
+		{"c2", 0.5, 2.5, false},
+		{"w", 0.2, 1.1, false},
+		{"wdamp", 0.95, 1, false},
+	}
+}
+
+// SpaceOf returns the space of a tunable scheduler.
+func SpaceOf(scheduler string) (Space, error) {
+	switch scheduler {
+	case "sa":
+		return SASpace(), nil
+	case "pso", "pso-parallel":
+		return PSOSpace(), nil
+	}
+	return nil, fmt.Errorf("%q can't be tuned, only %s", scheduler, strings.Join(scheduling.Tunable, ", "))
+}
+
+// Defaults is the configuration the scheduler runs with untuned.
+func Defaults(scheduler string) Config {
+	if scheduler == "sa" {
+		return Config{"temp": 10000, "coolingRate": 0.003}
+	}
+	return Config{"c1": 1.796180, "c2": 1.796180, "w": 0.729844, "wdamp": 0.995}
+}
+
+// Sample draws a configuration uniformly, or log-uniformly for Log parameters.
+func (s Space) Sample(rng *rand.Rand) Config {
+	c := make(Config, len(s))
+	for _, p := range s {
+		c[p.Name] = p.from(rng.Float64())
+	}
+	return c
+}
+
+// Around draws a configuration near c, every parameter from a normal distribution
+// around its value in c with a standard deviation of spread times its range, cut to
+// the range.
+func (s Space) Around(c Config, spread float64, rng *rand.Rand) Config {
+	near := make(Config, len(s))
+	for _, p := range s {
+		u := p.to(c[p.Name]) + rng.NormFloat64()*spread
+		near[p.Name] = p.from(math.Min(math.Max(u, 0), 1))
+	}
+	return near
+}
+
+// from maps u in [0, 1] to the range of the parameter.
+func (p Param) from(u float64) float64 {
+	x := p.Min + u*(p.Max-p.Min)
+	if p.Log {
+		x = math.Exp(math.Log(p.Min) + u*(math.Log(p.Max)-math.Log(p.Min)))
+	}
+	//exp and log may round past the bounds
+	return math.Min(math.Max(x, p.Min), p.Max)
+}
+
+// to maps a value of the parameter to [0, 1].
+func (p Param) to(x float64) float64 {
+	if p.Log {
+		return (math.Log(x) - math.Log(p.Min)) / (math.Log(p.Max) - math.Log(p.Min))
+	}
+	return (x - p.Min) / (p.Max - p.Min)
+}
+
+// Format writes the configuration as name=value pairs in the order of the space.
+func (s Space) Format(c Config) string {
+	var pairs []string
+	for _, p := range s {
+		pairs = append(pairs, p.Name+"="+strconv.FormatFloat(c[p.Name], 'g', 4, 64))
+	}
+	return strings.Join(pairs, " ")
+}
+
+// Profile returns the profile of scheduler set up with the configuration c.
+func Profile(name, scheduler string, c Config) scheduling.Profile {
+	p := scheduling.Profile{Name: name, Scheduler: scheduler}
+	if scheduler == "sa" {
+		p.SA = &scheduling.SAOptions{Temperature: c["temp"], CoolingRate: c["coolingRate"]}
+	} else {
+		p.PSO = &scheduling.PSOOptions{C1: c["c1"], C2: c["c2"], Inertia: c["w"], Damping: c["wdamp"]}
+	}
+	return p
+}
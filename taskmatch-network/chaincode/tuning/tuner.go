@@ -0,0 +1,186 @@
+package tuning
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/chaincode/scheduling"
+)
+
+// Tuner tunes the parameters of a scheduler on generated instances of a class. Its
+// runs are deterministic: instance k is generated with the seed Seed+k and every
+// configuration is run on it with that seed as well, so configurations are compared on
+// the same instances with the same random numbers.
+type Tuner struct {
+	Scheduler string //sa, pso or pso-parallel
+	Space     Space  //the parameters to tune, the space of the scheduler if nil
+	Class     scheduling.Class
+	Method    string //how instances are generated, scheduling.RangeBased or scheduling.CVB
+	Tasks     int
+	Resources int
+	Budget    scheduling.Budget //of every run of the scheduler
+	Seed      int64
+	Progress  io.Writer //gets a line for every configuration scored, nil for none
+
+	instances [][][]float64
+	bounds    []float64 //makespan lower bounds of the instances
+	runs      int
+}
+
+// Result is the best configuration a tuning method found.
+type Result struct {
+	Config Config
+	Score  float64 //mean makespan over the lower bound, on the instances it was last run on
+	Runs   int     //runs of the scheduler the tuner made so far
+}
+
+// Score returns the mean makespan over the lower bound of the scheduler set up with c on
+// the first n instances.
+func (t *Tuner) Score(c Config, n int) (float64, error) {
+	return t.score(c, n, t.Budget)
+}
+
+func (t *Tuner) score(c Config, n int, budget scheduling.Budget) (float64, error) {
+	total := 0.0
+	for k := 0; k < n; k++ {
+		cost, err := t.cost(c, k, budget)
+		if err != nil {
+			return 0, err
+		}
+		total += cost
+	}
+	t.report(c, total/float64(n), n, budget)
+	return total / float64(n), nil
+}
+
+// cost runs the scheduler set up with c on instance k and returns the makespan over the
+// lower bound.
+func (t *Tuner) cost(c Config, k int, budget scheduling.Budget) (float64, error) {
+	if err := t.setup(); err != nil {
+		return 0, err
+	}
+	for len(t.instances) <= k {
+		seed := t.Seed + int64(len(t.instances))
+		etc, err := t.Class.Params(t.Method, t.Tasks, t.Resources).Generate(rand.New(rand.NewSource(seed)))
+		if err != nil {
+			return 0, err
+		}
+		t.instances = append(t.instances, etc)
+		t.bounds = append(t.bounds, scheduling.LowerBound(etc))
+	}
+	f, err := Profile("tuning", t.Scheduler, c).Func()
+	if err != nil {
+		return 0, err
+	}
+	t.runs++
+	sol := f(context.Background(), t.instances[k], budget, rand.New(rand.NewSource(t.Seed+int64(k))))
+	return scheduling.ETCMakespan(t.instances[k], sol) / t.bounds[k], nil
+}
+
+func (t *Tuner) report(c Config, score float64, n int, budget scheduling.Budget) {
+	if t.Progress == nil {
+		return
+	}
+	effort := ""
+	if budget.Evaluations > 0 {
+		effort = fmt.Sprintf(", %d evaluations", budget.Evaluations)
+	}
+	if budget.Time > 0 {
+		effort += fmt.Sprintf(", %s", budget.Time)
+	}
+	fmt.Fprintf(t.Progress, "%s: %.4f on %d instances%s\n", t.Space.Format(c), score, n, effort)
+}
+
+// RandomSearch scores configs configurations drawn from the space on the first
+// instances and returns the best one.
+func (t *Tuner) RandomSearch(configs, instances int, rng *rand.Rand) (Result, error) {
+	if err := t.check(configs, instances); err != nil {
+		return Result{}, err
+	}
+	best := Result{Score: math.Inf(1)}
+	for i := 0; i < configs; i++ {
+		c := t.Space.Sample(rng)
+		score, err := t.Score(c, instances)
+		if err != nil {
+			return Result{}, err
+		}
+		if score < best.Score {
+			best.Config, best.Score = c, score
+		}
+	}
+	best.Runs = t.runs
+	return best, nil
+}
+
+// SuccessiveHalving draws configs configurations and scores them on the first
+// instances with a small share of the budget. It keeps the best 1/eta of them, gives
+// them eta times the budget and so on, until one is left; the last round runs with the
+// whole budget. It needs a budget to share.
+func (t *Tuner) SuccessiveHalving(configs, instances, eta int, rng *rand.Rand) (Result, error) {
+	if err := t.check(configs, instances); err != nil {
+		return Result{}, err
+	}
+	if t.Budget.Evaluations <= 0 && t.Budget.Time <= 0 {
+		return Result{}, fmt.Errorf("successive halving needs an evaluation or time budget")
+	}
+	if eta < 2 {
+		return Result{}, fmt.Errorf("successive halving needs an eta of at least 2")
+	}
+	rounds := 1
+	for n := configs; n > 1; n = (n + eta - 1) / eta {
+		rounds++
+	}
+
+	type scored struct {
+		config Config
+		score  float64
+	}
+	var alive []scored
+	for i := 0; i < configs; i++ {
+		alive = append(alive, scored{config: t.Space.Sample(rng)})
+	}
+	for round := 0; round < rounds; round++ {
+		share := math.Pow(float64(eta), float64(round-rounds+1))
+		budget := scheduling.Budget{Time: time.Duration(float64(t.Budget.Time) * share)}
+		if t.Budget.Evaluations > 0 {
+			budget.Evaluations = int(math.Ceil(float64(t.Budget.Evaluations) * share))
+		}
+		for i := range alive {
+			score, err := t.score(alive[i].config, instances, budget)
+			if err != nil {
+				return Result{}, err
+			}
+			alive[i].score = score
+		}
+		sort.SliceStable(alive, func(a, b int) bool { return alive[a].score < alive[b].score })
+		alive = alive[:(len(alive)+eta-1)/eta]
+	}
+	return Result{alive[0].config, alive[0].score, t.runs}, nil
+}
+
+func (t *Tuner) check(configs, instances int) error {
+	if configs < 1 || instances < 1 {
+		return fmt.Errorf("tuning needs at least one configuration and one instance")
+	}
+	return t.setup()
+}
+
+// setup checks the tuner and fills in the space of the scheduler if none is given.
+func (t *Tuner) setup() error {
+	if t.Tasks < 1 || t.Resources < 1 {
+		return fmt.Errorf("tuning needs instances with tasks and resources")
+	}
+	if t.Space == nil {
+		space, err := SpaceOf(t.Scheduler)
+		if err != nil {
+			return err
+		}
+		t.Space = space
+	}
+	return nil
+}
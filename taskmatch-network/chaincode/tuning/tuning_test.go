@@ -0,0 +1,162 @@
+package tuning
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/chaincode/scheduling"
+)
+
+func TestSpace(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for _, space := range []Space{SASpace(), PSOSpace()} {
+		for i := 0; i < 100; i++ {
+			c := space.Sample(rng)
+			near := space.Around(c, 0.5, rng)
+			for _, p := range space {
+				if c[p.Name] < p.Min || c[p.Name] > p.Max || near[p.Name] < p.Min || near[p.Name] > p.Max {
+					t.Fatalf("%s out of [%v, %v]: %v, %v around it", p.Name, p.Min, p.Max, c[p.Name], near[p.Name])
+				}
+				if x := p.from(p.to(c[p.Name])); math.Abs(x-c[p.Name]) > 1e-9*p.Max {
+					t.Fatalf("%s of %v maps back to %v", p.Name, c[p.Name], x)
+				}
+			}
+		}
+	}
+
+	//the temperature spans three orders of magnitude, half the draws are below 3162
+	low := 0
+	for i := 0; i < 1000; i++ {
+		if SASpace().Sample(rng)["temp"] < math.Sqrt(100*100000) {
+			low++
+		}
+	}
+	if low < 400 || low > 600 {
+		t.Errorf("%d of 1000 temperatures below the geometric mean", low)
+	}
+
+	if got := SASpace().Format(Defaults("sa")); got != "temp=1e+04 coolingRate=0.003" {
+		t.Errorf("formatted %q", got)
+	}
+	if _, err := SpaceOf("min-min"); err == nil {
+		t.Error("min-min has a space")
+	}
+}
+
+func TestProfile(t *testing.T) {
+	p := Profile("sa@c_hihi", "sa", Config{"temp": 500, "coolingRate": 0.01})
+	if p.SA == nil || *p.SA != (scheduling.SAOptions{Temperature: 500, CoolingRate: 0.01}) {
+		t.Errorf("sa profile %+v", p.SA)
+	}
+	p = Profile("pso@c_hihi", "pso", Config{"c1": 1, "c2": 2, "w": 0.5, "wdamp": 0.99})
+	if p.PSO == nil || p.PSO.C1 != 1 || p.PSO.C2 != 2 || p.PSO.Inertia != 0.5 || p.PSO.Damping != 0.99 {
+		t.Errorf("pso profile %+v", p.PSO)
+	}
+
+	//the defaults set up the registered schedulers
+	etc := scheduling.GenerateETC(rand.New(rand.NewSource(1)), 30, 4, "hi", "hi")
+	for _, name := range scheduling.Tunable {
+		f, err := Profile("x", name, Defaults(name)).Func()
+		if err != nil {
+			t.Fatal(err)
+		}
+		registered, _ := scheduling.Lookup(name)
+		budget := scheduling.Budget{Evaluations: 300}
+		if !reflect.DeepEqual(f(context.Background(), etc, budget, rand.New(rand.NewSource(2))), registered(context.Background(), etc, budget, rand.New(rand.NewSource(2)))) {
+			t.Errorf("the default profile of %s schedules differently", name)
+		}
+	}
+}
+
+func newTestTuner(scheduler string) *Tuner {
+	c, _ := scheduling.ParseClass("i_hihi")
+	return &Tuner{Scheduler: scheduler, Class: c, Method: scheduling.RangeBased, Tasks: 24, Resources: 4,
+		Budget: scheduling.Budget{Evaluations: 300}, Seed: 1}
+}
+
+func TestTuners(t *testing.T) {
+	methods := map[string]func(t *Tuner, rng *rand.Rand) (Result, error){
+		"random": func(t *Tuner, rng *rand.Rand) (Result, error) { return t.RandomSearch(6, 3, rng) },
+		"halving": func(t *Tuner, rng *rand.Rand) (Result, error) {
+			return t.SuccessiveHalving(9, 3, 3, rng)
+		},
+		"race": func(t *Tuner, rng *rand.Rand) (Result, error) { return t.Race(8, 7, 2, rng) },
+	}
+	for name, method := range methods {
+		tuner := newTestTuner("sa")
+		res, err := method(tuner, rand.New(rand.NewSource(3)))
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if res.Score < 1 || res.Runs == 0 || len(res.Config) != 2 {
+			t.Errorf("%s: %+v", name, res)
+		}
+		again, _ := method(newTestTuner("sa"), rand.New(rand.NewSource(3)))
+		if !reflect.DeepEqual(res, again) {
+			t.Errorf("%s is not deterministic per seed: %+v, then %+v", name, res, again)
+		}
+	}
+
+	//successive halving gives the survivors more and more of the budget
+	tuner := newTestTuner("pso")
+	res, err := tuner.SuccessiveHalving(9, 2, 3, rand.New(rand.NewSource(1)))
+	if err != nil || len(res.Config) != 4 {
+		t.Fatalf("%+v, %v", res, err)
+	}
+	//9 configurations on a ninth, 3 on a third, 1 on all of it, 2 instances each
+	if tuner.runs != 2*(9+3+1) {
+		t.Errorf("%d runs", tuner.runs)
+	}
+	tuner.Budget = scheduling.Budget{}
+	if _, err := tuner.SuccessiveHalving(9, 2, 3, rand.New(rand.NewSource(1))); err == nil {
+		t.Error("successive halving ran without a budget")
+	}
+}
+
+func TestEliminate(t *testing.T) {
+	candidates := func(costs ...[]float64) []*candidate {
+		var cs []*candidate
+		for _, c := range costs {
+			cs = append(cs, &candidate{costs: c})
+		}
+		return cs
+	}
+
+	//the third is the worst on every instance
+	alive := candidates(
+		[]float64{1.1, 1.2, 1.1, 1.3, 1.2, 1.1, 1.2, 1.1},
+		[]float64{1.2, 1.1, 1.2, 1.1, 1.1, 1.2, 1.1, 1.2},
+		[]float64{1.5, 1.6, 1.4, 1.5, 1.6, 1.5, 1.4, 1.5},
+	)
+	if survivors := eliminate(alive, 8); len(survivors) != 2 || survivors[0] != alive[0] || survivors[1] != alive[1] {
+		t.Errorf("%d survivors", len(survivors))
+	}
+	//too few instances to tell
+	if survivors := eliminate(alive, 2); len(survivors) != 3 {
+		t.Errorf("%d survivors after 2 instances", len(survivors))
+	}
+	//ties are no evidence
+	tied := candidates([]float64{1, 1, 1, 1, 1, 1}, []float64{1, 1, 1, 1, 1, 1})
+	if survivors := eliminate(tied, 6); len(survivors) != 2 {
+		t.Errorf("%d of 2 tied survivors", len(survivors))
+	}
+
+	if q := normalQuantile(0.975); math.Abs(q-1.959964) > 1e-5 {
+		t.Errorf("normal quantile %v", q)
+	}
+	if q := chiSquaredQuantile(0.95, 2); math.Abs(q-5.991) > 0.1 {
+		t.Errorf("chi-squared quantile %v", q)
+	}
+
+	rng := rand.New(rand.NewSource(1))
+	counts := make([]int, 3)
+	for i := 0; i < 6000; i++ {
+		counts[rankWeighted(3, rng)]++
+	}
+	if counts[0] < 2700 || counts[0] > 3300 || counts[2] < 800 || counts[2] > 1200 {
+		t.Errorf("rank weighted draws %v, expected about 3000, 2000, 1000", counts)
+	}
+}
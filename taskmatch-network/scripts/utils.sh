@@ -11,7 +11,7 @@ PEER0_ORG1_CA=/opt/gopath/src/github.com/hyperledger/fabric/peer/crypto/peerOrga
 PEER0_ORG2_CA=/opt/gopath/src/github.com/hyperledger/fabric/peer/crypto/peerOrganizations/org2.example.com/peers/peer0.org2.example.com/tls/ca.crt
 PEER0_ORG3_CA=/opt/gopath/src/github.com/hyperledger/fabric/peer/crypto/peerOrganizations/org3.example.com/peers/peer0.org3.example.com/tls/ca.crt
 
-CC_VERSION=4.042
+CC_VERSION=4.083
 
 # verify the result of the end-to-end test
 verifyResult() {
@@ -135,7 +135,7 @@ instantiateChaincode() {
   # the "-o" option
   
   set -x
-  peer chaincode instantiate -o orderer.example.com:7050 --tls $CORE_PEER_TLS_ENABLED --cafile $ORDERER_CA -C $CHANNEL_NAME -n taskmatching -l ${LANGUAGE} -v ${CC_VERSION} -c '{"Args":["init"]}' -P "OR ('Org1MSP.peer','Org2MSP.peer','Org3MSP.peer')"
+  peer chaincode instantiate -o orderer.example.com:7050 --tls $CORE_PEER_TLS_ENABLED --cafile $ORDERER_CA -C $CHANNEL_NAME -n taskmatching -l ${LANGUAGE} -v ${CC_VERSION} -c '{"Args":["init"]}' --collections-config /opt/gopath/src/github.com/chaincode/taskmatching/collections_config.json -P "OR ('Org1MSP.peer','Org2MSP.peer','Org3MSP.peer')"
   res=$?
   set +x
 
@@ -321,7 +321,7 @@ chaincodeInvoke() {
   ## Read the current peer statuses::
   set -x
   echo "Read the current peer statuses"
-  peer chaincode query -o orderer.example.com:7050 --tls $CORE_PEER_TLS_ENABLED --cafile $ORDERER_CA -C $CHANNEL_NAME -n taskmatching -c '{"Args":["readTaskMatching", "p1"]}'
+  peer chaincode query -o orderer.example.com:7050 --tls $CORE_PEER_TLS_ENABLED --cafile $ORDERER_CA -C $CHANNEL_NAME -n taskmatching -c '{"Args":["readTaskMatching", "solver", "p1"]}'
   res=$?
   set +x
 
@@ -334,7 +334,7 @@ chaincodeInvoke() {
 
   set -x
   echo "Read the current peer statuses"
-  peer chaincode query -o orderer.example.com:7050 --tls $CORE_PEER_TLS_ENABLED --cafile $ORDERER_CA -C $CHANNEL_NAME -n taskmatching -c '{"Args":["readTaskMatching", "p2"]}'
+  peer chaincode query -o orderer.example.com:7050 --tls $CORE_PEER_TLS_ENABLED --cafile $ORDERER_CA -C $CHANNEL_NAME -n taskmatching -c '{"Args":["readTaskMatching", "solver", "p2"]}'
   res=$?
   set +x
 
@@ -347,7 +347,7 @@ chaincodeInvoke() {
 
   set -x
   echo "Read the current peer statuses"
-  peer chaincode query -o orderer.example.com:7050 --tls $CORE_PEER_TLS_ENABLED --cafile $ORDERER_CA -C $CHANNEL_NAME -n taskmatching -c '{"Args":["readTaskMatching", "p3"]}'
+  peer chaincode query -o orderer.example.com:7050 --tls $CORE_PEER_TLS_ENABLED --cafile $ORDERER_CA -C $CHANNEL_NAME -n taskmatching -c '{"Args":["readTaskMatching", "solver", "p3"]}'
   res=$?
   set +x
 
@@ -359,7 +359,7 @@ chaincodeInvoke() {
   fi
 
 
-  ##The following setGlobals aren't mandatory however, they represent the different peers running the calculations.
+  ##Every solver pN belongs to OrgN, its calculations have to be sent from a peer of that org.
   setGlobals 0 1
   ## Calculate task matchings::
   set -x
@@ -413,7 +413,7 @@ chaincodeInvoke() {
   ## Read the current peer statuses now that the work has been completed:
   set -x
   echo "Read the current peer statuses"
-  peer chaincode query -o orderer.example.com:7050 --tls $CORE_PEER_TLS_ENABLED --cafile $ORDERER_CA -C $CHANNEL_NAME -n taskmatching -c '{"Args":["readTaskMatching", "p1"]}'
+  peer chaincode query -o orderer.example.com:7050 --tls $CORE_PEER_TLS_ENABLED --cafile $ORDERER_CA -C $CHANNEL_NAME -n taskmatching -c '{"Args":["readTaskMatching", "solver", "p1"]}'
   res=$?
   set +x
 
@@ -426,7 +426,7 @@ chaincodeInvoke() {
 
   set -x
   echo "Read the current peer statuses"
-  peer chaincode query -o orderer.example.com:7050 --tls $CORE_PEER_TLS_ENABLED --cafile $ORDERER_CA -C $CHANNEL_NAME -n taskmatching -c '{"Args":["readTaskMatching", "p2"]}'
+  peer chaincode query -o orderer.example.com:7050 --tls $CORE_PEER_TLS_ENABLED --cafile $ORDERER_CA -C $CHANNEL_NAME -n taskmatching -c '{"Args":["readTaskMatching", "solver", "p2"]}'
   res=$?
   set +x
 
@@ -439,7 +439,7 @@ chaincodeInvoke() {
 
   set -x
   echo "Read the current peer statuses"
-  peer chaincode query -o orderer.example.com:7050 --tls $CORE_PEER_TLS_ENABLED --cafile $ORDERER_CA -C $CHANNEL_NAME -n taskmatching -c '{"Args":["readTaskMatching", "p3"]}'
+  peer chaincode query -o orderer.example.com:7050 --tls $CORE_PEER_TLS_ENABLED --cafile $ORDERER_CA -C $CHANNEL_NAME -n taskmatching -c '{"Args":["readTaskMatching", "solver", "p3"]}'
   res=$?
   set +x
 
@@ -450,29 +450,14 @@ chaincodeInvoke() {
     exit 1
   fi
 
-  #Must call calculateTaskMatching method again in order for the ledger to realize that all tasks are complete (Doesn't matter which peer it's called on p1/p2/p3 all work) :
-  set -x
-  echo "Picking optimal taskmatching and writing the solution to the ledger"
-  peer chaincode invoke -o orderer.example.com:7050 --tls $CORE_PEER_TLS_ENABLED --cafile $ORDERER_CA -C $CHANNEL_NAME -n taskmatching -c '{"Args":["calculateTaskMatching", "p3"]}'
-  res=$?
-  set +x
-
-  if [ $res -ne 0 ]; then
-    echo "!!!!!!!!!!!!!!! "$2" !!!!!!!!!!!!!!!!"
-    echo "========= ERROR !!! FAILED to calculate the optimal taskmatching! ==========="
-    echo
-    exit 1
-  fi
-
-  echo "Sleeping for 3s while ledger updates"
-  sleep 3
+  #The last calculateTaskMatching picked the optimal taskmatching and wrote the solution to the ledger
 
 
   setGlobals 0 1
   #Can now view the solution that has been created for the given taskmatching:
   set -x
   echo "viewing solution"
-  peer chaincode query -o orderer.example.com:7050 --tls $CORE_PEER_TLS_ENABLED --cafile $ORDERER_CA -C $CHANNEL_NAME -n taskmatching -c '{"Args":["readTaskMatching", "1"]}'
+  peer chaincode query -o orderer.example.com:7050 --tls $CORE_PEER_TLS_ENABLED --cafile $ORDERER_CA -C $CHANNEL_NAME -n taskmatching -c '{"Args":["readTaskMatching", "solution", "work", "1"]}'
   res=$?
   set +x
 
@@ -3,5 +3,5 @@ echo "#################################################################"
 echo "##################  Shutting Down The Network  ##################"
 echo "#################################################################"
 echo 
-docker-compose -f docker-compose-cli.yaml down --volumes --remove-orphans
+docker-compose -f docker-compose-cli.yaml -f docker-compose-couch.yaml down --volumes --remove-orphans
 rm -rf channel-artifacts crypto-config
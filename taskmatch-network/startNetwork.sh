@@ -16,6 +16,8 @@ SYS_CHANNEL="taskmatch-sys-channel"
 CHANNEL_NAME="taskmatch-channel"
 # use this as the default docker-compose yaml definition
 COMPOSE_FILE=docker-compose-cli.yaml
+# CouchDB state databases, needed by the rich queries of the chaincode
+COMPOSE_FILE_COUCH=docker-compose-couch.yaml
 
 # use golang as the default language for chaincode
 LANGUAGE=golang
@@ -127,14 +129,14 @@ echo "#################################################################"
 echo "##  Shutting down any docker containers used by this network  ###"
 echo "#################################################################"
 echo 
-docker-compose -f docker-compose-cli.yaml down
+docker-compose -f $COMPOSE_FILE -f $COMPOSE_FILE_COUCH down
 
 echo 
 echo "#################################################################"
 echo "#######           Starting up docker containers        ##########"
 echo "#################################################################"
 echo 
-docker-compose -f docker-compose-cli.yaml up -d 2>&1 #2>&1 suppresses output from containers, comment it out if you're curious to see what happens.
+docker-compose -f $COMPOSE_FILE -f $COMPOSE_FILE_COUCH up -d 2>&1 #2>&1 suppresses output from containers, comment it out if you're curious to see what happens.
 echo "passed here."
 
 ## Begin installing and instantiating chaincode onto the ledger